@@ -6,34 +6,86 @@ package app
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"sync"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/options"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
 
 	domain "github.com/AshBuk/FingerGo/internal/domain"
+	"github.com/AshBuk/FingerGo/internal/drill"
+	"github.com/AshBuk/FingerGo/internal/importer"
 	"github.com/AshBuk/FingerGo/internal/storage"
 )
 
+// Version is the application version recorded in backups.
+// Release builds override it with -ldflags "-X github.com/AshBuk/FingerGo/app.Version=...".
+var Version = "1.2.8"
+
 type App struct {
-	storage      *storage.Manager            // Manages the application's data storage on disk
-	textsRepo    *storage.TextRepository     // Handles operations related to typing texts
-	sessionsRepo *storage.SessionRepository  // Manages the persistence of typing session data
-	settingsRepo *storage.SettingsRepository // Handles user preferences persistence
+	ctx          context.Context                // Wails runtime context, set in Startup
+	mu           sync.RWMutex                   // guards the fields below; RestoreBackup swaps them
+	storage      *storage.Manager               // Manages the application's data storage on disk
+	database     *storage.SQLiteDB              // Open database when the SQLite backend is selected
+	textsRepo    storage.TextStore              // Handles operations related to typing texts
+	sessionsRepo storage.SessionStore           // Manages the persistence of typing session data
+	settingsRepo storage.SettingsStore          // Handles user preferences persistence
+	backend      storage.Backend                // Selected in Startup via storage.SelectBackend unless preset
+	root         storage.DataRoot               // Data directory in use, set in Startup unless preset
+	stopWatch    context.CancelFunc             // Stops the library watcher (JSON backend only)
+	emit         func(name string, data ...any) // Sends Wails events; runtime.EventsEmit unless preset
 }
 
+// EventLibraryChanged is emitted with a storage.LibraryChange when library
+// files were edited outside the app and reloaded.
+const EventLibraryChanged = "library:changed"
+
 func New() *App { return &App{} }
 
+// NewWithRoot creates an App that uses an already resolved data directory
+// (see storage.ResolveRoot). New resolves it at startup instead.
+func NewWithRoot(root storage.DataRoot) *App { return &App{root: root} }
+
 func (a *App) Startup(ctx context.Context) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.ctx = ctx
+	return a.initStorage()
+}
+
+// initStorage opens the data directory and wires the repositories of the
+// selected backend. Caller must hold a.mu.
+func (a *App) initStorage() error {
 	if a.storage == nil {
-		root := storage.DefaultRoot()
-		manager, err := storage.New(root)
+		root := a.root
+		if root.Path == "" {
+			var err error
+			if root, err = storage.ResolveRoot(""); err != nil {
+				return err
+			}
+		}
+		manager, err := storage.New(root.Path)
 		if err != nil {
 			return fmt.Errorf("storage: failed to create manager: %w", err)
 		}
 		a.storage = manager
+		a.root = root
+	}
+	if a.root.Path == "" {
+		a.root.Path = a.storage.Root() // manager preset by the caller
 	}
 	if err := a.storage.Init(); err != nil {
+		if errors.Is(err, storage.ErrLocked) {
+			return fmt.Errorf("storage: another FingerGo instance is using %s: %w", a.storage.Root(), err)
+		}
 		return fmt.Errorf("storage: initialization failed: %w", err)
 	}
+	if err := a.openBackend(); err != nil {
+		return err
+	}
 	// Text repository is critical — app is useless without it
 	if err := a.ensureTextRepository(); err != nil {
 		return fmt.Errorf("storage: text repository init failed: %w", err)
@@ -46,13 +98,229 @@ func (a *App) Startup(ctx context.Context) error {
 	if err := a.ensureSettingsRepository(); err != nil {
 		log.Printf("WARNING: settings repository init failed, using defaults: %v", err)
 	}
+	// Load eagerly so corrupt files are quarantined and recovered before the GUI asks
+	if _, err := a.textsRepo.Library(); err != nil {
+		return fmt.Errorf("storage: text library load failed: %w", err)
+	}
+	if a.sessionsRepo != nil {
+		if _, err := a.sessionsRepo.List(1); err != nil {
+			log.Printf("WARNING: session history load failed: %v", err)
+		}
+	}
+	settings := a.startupSettings()
+	a.applyNormalizer(settings)
+	a.refreshMetrics()
+	a.purgeTrash(settings.TrashRetentionDays)
+	a.maintainSessions(settings.SessionArchiveDays)
+	a.startWatcher()
 	return nil
 }
 
-func (a *App) Shutdown(ctx context.Context) {}
+// startupSettings returns the saved settings, or the defaults when they
+// cannot be loaded.
+func (a *App) startupSettings() domain.Settings {
+	if a.settingsRepo == nil {
+		return domain.DefaultSettings()
+	}
+	settings, err := a.settingsRepo.Load()
+	if err != nil {
+		log.Printf("WARNING: settings load failed: %v", err)
+		return domain.DefaultSettings()
+	}
+	return settings
+}
+
+// maintainSessions compacts the JSON session journal and archives sessions
+// older than archiveDays (0 keeps them in the live journal). Failures are
+// logged: the journal stays readable either way.
+func (a *App) maintainSessions(archiveDays int) {
+	repo, ok := a.sessionsRepo.(*storage.SessionRepository)
+	if !ok {
+		return
+	}
+	if archiveDays > 0 {
+		n, err := repo.Archive(time.Now().AddDate(0, 0, -archiveDays))
+		if err != nil {
+			log.Printf("WARNING: session archiving failed: %v", err)
+		} else if n > 0 {
+			log.Printf("Archived %d sessions older than %d days", n, archiveDays)
+			return // archiving rewrote the journal
+		}
+	}
+	if err := repo.Compact(); err != nil {
+		log.Printf("WARNING: session journal compaction failed: %v", err)
+	}
+}
+
+// refreshMetrics recomputes text difficulty cached for another keyboard
+// layout. Failures are logged: stale metrics are recomputed on request.
+func (a *App) refreshMetrics() {
+	if n, err := a.textsRepo.RefreshMetrics(); err != nil {
+		log.Printf("WARNING: text metrics refresh failed: %v", err)
+	} else if n > 0 {
+		log.Printf("Recomputed difficulty metrics of %d texts", n)
+	}
+}
+
+// applyNormalizer makes the text repository normalize with the keyboard
+// layout and rule overrides of settings.
+func (a *App) applyNormalizer(settings domain.Settings) {
+	a.textsRepo.SetNormalizer(storage.NewNormalizer(settings.KeyboardLayout, settings.NormalizeRules))
+}
+
+// purgeTrash deletes trash entries older than retentionDays (0 keeps them).
+// Failures are logged: an overfull trash does not stop the app.
+func (a *App) purgeTrash(retentionDays int) {
+	n, err := a.storage.PurgeTrash(time.Duration(retentionDays) * 24 * time.Hour)
+	if err != nil {
+		log.Printf("WARNING: trash purge failed: %v", err)
+	}
+	if n > 0 {
+		log.Printf("Purged %d trash entries older than %d days", n, retentionDays)
+	}
+}
+
+// startWatcher reloads the JSON library when its files are edited outside the
+// app and tells the GUI to refresh. Caller must hold a.mu.
+func (a *App) startWatcher() {
+	repo, ok := a.textsRepo.(*storage.TextRepository)
+	if !ok || a.stopWatch != nil {
+		return
+	}
+	if a.emit == nil {
+		ctx := a.ctx
+		a.emit = func(name string, data ...any) { runtime.EventsEmit(ctx, name, data...) }
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	a.stopWatch = cancel
+	emit := a.emit
+	repo.Watch(ctx, storage.DefaultWatchInterval, func(change storage.LibraryChange) {
+		emit(EventLibraryChanged, change)
+	})
+}
+
+// stopWatcher stops the library watcher if running. Caller must hold a.mu.
+func (a *App) stopWatcher() {
+	if a.stopWatch != nil {
+		a.stopWatch()
+		a.stopWatch = nil
+	}
+}
+
+// Shutdown closes the database (SQLite backend) and releases the data directory lock.
+func (a *App) Shutdown(ctx context.Context) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.stopWatcher()
+	if a.database != nil {
+		if err := a.database.Close(); err != nil {
+			log.Printf("WARNING: %v", err)
+		}
+		a.database = nil
+	}
+	if a.storage == nil {
+		return
+	}
+	if err := a.storage.Close(); err != nil {
+		log.Printf("WARNING: %v", err)
+	}
+}
+
+// OnSecondInstanceLaunch brings the running window to front when the user
+// starts FingerGo again; the second process exits without touching the data.
+func (a *App) OnSecondInstanceLaunch(_ options.SecondInstanceData) {
+	a.mu.RLock()
+	ctx := a.ctx
+	a.mu.RUnlock()
+	if ctx == nil {
+		return
+	}
+	runtime.WindowUnminimise(ctx)
+	runtime.Show(ctx)
+}
+
+// StorageWarnings returns recoverable data problems detected at startup
+// (quarantined corrupt files, rebuilt library index) for the GUI to show.
+func (a *App) StorageWarnings() []string {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.storage == nil {
+		return nil
+	}
+	return a.storage.Warnings()
+}
+
+// DataRoot returns the data directory in use and how it was chosen
+// (--data-dir, FINGERGO_DATA_DIR, portable mode or the platform default).
+func (a *App) DataRoot() storage.DataRoot {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.root
+}
+
+// ExportBackup writes a zip of the library, session history and settings to path.
+func (a *App) ExportBackup(path string) (storage.BackupManifest, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.storage == nil {
+		return storage.BackupManifest{}, fmt.Errorf("storage manager not initialized")
+	}
+	return a.storage.ExportBackup(path, storage.BackupOptions{Database: a.database, AppVersion: Version})
+}
+
+// RestoreBackup replaces all data with the contents of a backup made by
+// ExportBackup and reloads the repositories. The previous data directory is
+// kept on disk (RestoreReport.PreviousData).
+func (a *App) RestoreBackup(path string) (storage.RestoreReport, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.storage == nil {
+		return storage.RestoreReport{}, fmt.Errorf("storage manager not initialized")
+	}
+	a.stopWatcher()
+	if a.database != nil {
+		if err := a.database.Close(); err != nil {
+			return storage.RestoreReport{}, err
+		}
+		a.database = nil
+	}
+	report, err := a.storage.RestoreBackup(path)
+	// Reload from disk either way: the database was closed and caches may be stale
+	a.textsRepo, a.sessionsRepo, a.settingsRepo = nil, nil, nil
+	a.backend = "" // the backup may switch backends (fingergo.db present or not)
+	if initErr := a.initStorage(); initErr != nil {
+		return report, errors.Join(err, initErr)
+	}
+	return report, err
+}
+
+// ExportPack writes the given categories (with subcategories and texts) to
+// path as a text pack for sharing; no IDs exports the whole library.
+func (a *App) ExportPack(categoryIDs []string, path string) (storage.PackManifest, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.textsRepo == nil {
+		return storage.PackManifest{}, fmt.Errorf("text repository not initialized")
+	}
+	return storage.ExportPack(a.textsRepo, categoryIDs, path, Version)
+}
+
+// ImportPack adds the categories and texts of a text pack to the library.
+// strategy ("rename", "skip" or "overwrite"; empty means rename) decides
+// what happens to entries whose ID is already taken.
+func (a *App) ImportPack(path string, strategy storage.ConflictStrategy) (storage.PackReport, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.textsRepo == nil {
+		return storage.PackReport{}, fmt.Errorf("text repository not initialized")
+	}
+	return storage.ImportPack(a.textsRepo, path, strategy)
+}
 
 // DefaultText returns the default text entry (metadata + content).
 func (a *App) DefaultText() (domain.Text, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
 	if a.textsRepo == nil {
 		return domain.Text{}, fmt.Errorf("text repository not initialized")
 	}
@@ -61,6 +329,8 @@ func (a *App) DefaultText() (domain.Text, error) {
 
 // Text returns text content by identifier.
 func (a *App) Text(id string) (domain.Text, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
 	if a.textsRepo == nil {
 		return domain.Text{}, fmt.Errorf("text repository not initialized")
 	}
@@ -69,31 +339,104 @@ func (a *App) Text(id string) (domain.Text, error) {
 
 // TextLibrary returns library metadata for UI navigation.
 func (a *App) TextLibrary() (domain.TextLibrary, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
 	if a.textsRepo == nil {
 		return domain.TextLibrary{}, fmt.Errorf("text repository not initialized")
 	}
 	return a.textsRepo.Library()
 }
 
+// SearchTexts finds texts by title and content words, best match first,
+// optionally filtered by language, category subtree, favourites and length.
+func (a *App) SearchTexts(query storage.SearchQuery) (storage.SearchResult, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.textsRepo == nil {
+		return storage.SearchResult{}, fmt.Errorf("text repository not initialized")
+	}
+	return a.textsRepo.Search(query)
+}
+
 // SaveSession persists a completed typing session.
 func (a *App) SaveSession(payload *domain.SessionPayload) error {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
 	if a.sessionsRepo == nil {
 		return fmt.Errorf("session repository not initialized")
 	}
-	_, err := a.sessionsRepo.Record(payload)
-	return err
+	var meta *domain.SessionTextMeta
+	if payload != nil && payload.SessionTextMeta != nil && payload.TextID != "" && a.textsRepo != nil {
+		meta = payload.SessionTextMeta
+		a.completeSessionMeta(meta)
+	}
+	if _, err := a.sessionsRepo.Record(payload); err != nil {
+		return err
+	}
+	if meta != nil && meta.SegmentID != "" {
+		// The session is saved either way; a text edited meanwhile keeps its cursor
+		if _, err := storage.AdvanceProgress(a.textsRepo, meta.TextID, meta.SegmentID); err != nil {
+			log.Printf("WARNING: progress of %q not advanced: %v", meta.TextID, err)
+		}
+	}
+	return nil
+}
+
+// completeSessionMeta fills in the text revision and segment index the GUI
+// did not send, from the text as stored. Caller must hold a.mu.
+func (a *App) completeSessionMeta(meta *domain.SessionTextMeta) {
+	text, err := a.textsRepo.Text(meta.TextID)
+	if err != nil {
+		return
+	}
+	// A GUI that did not send the revision typed most likely had the current one
+	if meta.TextRevision == 0 {
+		meta.TextRevision = text.Revision
+	}
+	if meta.SegmentID == "" {
+		return
+	}
+	for _, s := range storage.SplitSegments(&text) {
+		if s.ID == meta.SegmentID {
+			meta.SegmentIndex = s.Index
+			return
+		}
+	}
 }
 
 // ListSessions returns recent typing sessions (newest first).
 func (a *App) ListSessions(limit int) ([]domain.TypingSession, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
 	if a.sessionsRepo == nil {
 		return nil, fmt.Errorf("session repository not initialized")
 	}
 	return a.sessionsRepo.List(limit)
 }
 
+// ArchiveSessions moves sessions completed more than olderThanDays ago from
+// the live journal into the yearly archive and returns how many it moved.
+// Archived sessions no longer appear in ListSessions. JSON backend only.
+func (a *App) ArchiveSessions(olderThanDays int) (int, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.sessionsRepo == nil {
+		return 0, fmt.Errorf("session repository not initialized")
+	}
+	repo, ok := a.sessionsRepo.(*storage.SessionRepository)
+	if !ok {
+		return 0, fmt.Errorf("session archiving requires the JSON backend")
+	}
+	if olderThanDays < 0 {
+		return 0, fmt.Errorf("olderThanDays must not be negative: %d", olderThanDays)
+	}
+	return repo.Archive(time.Now().AddDate(0, 0, -olderThanDays))
+}
+
 // GetSettings returns current user settings.
 func (a *App) GetSettings() (domain.Settings, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
 	if a.settingsRepo == nil {
 		return domain.DefaultSettings(), fmt.Errorf("settings repository not initialized")
 	}
@@ -102,57 +445,399 @@ func (a *App) GetSettings() (domain.Settings, error) {
 
 // UpdateSetting modifies a single setting by key and persists the change.
 func (a *App) UpdateSetting(key string, value any) error {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
 	if a.settingsRepo == nil {
 		return fmt.Errorf("settings repository not initialized")
 	}
-	return a.settingsRepo.Update(key, value)
+	if err := a.settingsRepo.Update(key, value); err != nil {
+		return err
+	}
+	if key == "keyboardLayout" && a.textsRepo != nil {
+		settings, err := a.settingsRepo.Load()
+		if err != nil {
+			return err
+		}
+		a.applyNormalizer(settings)
+		a.refreshMetrics()
+	}
+	return nil
 }
 
-// SaveText creates a new text entry.
-func (a *App) SaveText(text *domain.Text) error {
+// NormalizeRules returns the normalization rules applied to texts of
+// language: the saved override, or the defaults.
+func (a *App) NormalizeRules(language string) (storage.NormalizeRules, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
 	if a.textsRepo == nil {
-		return fmt.Errorf("text repository not initialized")
+		return storage.NormalizeRules{}, fmt.Errorf("text repository not initialized")
 	}
-	return a.textsRepo.SaveText(text)
+	if !domain.IsValidLanguage(language) {
+		return storage.NormalizeRules{}, fmt.Errorf("%w: %s", storage.ErrInvalidLanguage, language)
+	}
+	return a.textsRepo.Normalizer().Rules(language), nil
 }
 
-// UpdateText modifies an existing text entry.
-func (a *App) UpdateText(text *domain.Text) error {
+// SetNormalizeRules saves rules as the normalization of texts of language
+// from now on; nil restores the defaults. Stored texts are rewritten on
+// their next save.
+func (a *App) SetNormalizeRules(language string, rules *storage.NormalizeRules) error {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.settingsRepo == nil {
+		return fmt.Errorf("settings repository not initialized")
+	}
 	if a.textsRepo == nil {
 		return fmt.Errorf("text repository not initialized")
 	}
-	return a.textsRepo.UpdateText(text)
+	settings, err := a.settingsRepo.Load()
+	if err != nil {
+		return err
+	}
+	overrides, err := storage.WithNormalizeRules(settings.NormalizeRules, language, rules)
+	if err != nil {
+		return err
+	}
+	settings.NormalizeRules = overrides
+	if err := a.settingsRepo.Save(settings); err != nil {
+		return err
+	}
+	a.applyNormalizer(settings)
+	return nil
 }
 
-// DeleteText removes a text entry by ID.
+// SaveText creates a new text entry. The report tells what normalization
+// changed in the content and which characters the keyboard layout cannot type.
+func (a *App) SaveText(text *domain.Text) (storage.NormalizeReport, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.textsRepo == nil {
+		return storage.NormalizeReport{}, fmt.Errorf("text repository not initialized")
+	}
+	return a.textsRepo.SaveTextWithReport(text)
+}
+
+// UpdateText modifies an existing text entry and reports normalization like
+// SaveText.
+func (a *App) UpdateText(text *domain.Text) (storage.NormalizeReport, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.textsRepo == nil {
+		return storage.NormalizeReport{}, fmt.Errorf("text repository not initialized")
+	}
+	return a.textsRepo.UpdateTextWithReport(text)
+}
+
+// DeleteText moves a text to the trash.
 func (a *App) DeleteText(id string) error {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
 	if a.textsRepo == nil {
 		return fmt.Errorf("text repository not initialized")
 	}
 	return a.textsRepo.DeleteText(id)
 }
 
+// ApplyBulk moves, re-languages, (un)favorites or deletes many texts at once.
+// Each ID gets a result; a failed write changes no text.
+func (a *App) ApplyBulk(ids []string, change storage.BulkChange) ([]storage.BulkResult, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.textsRepo == nil {
+		return nil, fmt.Errorf("text repository not initialized")
+	}
+	return a.textsRepo.ApplyBulk(ids, change)
+}
+
+// TextRevisions lists the saved revisions of a text, newest first, without content.
+func (a *App) TextRevisions(id string) ([]storage.TextRevision, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.textsRepo == nil {
+		return nil, fmt.Errorf("text repository not initialized")
+	}
+	return a.textsRepo.Revisions(id)
+}
+
+// DiffTextRevisions returns a line diff from revision from to revision to.
+func (a *App) DiffTextRevisions(id string, from, to int) ([]storage.DiffLine, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.textsRepo == nil {
+		return nil, fmt.Errorf("text repository not initialized")
+	}
+	return storage.DiffRevisions(a.textsRepo, id, from, to)
+}
+
+// RestoreTextRevision makes an earlier revision current again, saved as a
+// new revision, and returns the updated text.
+func (a *App) RestoreTextRevision(id string, revision int) (domain.Text, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.textsRepo == nil {
+		return domain.Text{}, fmt.Errorf("text repository not initialized")
+	}
+	return storage.RestoreRevision(a.textsRepo, id, revision)
+}
+
+// TextSegments lists the segments of a text, without content.
+func (a *App) TextSegments(id string) ([]storage.Segment, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.textsRepo == nil {
+		return nil, fmt.Errorf("text repository not initialized")
+	}
+	return storage.TextSegments(a.textsRepo, id)
+}
+
+// CurrentSegment returns the segment the next session of a text starts with.
+func (a *App) CurrentSegment(id string) (storage.TextPosition, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.textsRepo == nil {
+		return storage.TextPosition{}, fmt.Errorf("text repository not initialized")
+	}
+	return storage.CurrentSegment(a.textsRepo, id)
+}
+
+// SeekSegment moves the progress cursor of a text to segmentID.
+func (a *App) SeekSegment(id, segmentID string) (storage.TextPosition, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.textsRepo == nil {
+		return storage.TextPosition{}, fmt.Errorf("text repository not initialized")
+	}
+	return storage.SeekSegment(a.textsRepo, id, segmentID)
+}
+
+// TextDifficulty returns the difficulty of a text and the time to type it
+// at the average speed of recent sessions.
+func (a *App) TextDifficulty(id string) (storage.TextDifficulty, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.textsRepo == nil {
+		return storage.TextDifficulty{}, fmt.Errorf("text repository not initialized")
+	}
+	var wpm float64
+	var sessions int
+	if a.sessionsRepo != nil {
+		var err error
+		if wpm, sessions, err = storage.RecentWPM(a.sessionsRepo); err != nil {
+			return storage.TextDifficulty{}, err
+		}
+	}
+	return storage.Difficulty(a.textsRepo, id, wpm, sessions)
+}
+
 // SaveCategory creates a new category entry.
 func (a *App) SaveCategory(cat *domain.Category) error {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
 	if a.textsRepo == nil {
 		return fmt.Errorf("text repository not initialized")
 	}
 	return a.textsRepo.SaveCategory(cat)
 }
 
-// DeleteCategory removes a category entry by ID.
+// UpdateCategory renames a category or changes its icon, parent or sort order.
+func (a *App) UpdateCategory(cat *domain.Category) error {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.textsRepo == nil {
+		return fmt.Errorf("text repository not initialized")
+	}
+	return a.textsRepo.UpdateCategory(cat)
+}
+
+// MoveCategory moves a category under parentID (empty for the root) at
+// position among its new siblings; a negative position appends.
+func (a *App) MoveCategory(id, parentID string, position int) error {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.textsRepo == nil {
+		return fmt.Errorf("text repository not initialized")
+	}
+	return a.textsRepo.MoveCategory(id, parentID, position)
+}
+
+// DeleteCategory moves a category, its subcategories and their texts to the trash.
 func (a *App) DeleteCategory(id string) error {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
 	if a.textsRepo == nil {
 		return fmt.Errorf("text repository not initialized")
 	}
 	return a.textsRepo.DeleteCategory(id)
 }
 
+// ListTrash returns the deleted texts and categories, most recent first.
+func (a *App) ListTrash() ([]storage.TrashEntry, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.storage == nil {
+		return nil, fmt.Errorf("storage manager not initialized")
+	}
+	return a.storage.ListTrash()
+}
+
+// RestoreFromTrash puts a trash entry back into the library. Taken IDs and
+// category names are renamed; the report maps original to new text IDs.
+func (a *App) RestoreFromTrash(id string) (storage.PackReport, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.storage == nil || a.textsRepo == nil {
+		return storage.PackReport{}, fmt.Errorf("text repository not initialized")
+	}
+	return a.storage.RestoreFromTrash(a.textsRepo, id)
+}
+
+// EmptyTrash permanently deletes everything in the trash.
+func (a *App) EmptyTrash() error {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.storage == nil {
+		return fmt.Errorf("storage manager not initialized")
+	}
+	return a.storage.EmptyTrash()
+}
+
+// ImportFiles imports local files as texts into categoryID (empty for the root)
+// and reports, per file, whether it was imported, skipped or rejected.
+func (a *App) ImportFiles(paths []string, categoryID string) (importer.Report, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.textsRepo == nil {
+		return importer.Report{}, fmt.Errorf("text repository not initialized")
+	}
+	return importer.ImportFiles(a.textsRepo, paths, categoryID)
+}
+
+// ImportDirectory imports the source files of a local directory tree (such as
+// a git checkout), mirroring its folders as nested categories. Running it
+// again on the same directory updates changed files.
+func (a *App) ImportDirectory(root string, opts importer.TreeOptions) (importer.Report, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.textsRepo == nil {
+		return importer.Report{}, fmt.Errorf("text repository not initialized")
+	}
+	return importer.ImportTree(a.textsRepo, root, opts)
+}
+
+// ImportGoSnippets imports every top-level function, method and type of the
+// given Go files as its own text, titled with the symbol name.
+func (a *App) ImportGoSnippets(paths []string, opts importer.GoSnippetOptions) (importer.Report, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.textsRepo == nil {
+		return importer.Report{}, fmt.Errorf("text repository not initialized")
+	}
+	return importer.ImportGoSnippets(a.textsRepo, paths, opts)
+}
+
+// ImportMarkdown imports the fenced code blocks (and optionally the
+// paragraphs) of Markdown documents, one category per document.
+func (a *App) ImportMarkdown(paths []string, opts importer.MarkdownOptions) (importer.Report, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.textsRepo == nil {
+		return importer.Report{}, fmt.Errorf("text repository not initialized")
+	}
+	return importer.ImportMarkdown(a.textsRepo, paths, opts)
+}
+
+// GenerateDrill generates a practice drill without saving it. An empty
+// layout is taken from the keyboardLayout setting; the returned options
+// (with the seed) replay the drill.
+func (a *App) GenerateDrill(opts drill.Options) (drill.Drill, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return drill.Generate(a.drillOptions(opts))
+}
+
+// SaveDrill generates a drill and saves it as a text into categoryID (empty
+// for the root). Saving the options of a generated drill keeps what was typed.
+func (a *App) SaveDrill(opts drill.Options, categoryID string) (domain.Text, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.textsRepo == nil {
+		return domain.Text{}, fmt.Errorf("text repository not initialized")
+	}
+	d, err := drill.Generate(a.drillOptions(opts))
+	if err != nil {
+		return domain.Text{}, err
+	}
+	text := d.Text
+	text.ID = drill.TextID(d.Options)
+	text.CategoryID = categoryID
+	if err := a.textsRepo.SaveText(&text); err != nil {
+		return domain.Text{}, err
+	}
+	return text, nil
+}
+
+// GenerateWeaknessDrill generates a drill over-representing the letters and
+// bigrams mistyped most in recent sessions, recent mistakes weighing more.
+// The drill is not saved; its options replay it like GenerateDrill's.
+func (a *App) GenerateWeaknessDrill(opts drill.WeaknessOptions) (drill.WeaknessDrill, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.sessionsRepo == nil {
+		return drill.WeaknessDrill{}, fmt.Errorf("session repository not initialized")
+	}
+	limit := opts.Sessions
+	if limit <= 0 {
+		limit = drill.DefaultWeaknessSessions
+	}
+	sessions, err := a.sessionsRepo.List(limit)
+	if err != nil {
+		return drill.WeaknessDrill{}, err
+	}
+	opts.Drill = a.drillOptions(opts.Drill)
+	return drill.GenerateWeakness(sessions, time.Now(), opts)
+}
+
+// DrillLanguages returns the languages drills can be generated in.
+func (a *App) DrillLanguages() []string {
+	return drill.Languages()
+}
+
+// drillOptions fills an empty drill layout from the settings.
+func (a *App) drillOptions(opts drill.Options) drill.Options {
+	if opts.Layout != "" || a.settingsRepo == nil {
+		return opts
+	}
+	if settings, err := a.settingsRepo.Load(); err == nil && domain.IsKnownLayout(settings.KeyboardLayout) {
+		opts.Layout = settings.KeyboardLayout
+	}
+	return opts
+}
+
 // SupportedLanguages returns the list of supported programming languages.
 func (a *App) SupportedLanguages() []domain.LanguageInfo {
 	return domain.SupportedLanguages()
 }
 
+// openBackend resolves the storage backend and opens the SQLite database if selected.
+func (a *App) openBackend() error {
+	if a.backend == "" {
+		backend, err := storage.SelectBackend(a.storage.Root())
+		if err != nil {
+			return err
+		}
+		a.backend = backend
+	}
+	if a.backend != storage.BackendSQLite || a.database != nil {
+		return nil
+	}
+	db, err := storage.OpenSQLite(a.storage)
+	if err != nil {
+		return fmt.Errorf("storage: open sqlite database: %w", err)
+	}
+	a.database = db
+	return nil
+}
+
 // ensureTextRepository initializes text repository if not already initialized.
 func (a *App) ensureTextRepository() error {
 	if a.textsRepo != nil {
@@ -161,7 +846,13 @@ func (a *App) ensureTextRepository() error {
 	if a.storage == nil {
 		return fmt.Errorf("text repository: storage manager not initialized")
 	}
-	repo, err := storage.NewTextRepository(a.storage)
+	var repo storage.TextStore
+	var err error
+	if a.database != nil {
+		repo, err = storage.NewSQLiteTextRepository(a.database)
+	} else {
+		repo, err = storage.NewTextRepository(a.storage)
+	}
 	if err != nil {
 		return fmt.Errorf("text repository: initialization failed: %w", err)
 	}
@@ -177,7 +868,13 @@ func (a *App) ensureSessionRepository() error {
 	if a.storage == nil {
 		return fmt.Errorf("session repository: storage manager not initialized")
 	}
-	repo, err := storage.NewSessionRepository(a.storage)
+	var repo storage.SessionStore
+	var err error
+	if a.database != nil {
+		repo, err = storage.NewSQLiteSessionRepository(a.database)
+	} else {
+		repo, err = storage.NewSessionRepository(a.storage)
+	}
 	if err != nil {
 		return fmt.Errorf("session repository: initialization failed: %w", err)
 	}
@@ -193,7 +890,13 @@ func (a *App) ensureSettingsRepository() error {
 	if a.storage == nil {
 		return fmt.Errorf("settings repository: storage manager not initialized")
 	}
-	repo, err := storage.NewSettingsRepository(a.storage)
+	var repo storage.SettingsStore
+	var err error
+	if a.database != nil {
+		repo, err = storage.NewSQLiteSettingsRepository(a.database)
+	} else {
+		repo, err = storage.NewSettingsRepository(a.storage)
+	}
 	if err != nil {
 		return fmt.Errorf("settings repository: initialization failed: %w", err)
 	}
@@ -6,9 +6,18 @@ package app
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	domain "github.com/AshBuk/FingerGo/internal/domain"
+	"github.com/AshBuk/FingerGo/internal/drill"
 	"github.com/AshBuk/FingerGo/internal/storage"
 )
 
@@ -22,9 +31,11 @@ func startApp(t *testing.T, dir string) *App {
 	}
 	app := New()
 	app.storage = mgr
+	app.emit = func(string, ...any) {} // no Wails runtime in tests
 	if err := app.Startup(context.Background()); err != nil {
 		t.Fatalf("Startup: %v", err)
 	}
+	t.Cleanup(func() { app.Shutdown(context.Background()) })
 	return app
 }
 
@@ -51,6 +62,119 @@ func TestApp_Startup(t *testing.T) {
 	})
 }
 
+func TestApp_StartupRecoversCorruptIndex(t *testing.T) {
+	dir := t.TempDir()
+	startApp(t, dir).Shutdown(context.Background())
+	indexPath := filepath.Join(dir, "texts", "index.json")
+	if err := os.WriteFile(indexPath, []byte("{broken"), 0o600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	_ = os.Remove(indexPath + ".bak")
+
+	app := startApp(t, dir)
+	if len(app.StorageWarnings()) == 0 {
+		t.Error("expected a storage warning after recovering the index")
+	}
+	if _, err := app.DefaultText(); err != nil {
+		t.Errorf("DefaultText after recovery: %v", err)
+	}
+}
+
+func TestApp_SecondInstanceIsRejected(t *testing.T) {
+	dir := t.TempDir()
+	first := startApp(t, dir)
+
+	mgr, _ := storage.New(dir)
+	second := New()
+	second.storage = mgr
+	second.emit = func(string, ...any) {}
+	err := second.Startup(context.Background())
+	if !errors.Is(err, storage.ErrLocked) {
+		t.Fatalf("expected storage.ErrLocked, got %v", err)
+	}
+
+	// Lock is released on shutdown, so a restart succeeds
+	first.Shutdown(context.Background())
+	if err := second.Startup(context.Background()); err != nil {
+		t.Fatalf("Startup after first instance shut down: %v", err)
+	}
+	second.Shutdown(context.Background())
+}
+
+func TestApp_WatcherEmitsLibraryChanged(t *testing.T) {
+	dir := t.TempDir()
+	mgr, _ := storage.New(dir)
+	app := New()
+	app.storage = mgr
+	events := make(chan storage.LibraryChange, 1)
+	app.emit = func(name string, data ...any) {
+		if name == EventLibraryChanged {
+			events <- data[0].(storage.LibraryChange)
+		}
+	}
+	if err := app.Startup(context.Background()); err != nil {
+		t.Fatalf("Startup: %v", err)
+	}
+	defer app.Shutdown(context.Background())
+
+	lib, _ := app.TextLibrary()
+	path := filepath.Join(dir, "texts", "content", lib.DefaultTextID+".txt")
+	if err := os.WriteFile(path, []byte("edited by hand"), 0o600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	select {
+	case change := <-events:
+		if len(change.TextIDs) != 1 || change.TextIDs[0] != lib.DefaultTextID {
+			t.Errorf("change = %+v, want text %q", change, lib.DefaultTextID)
+		}
+	case <-time.After(3 * storage.DefaultWatchInterval):
+		t.Fatal("no library:changed event")
+	}
+	text, err := app.Text(lib.DefaultTextID)
+	if err != nil {
+		t.Fatalf("Text: %v", err)
+	}
+	if text.Content != "edited by hand" {
+		t.Errorf("content = %q, want the external edit", text.Content)
+	}
+}
+
+func TestApp_DataRoot(t *testing.T) {
+	dir := t.TempDir()
+	resolved, err := storage.ResolveRoot(dir) // as main does for --data-dir
+	if err != nil {
+		t.Fatalf("ResolveRoot: %v", err)
+	}
+	app := NewWithRoot(resolved)
+	app.emit = func(string, ...any) {}
+	if err := app.Startup(context.Background()); err != nil {
+		t.Fatalf("Startup: %v", err)
+	}
+	defer app.Shutdown(context.Background())
+
+	root := app.DataRoot()
+	if root.Path != dir || root.Source != storage.RootFromFlag {
+		t.Errorf("DataRoot = %+v, want {%s %s}", root, dir, storage.RootFromFlag)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "texts", "index.json")); err != nil {
+		t.Errorf("library not created in the data dir: %v", err)
+	}
+}
+
+func TestApp_PresetRoot(t *testing.T) {
+	dir := t.TempDir()
+	app := NewWithRoot(storage.DataRoot{Path: dir, Source: storage.RootFromPortable})
+	app.emit = func(string, ...any) {}
+	if err := app.Startup(context.Background()); err != nil {
+		t.Fatalf("Startup: %v", err)
+	}
+	defer app.Shutdown(context.Background())
+
+	if root := app.DataRoot(); root.Path != dir || root.Source != storage.RootFromPortable {
+		t.Errorf("DataRoot = %+v, want {%s %s}", root, dir, storage.RootFromPortable)
+	}
+}
+
 func TestApp_DefaultText(t *testing.T) {
 	app := startApp(t, t.TempDir())
 
@@ -77,7 +201,7 @@ func TestApp_TextLifecycle(t *testing.T) {
 	}
 
 	// Create
-	if err := app.SaveText(text); err != nil {
+	if _, err := app.SaveText(text); err != nil {
 		t.Fatalf("SaveText: %v", err)
 	}
 
@@ -115,7 +239,7 @@ func TestApp_TextLifecycle(t *testing.T) {
 		Content:  "Updated content for the lifecycle test.",
 		Language: "go",
 	}
-	if err := app.UpdateText(updated); err != nil {
+	if _, err := app.UpdateText(updated); err != nil {
 		t.Fatalf("UpdateText: %v", err)
 	}
 	got, _ = app.Text("lifecycle-test")
@@ -146,7 +270,7 @@ func TestApp_CategoryLifecycle(t *testing.T) {
 
 	// Add texts to category
 	for _, id := range []string{"text-a", "text-b"} {
-		err := app.SaveText(&domain.Text{
+		_, err := app.SaveText(&domain.Text{
 			ID: id, Title: id, Content: "content", Language: "text", CategoryID: "cat-1",
 		})
 		if err != nil {
@@ -241,37 +365,558 @@ func TestApp_SettingsLifecycle(t *testing.T) {
 }
 
 func TestApp_Persistence(t *testing.T) {
+	for _, backend := range []storage.Backend{storage.BackendJSON, storage.BackendSQLite} {
+		t.Run(string(backend), func(t *testing.T) {
+			t.Setenv(storage.BackendEnv, string(backend))
+			dir := t.TempDir()
+
+			// First run: create data
+			app1 := startApp(t, dir)
+			_, _ = app1.SaveText(&domain.Text{
+				ID: "persist-test", Title: "Persist", Content: "survives restart", Language: "text",
+			})
+			_ = app1.SaveSession(&domain.SessionPayload{
+				SessionTextMeta: &domain.SessionTextMeta{Text: "test"},
+				WPM:             42.0,
+			})
+			_ = app1.UpdateSetting("theme", "light")
+			app1.Shutdown(context.Background())
+
+			// Second run: new App, same directory
+			app2 := startApp(t, dir)
+
+			text, err := app2.Text("persist-test")
+			if err != nil {
+				t.Fatalf("text not persisted: %v", err)
+			}
+			if text.Content != "survives restart" {
+				t.Errorf("content = %q, want %q", text.Content, "survives restart")
+			}
+
+			sessions, _ := app2.ListSessions(1)
+			if len(sessions) != 1 || sessions[0].WPM != 42.0 {
+				t.Error("session not persisted across restart")
+			}
+
+			settings, _ := app2.GetSettings()
+			if settings.Theme != "light" {
+				t.Errorf("settings not persisted: theme = %q", settings.Theme)
+			}
+
+			_, err = os.Stat(filepath.Join(dir, "fingergo.db"))
+			if hasDB := err == nil; hasDB != (backend == storage.BackendSQLite) {
+				t.Errorf("fingergo.db present = %v with %s backend", hasDB, backend)
+			}
+		})
+	}
+}
+
+func TestApp_BackupRestore(t *testing.T) {
+	for _, backend := range []storage.Backend{storage.BackendJSON, storage.BackendSQLite} {
+		t.Run(string(backend), func(t *testing.T) {
+			t.Setenv(storage.BackendEnv, string(backend))
+			app := startApp(t, t.TempDir())
+			if _, err := app.SaveText(&domain.Text{ID: "backup-me", Title: "B", Content: "v1", Language: "text"}); err != nil {
+				t.Fatalf("SaveText: %v", err)
+			}
+			archive := filepath.Join(t.TempDir(), "backup.zip")
+			manifest, err := app.ExportBackup(archive)
+			if err != nil {
+				t.Fatalf("ExportBackup: %v", err)
+			}
+			if manifest.AppVersion != Version {
+				t.Errorf("manifest app version = %q, want %q", manifest.AppVersion, Version)
+			}
+			if err := app.DeleteText("backup-me"); err != nil {
+				t.Fatalf("DeleteText: %v", err)
+			}
+
+			report, err := app.RestoreBackup(archive)
+			if err != nil {
+				t.Fatalf("RestoreBackup: %v", err)
+			}
+			if report.PreviousData == "" {
+				t.Error("expected the previous data directory in the report")
+			}
+			text, err := app.Text("backup-me")
+			if err != nil || text.Content != "v1" {
+				t.Errorf("Text after restore = %+v, %v; want v1", text, err)
+			}
+			if _, err := app.SaveText(&domain.Text{ID: "after", Title: "A", Content: "x", Language: "text"}); err != nil {
+				t.Errorf("SaveText after restore: %v", err)
+			}
+		})
+	}
+}
+
+func TestApp_PackRoundTrip(t *testing.T) {
+	src := startApp(t, t.TempDir())
+	if err := src.SaveCategory(&domain.Category{ID: "shared", Name: "Shared"}); err != nil {
+		t.Fatalf("SaveCategory: %v", err)
+	}
+	if _, err := src.SaveText(&domain.Text{ID: "tip", Title: "Tip", Content: "go vet ./...", CategoryID: "shared", Language: "bash"}); err != nil {
+		t.Fatalf("SaveText: %v", err)
+	}
+	pack := filepath.Join(t.TempDir(), "shared.fgpack")
+	manifest, err := src.ExportPack([]string{"shared"}, pack)
+	if err != nil {
+		t.Fatalf("ExportPack: %v", err)
+	}
+	if manifest.AppVersion != Version || len(manifest.Texts) != 1 {
+		t.Errorf("manifest = %+v", manifest)
+	}
+
+	dst := startApp(t, t.TempDir())
+	report, err := dst.ImportPack(pack, storage.ConflictSkip)
+	if err != nil {
+		t.Fatalf("ImportPack: %v", err)
+	}
+	if report.Imported != 1 || report.Categories != 1 {
+		t.Errorf("report = %+v", report)
+	}
+	if text, err := dst.Text("tip"); err != nil || text.Content != "go vet ./..." {
+		t.Errorf("Text(tip) = %+v, %v", text, err)
+	}
+}
+
+func TestApp_UpdateAndMoveCategory(t *testing.T) {
+	app := startApp(t, t.TempDir())
+	for _, c := range []domain.Category{{ID: "a", Name: "A"}, {ID: "b", Name: "B"}} {
+		if err := app.SaveCategory(&c); err != nil {
+			t.Fatalf("SaveCategory: %v", err)
+		}
+	}
+	if err := app.UpdateCategory(&domain.Category{ID: "a", Name: "Alpha", Icon: "🔤"}); err != nil {
+		t.Fatalf("UpdateCategory: %v", err)
+	}
+	if err := app.MoveCategory("b", "a", 0); err != nil {
+		t.Fatalf("MoveCategory: %v", err)
+	}
+	if err := app.MoveCategory("a", "b", 0); !errors.Is(err, storage.ErrCategoryCycle) {
+		t.Errorf("MoveCategory into own child: expected ErrCategoryCycle, got %v", err)
+	}
+	lib, _ := app.TextLibrary()
+	for _, c := range lib.Categories {
+		if c.ID == "a" && c.Name != "Alpha" || c.ID == "b" && c.ParentID != "a" {
+			t.Errorf("category = %+v", c)
+		}
+	}
+}
+
+func TestApp_SearchTexts(t *testing.T) {
+	app := startApp(t, t.TempDir())
+	if _, err := app.SaveText(&domain.Text{ID: "tip", Title: "Vet tip", Content: "go vet ./...", Language: "bash"}); err != nil {
+		t.Fatalf("SaveText: %v", err)
+	}
+	res, err := app.SearchTexts(storage.SearchQuery{Query: "vet", Language: "bash"})
+	if err != nil {
+		t.Fatalf("SearchTexts: %v", err)
+	}
+	if res.Total != 1 || res.Hits[0].Text.ID != "tip" || res.Hits[0].Snippet != "go vet ./..." {
+		t.Errorf("SearchTexts = %+v", res)
+	}
+}
+
+func TestApp_Trash(t *testing.T) {
+	app := startApp(t, t.TempDir())
+	if err := app.SaveCategory(&domain.Category{ID: "old", Name: "Old"}); err != nil {
+		t.Fatalf("SaveCategory: %v", err)
+	}
+	if _, err := app.SaveText(&domain.Text{ID: "gone", Title: "Gone", Content: "bye", CategoryID: "old"}); err != nil {
+		t.Fatalf("SaveText: %v", err)
+	}
+	if err := app.DeleteCategory("old"); err != nil {
+		t.Fatalf("DeleteCategory: %v", err)
+	}
+	entries, err := app.ListTrash()
+	if err != nil || len(entries) != 1 || entries[0].Name != "Old" {
+		t.Fatalf("ListTrash = %+v, %v", entries, err)
+	}
+	if _, err := app.RestoreFromTrash(entries[0].ID); err != nil {
+		t.Fatalf("RestoreFromTrash: %v", err)
+	}
+	if text, err := app.Text("gone"); err != nil || text.CategoryID != "old" {
+		t.Errorf("restored Text = %+v, %v", text, err)
+	}
+	if err := app.DeleteText("gone"); err != nil {
+		t.Fatalf("DeleteText: %v", err)
+	}
+	if err := app.EmptyTrash(); err != nil {
+		t.Fatalf("EmptyTrash: %v", err)
+	}
+	if entries, _ := app.ListTrash(); len(entries) != 0 {
+		t.Errorf("ListTrash after EmptyTrash = %+v", entries)
+	}
+}
+
+func TestApp_PurgesTrashOfUpgradedSettings(t *testing.T) {
 	dir := t.TempDir()
+	app := startApp(t, dir)
+	if _, err := app.SaveText(&domain.Text{ID: "gone", Title: "Gone", Content: "bye"}); err != nil {
+		t.Fatalf("SaveText: %v", err)
+	}
+	if err := app.DeleteText("gone"); err != nil {
+		t.Fatalf("DeleteText: %v", err)
+	}
+	entries, err := app.ListTrash()
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("ListTrash = %+v, %v", entries, err)
+	}
+	app.Shutdown(context.Background())
 
-	// First run: create data
-	app1 := startApp(t, dir)
-	_ = app1.SaveText(&domain.Text{
-		ID: "persist-test", Title: "Persist", Content: "survives restart", Language: "text",
-	})
-	_ = app1.SaveSession(&domain.SessionPayload{
-		SessionTextMeta: &domain.SessionTextMeta{Text: "test"},
-		WPM:             42.0,
-	})
-	_ = app1.UpdateSetting("theme", "light")
+	// Age the entry past the default retention and restore settings saved
+	// before trashRetentionDays existed
+	entry := entries[0]
+	entry.DeletedAt = time.Now().AddDate(0, 0, -40)
+	data, _ := json.Marshal(entry)
+	if err := os.WriteFile(filepath.Join(dir, "trash", entry.ID, "entry.json"), data, 0o600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	settings := `{"schemaVersion": 1, "data": {"theme": "dark", "textZoom": 1}}`
+	if err := os.WriteFile(filepath.Join(dir, "settings.json"), []byte(settings), 0o600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
 
-	// Second run: new App, same directory
-	app2 := startApp(t, dir)
+	app = startApp(t, dir)
+	if entries, _ := app.ListTrash(); len(entries) != 0 {
+		t.Errorf("ListTrash after restart = %+v, want the old entry purged", entries)
+	}
+}
 
-	text, err := app2.Text("persist-test")
+func TestApp_ApplyBulk(t *testing.T) {
+	app := startApp(t, t.TempDir())
+	for _, id := range []string{"one", "two"} {
+		if _, err := app.SaveText(&domain.Text{ID: id, Title: id, Content: id}); err != nil {
+			t.Fatalf("SaveText: %v", err)
+		}
+	}
+	favorite := true
+	results, err := app.ApplyBulk([]string{"one", "two", "nope"}, storage.BulkChange{IsFavorite: &favorite})
+	if err != nil || len(results) != 3 || results[2].Error == "" {
+		t.Fatalf("ApplyBulk = %+v, %v", results, err)
+	}
+	lib, _ := app.TextLibrary()
+	for _, text := range lib.Texts {
+		if text.ID != "quick-sort" && !text.IsFavorite {
+			t.Errorf("text %s not marked favorite", text.ID)
+		}
+	}
+}
+
+func TestApp_TextRevisions(t *testing.T) {
+	app := startApp(t, t.TempDir())
+	text := &domain.Text{ID: "rev", Title: "Rev", Content: "one\ntwo"}
+	if _, err := app.SaveText(text); err != nil {
+		t.Fatalf("SaveText: %v", err)
+	}
+	text.Content = "one\n2"
+	if _, err := app.UpdateText(text); err != nil {
+		t.Fatalf("UpdateText: %v", err)
+	}
+	revs, err := app.TextRevisions("rev")
+	if err != nil || len(revs) != 2 || revs[0].Revision != 2 {
+		t.Fatalf("TextRevisions = %+v, %v", revs, err)
+	}
+	diff, err := app.DiffTextRevisions("rev", 1, 2)
+	if err != nil || len(diff) != 3 || diff[1].Op != storage.DiffDelete || diff[2].Text != "2" {
+		t.Errorf("DiffTextRevisions = %+v, %v", diff, err)
+	}
+
+	// A session without a revision is attributed to the current one
+	payload := &domain.SessionPayload{SessionTextMeta: &domain.SessionTextMeta{Text: "one\n2", TextID: "rev"}}
+	if err := app.SaveSession(payload); err != nil {
+		t.Fatalf("SaveSession: %v", err)
+	}
+	if sessions, _ := app.ListSessions(1); len(sessions) != 1 || sessions[0].TextRevision != 2 {
+		t.Errorf("ListSessions = %+v, want textRevision 2", sessions)
+	}
+
+	restored, err := app.RestoreTextRevision("rev", 1)
+	if err != nil || restored.Revision != 3 || restored.Content != "one\ntwo" {
+		t.Errorf("RestoreTextRevision = %+v, %v", restored, err)
+	}
+}
+
+func TestApp_SaveTextNormalization(t *testing.T) {
+	app := startApp(t, t.TempDir())
+	text := &domain.Text{ID: "pasted", Title: "Pasted", Content: "Привет, world!\r\n"}
+	report, err := app.SaveText(text)
 	if err != nil {
-		t.Fatalf("text not persisted: %v", err)
+		t.Fatalf("SaveText: %v", err)
+	}
+	if len(report.Changes) != 1 || report.Changes[0].Rule != storage.RuleLineEndings {
+		t.Errorf("changes = %+v, want line endings", report.Changes)
 	}
-	if text.Content != "survives restart" {
-		t.Errorf("content = %q, want %q", text.Content, "survives restart")
+	if report.Layout != "en-qwerty" || len(report.Untypeable) != 6 {
+		t.Errorf("report = %+v, want the 6 Cyrillic letters untypeable on en-qwerty", report)
 	}
 
-	sessions, _ := app2.ListSessions(1)
-	if len(sessions) != 1 || sessions[0].WPM != 42.0 {
-		t.Error("session not persisted across restart")
+	// Switching the layout changes what is untypeable
+	if err := app.UpdateSetting("keyboardLayout", "ru-jcuken"); err != nil {
+		t.Fatalf("UpdateSetting: %v", err)
+	}
+	report, err = app.UpdateText(text)
+	if err != nil {
+		t.Fatalf("UpdateText: %v", err)
 	}
+	if len(report.Changes) != 0 || len(report.Untypeable) != 5 || report.Untypeable[0].Char != "w" {
+		t.Errorf("report = %+v, want the 5 Latin letters untypeable on ru-jcuken", report)
+	}
+}
 
-	settings, _ := app2.GetSettings()
-	if settings.Theme != "light" {
-		t.Errorf("settings not persisted: theme = %q", settings.Theme)
+func TestApp_NormalizeRules(t *testing.T) {
+	dir := t.TempDir()
+	app := startApp(t, dir)
+	if rules, err := app.NormalizeRules("go"); err != nil || rules != storage.DefaultNormalizeRules("go") {
+		t.Fatalf("NormalizeRules(go) = %+v, %v; want defaults", rules, err)
+	}
+	if err := app.SetNormalizeRules("go", &storage.NormalizeRules{TabWidth: 4}); err != nil {
+		t.Fatalf("SetNormalizeRules: %v", err)
+	}
+	text := &domain.Text{ID: "tabs", Title: "Tabs", Content: "func f() {\n\treturn  \n}", Language: "go"}
+	report, err := app.SaveText(text)
+	if err != nil {
+		t.Fatalf("SaveText: %v", err)
+	}
+	if text.Content != "func f() {\n    return  \n}" || len(report.Changes) != 1 || report.Changes[0].Rule != storage.RuleTabs {
+		t.Errorf("content = %q, changes = %+v; want tabs expanded, trailing space kept", text.Content, report.Changes)
+	}
+	// Keyboard layout changes keep the overrides
+	if err := app.UpdateSetting("keyboardLayout", "de-qwertz"); err != nil {
+		t.Fatalf("UpdateSetting: %v", err)
+	}
+	if rules, _ := app.NormalizeRules("go"); rules.TabWidth != 4 {
+		t.Errorf("rules after layout change = %+v, want the override", rules)
+	}
+	app.Shutdown(context.Background())
+
+	// The overrides are saved with the settings
+	app = startApp(t, dir)
+	if rules, _ := app.NormalizeRules("go"); rules != (storage.NormalizeRules{TabWidth: 4}) {
+		t.Errorf("rules after restart = %+v, want the override", rules)
+	}
+	if err := app.SetNormalizeRules("go", nil); err != nil {
+		t.Fatalf("SetNormalizeRules(nil): %v", err)
+	}
+	if rules, _ := app.NormalizeRules("go"); rules != storage.DefaultNormalizeRules("go") {
+		t.Errorf("rules after reset = %+v, want defaults", rules)
+	}
+	if err := app.SetNormalizeRules("cobol", &storage.NormalizeRules{}); !errors.Is(err, storage.ErrInvalidLanguage) {
+		t.Errorf("SetNormalizeRules(cobol) = %v, want ErrInvalidLanguage", err)
+	}
+	if err := app.SetNormalizeRules("go", &storage.NormalizeRules{TabWidth: -1}); err == nil {
+		t.Error("SetNormalizeRules with a negative tab width succeeded")
+	}
+}
+
+func TestApp_TextSegments(t *testing.T) {
+	app := startApp(t, t.TempDir())
+	text := &domain.Text{ID: "book", Title: "Book", Content: "One.\n\nTwo.\n\nThree.", SegmentMode: domain.SegmentParagraphs}
+	if _, err := app.SaveText(text); err != nil {
+		t.Fatalf("SaveText: %v", err)
+	}
+	segments, err := app.TextSegments("book")
+	if err != nil || len(segments) != 3 {
+		t.Fatalf("TextSegments = %+v, %v", segments, err)
+	}
+	pos, err := app.SeekSegment("book", segments[1].ID)
+	if err != nil || pos.Segment.Content != "Two." {
+		t.Fatalf("SeekSegment = %+v, %v", pos, err)
+	}
+
+	// A session of a segment records its position and advances the cursor
+	payload := &domain.SessionPayload{SessionTextMeta: &domain.SessionTextMeta{Text: "Two.", TextID: "book", SegmentID: segments[1].ID}}
+	if err := app.SaveSession(payload); err != nil {
+		t.Fatalf("SaveSession: %v", err)
+	}
+	if sessions, _ := app.ListSessions(1); len(sessions) != 1 || sessions[0].SegmentID != segments[1].ID || sessions[0].SegmentIndex != 1 {
+		t.Errorf("ListSessions = %+v, want segment 1", sessions)
+	}
+	if pos, err := app.CurrentSegment("book"); err != nil || pos.Segment.Content != "Three." {
+		t.Errorf("CurrentSegment = %+v, %v; want the next segment", pos, err)
+	}
+}
+
+func TestApp_Drills(t *testing.T) {
+	app := startApp(t, t.TempDir())
+	if err := app.UpdateSetting("keyboardLayout", "en-dvorak"); err != nil {
+		t.Fatalf("UpdateSetting: %v", err)
+	}
+	d, err := app.GenerateDrill(drill.Options{CharSet: drill.CharSetHomeRow, Words: 20})
+	if err != nil {
+		t.Fatalf("GenerateDrill: %v", err)
+	}
+	if d.Options.Layout != "en-dvorak" || d.Options.Seed == 0 {
+		t.Errorf("options = %+v, want the layout setting and a seed", d.Options)
+	}
+
+	// Saving the returned options keeps the drill that was typed
+	saved, err := app.SaveDrill(d.Options, "")
+	if err != nil {
+		t.Fatalf("SaveDrill: %v", err)
+	}
+	if saved.Content != d.Text.Content || saved.ID == "" {
+		t.Errorf("saved = %+v, want the generated content", saved)
+	}
+	if stored, err := app.Text(saved.ID); err != nil || stored.Content != d.Text.Content {
+		t.Errorf("Text(%s) = %+v, %v", saved.ID, stored, err)
+	}
+	if _, err := app.GenerateDrill(drill.Options{Language: "klingon"}); !errors.Is(err, drill.ErrUnknownLanguage) {
+		t.Errorf("GenerateDrill(unknown language): expected ErrUnknownLanguage, got %v", err)
+	}
+}
+
+func TestApp_GenerateWeaknessDrill(t *testing.T) {
+	app := startApp(t, t.TempDir())
+	for range 3 {
+		payload := &domain.SessionPayload{
+			SessionTextMeta: &domain.SessionTextMeta{Text: "jump"},
+			Mistakes:        map[string]int{"j": 3, "u": 1},
+			BigramMistakes:  map[string]int{"ju": 2},
+		}
+		if err := app.SaveSession(payload); err != nil {
+			t.Fatalf("SaveSession: %v", err)
+		}
+	}
+	d, err := app.GenerateWeaknessDrill(drill.WeaknessOptions{Drill: drill.Options{Words: 40}, Keys: -1, Bigrams: -1})
+	if err != nil {
+		t.Fatalf("GenerateWeaknessDrill: %v", err)
+	}
+	if len(d.Keys) != 2 || d.Keys[0].Text != "j" || len(d.Bigrams) != 1 || d.Bigrams[0].Text != "ju" {
+		t.Errorf("weaknesses = %+v, %+v; want j, u and ju", d.Keys, d.Bigrams)
+	}
+	if d.Text.Content == "" || d.Options.Layout != "en-qwerty" {
+		t.Errorf("drill = %+v, want content on the configured layout", d.Drill)
+	}
+}
+
+func TestApp_SessionArchiving(t *testing.T) {
+	dir := t.TempDir()
+	first := startApp(t, dir)
+	if err := first.UpdateSetting("sessionArchiveDays", float64(30)); err != nil {
+		t.Fatalf("UpdateSetting: %v", err)
+	}
+	if err := first.SaveSession(&domain.SessionPayload{WPM: 40}); err != nil {
+		t.Fatalf("SaveSession: %v", err)
+	}
+	first.Shutdown(context.Background())
+
+	// A session from last year, appended while the app was closed
+	journal := filepath.Join(dir, "sessions.jsonl")
+	f, err := os.OpenFile(journal, os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		t.Fatalf("OpenFile: %v", err)
+	}
+	old := time.Now().AddDate(-1, 0, 0).UTC().Format(time.RFC3339)
+	_, _ = f.WriteString(`{"id":"old","completedAt":"` + old + `"}` + "\n")
+	_ = f.Close()
+
+	app := startApp(t, dir)
+	sessions, err := app.ListSessions(0)
+	if err != nil || len(sessions) != 1 || sessions[0].WPM != 40 {
+		t.Fatalf("ListSessions = %+v, %v; want the old session archived at startup", sessions, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "sessions-archive")); err != nil {
+		t.Errorf("archive directory missing: %v", err)
+	}
+	if n, err := app.ArchiveSessions(0); err != nil || n != 1 {
+		t.Errorf("ArchiveSessions(0) = %d, %v; want the remaining session moved", n, err)
+	}
+	if _, err := app.ArchiveSessions(-1); err == nil {
+		t.Error("ArchiveSessions(-1): expected error")
+	}
+}
+
+func TestApp_TextDifficulty(t *testing.T) {
+	app := startApp(t, t.TempDir())
+	if _, err := app.SaveText(&domain.Text{ID: "words", Title: "Words", Content: strings.Repeat("the end ", 75), Language: "text"}); err != nil {
+		t.Fatalf("SaveText: %v", err)
+	}
+	d, err := app.TextDifficulty("words")
+	if err != nil {
+		t.Fatalf("TextDifficulty: %v", err)
+	}
+	if d.Sessions != 0 || d.WPM != domain.DefaultWPM || d.Metrics.Layout != "en-qwerty" {
+		t.Errorf("difficulty = %+v, want the default speed on en-qwerty", d)
+	}
+	for _, wpm := range []float64{50, 70} {
+		if err := app.SaveSession(&domain.SessionPayload{WPM: wpm}); err != nil {
+			t.Fatalf("SaveSession: %v", err)
+		}
+	}
+	faster, err := app.TextDifficulty("words")
+	if err != nil || faster.Sessions != 2 || faster.WPM != 60 || faster.EstimatedSeconds >= d.EstimatedSeconds {
+		t.Errorf("difficulty = %+v, %v; want a shorter estimate at 60 wpm", faster, err)
+	}
+
+	// Changing the layout recomputes the cached metrics
+	if err := app.UpdateSetting("keyboardLayout", "de-qwertz"); err != nil {
+		t.Fatalf("UpdateSetting: %v", err)
+	}
+	if text, err := app.Text("words"); err != nil || text.Metrics == nil || text.Metrics.Layout != "de-qwertz" {
+		t.Errorf("Text() metrics = %+v, %v; want de-qwertz", text.Metrics, err)
+	}
+}
+
+// TestApp_ConcurrentAccess hammers bound methods the way Wails calls them:
+// each from its own goroutine. Run with -race to catch unsynchronized state.
+func TestApp_ConcurrentAccess(t *testing.T) {
+	app := startApp(t, t.TempDir())
+	if err := app.SaveCategory(&domain.Category{ID: "race", Name: "Race"}); err != nil {
+		t.Fatalf("SaveCategory: %v", err)
+	}
+
+	const workers = 8
+	const iterations = 20
+	var wg sync.WaitGroup
+	for w := range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range iterations {
+				id := fmt.Sprintf("race-%d-%d", w, i)
+				_, err := app.SaveText(&domain.Text{
+					ID: id, Title: id, Content: "concurrent content", Language: "text", CategoryID: "race",
+				})
+				if err != nil {
+					t.Errorf("SaveText(%s): %v", id, err)
+					return
+				}
+				if _, err := app.Text(id); err != nil {
+					t.Errorf("Text(%s): %v", id, err)
+				}
+				if _, err := app.TextLibrary(); err != nil {
+					t.Errorf("TextLibrary: %v", err)
+				}
+				if i%2 == 0 {
+					if err := app.DeleteText(id); err != nil {
+						t.Errorf("DeleteText(%s): %v", id, err)
+					}
+				}
+				_ = app.SaveSession(&domain.SessionPayload{WPM: float64(i)})
+				_, _ = app.ListSessions(5)
+				_ = app.UpdateSetting("zenMode", i%2 == 0)
+				_, _ = app.GetSettings()
+			}
+		}()
+	}
+	wg.Wait()
+
+	lib, err := app.TextLibrary()
+	if err != nil {
+		t.Fatalf("TextLibrary: %v", err)
+	}
+	count := 0
+	for _, text := range lib.Texts {
+		if text.CategoryID == "race" {
+			count++
+		}
+	}
+	if want := workers * iterations / 2; count != want {
+		t.Errorf("got %d texts after concurrent writes, want %d", count, want)
+	}
+	sessions, _ := app.ListSessions(0)
+	if len(sessions) != workers*iterations {
+		t.Errorf("got %d sessions, want %d", len(sessions), workers*iterations)
 	}
 }
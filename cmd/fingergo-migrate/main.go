@@ -0,0 +1,65 @@
+// Copyright 2025 Asher Buk
+// SPDX-License-Identifier: Apache-2.0
+// https://github.com/AshBuk/FingerGo
+
+// Command fingergo-migrate copies a FingerGo JSON data directory into the
+// SQLite backend (fingergo.db in the same directory).
+//
+// Usage:
+//
+//	go run ./cmd/fingergo-migrate [-data-dir DIR]
+//
+// Without -data-dir the directory is resolved like FingerGo does it:
+// FINGERGO_DATA_DIR, portable mode, then the platform default.
+//
+// FingerGo must not be running. The JSON files are left in place; once the
+// database exists FingerGo opens it on startup. Set FINGERGO_BACKEND=json to
+// go back to the JSON files.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/AshBuk/FingerGo/internal/storage"
+)
+
+func main() {
+	dataDir := flag.String("data-dir", "", "FingerGo data directory (default: as resolved by FingerGo)")
+	flag.Parse()
+	root, err := storage.ResolveRoot(*dataDir)
+	if err == nil {
+		err = run(root.Path, os.Stdout)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "fingergo-migrate: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(root string, out io.Writer) error {
+	mgr, err := storage.New(root)
+	if err != nil {
+		return err
+	}
+	// Init takes the data directory lock, so a running FingerGo is detected
+	if err := mgr.Init(); err != nil {
+		return err
+	}
+	defer mgr.Close()
+	report, err := storage.MigrateToSQLite(mgr)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "migrated %d categories, %d texts and %d sessions into %s\n",
+		report.Categories, report.Texts, report.Sessions, mgr.Root())
+	for _, id := range report.Skipped {
+		fmt.Fprintf(out, "skipped text %q: content unavailable\n", id)
+	}
+	for _, warning := range mgr.Warnings() {
+		fmt.Fprintf(out, "warning: %s\n", warning)
+	}
+	return nil
+}
@@ -124,6 +124,21 @@
         console.log('FingerGo initialized successfully');
     }
 
+    /**
+     * Show data recovery warnings collected by the internal layer at startup
+     */
+    async function reportStorageWarnings() {
+        if (!window.go?.app?.App?.StorageWarnings) return;
+        try {
+            const warnings = await window.go.app.App.StorageWarnings();
+            if (warnings?.length) {
+                window.ModalManager?.show('error', { message: warnings.join('\n') });
+            }
+        } catch (err) {
+            console.error('Failed to load storage warnings:', err);
+        }
+    }
+
     /**
      * Boot application
      */
@@ -132,6 +147,7 @@
             window.ShortcutsManager?.init();
             window.LibraryManager?.init();
             window.SessionManager?.setupTypingStart();
+            reportStorageWarnings();
         });
     }
     if (document.readyState === 'loading') {
@@ -332,7 +332,7 @@
 
         const confirmed = await window.ModalManager.confirm({
             title: 'Delete Text',
-            message: `Delete "${text.title}"?\nThis action cannot be undone.`,
+            message: `Delete "${text.title}"?\nIt will be moved to the trash.`,
             confirmText: 'Delete',
             cancelText: 'Cancel',
         });
@@ -358,8 +358,8 @@
         const textsInCategory = state.library?.texts.filter(t => t.categoryId === categoryId) || [];
         const warningMsg =
             textsInCategory.length > 0
-                ? `Category "${category.name}" contains ${textsInCategory.length} text(s).\nTexts will become uncategorized.\n\nDelete category?`
-                : `Delete category "${category.name}"?`;
+                ? `Category "${category.name}" contains ${textsInCategory.length} text(s).\nThe category, its subcategories and their texts will be moved to the trash.\n\nDelete category?`
+                : `Delete category "${category.name}"?\nIt will be moved to the trash with its subcategories.`;
 
         const confirmed = await window.ModalManager.confirm({
             title: 'Delete Category',
@@ -450,6 +450,10 @@
             const success = await saveText(data);
             if (success) window.ModalManager?.hide();
         });
+        // Files under texts/ were edited outside the app (by hand or a sync tool)
+        window.runtime?.EventsOn?.('library:changed', () => {
+            refresh().catch(err => console.error('Failed to refresh library:', err));
+        });
     }
 
     // Export API
@@ -122,6 +122,8 @@
                     language,
                     isFavorite: data.text?.isFavorite || false,
                     createdAt: data.text?.createdAt || null,
+                    segmentMode: data.text?.segmentMode || '',
+                    segmentSize: data.text?.segmentSize || 0,
                 };
 
                 window.EventBus?.emit('text:save', textData);
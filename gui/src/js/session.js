@@ -59,6 +59,35 @@
         }
     }
 
+    /**
+     * Record the metadata of a loaded text and return the content to type:
+     * the current segment for chaptered texts, the whole text otherwise
+     * @param {Object} textObj
+     * @returns {Promise<string>}
+     */
+    async function resolveTextContent(textObj) {
+        currentTextMeta = {
+            textId: textObj.id || '',
+            textTitle: textObj.title || '',
+            categoryId: textObj.categoryId || '',
+            textRevision: textObj.revision || 0,
+            segmentId: '',
+        };
+        if (!textObj.segmentMode || !window.go?.app?.App?.CurrentSegment) {
+            return textObj.content;
+        }
+        try {
+            const position = await window.go.app.App.CurrentSegment(textObj.id);
+            if (position?.segment?.content?.length > 0) {
+                currentTextMeta.segmentId = position.segment.id;
+                return position.segment.content;
+            }
+        } catch (err) {
+            console.error('Failed to load text segment:', err);
+        }
+        return textObj.content;
+    }
+
     /**
      * Get default text from internal layer
      * @returns {Promise<string>}
@@ -71,12 +100,7 @@
         try {
             const textObj = await window.go.app.App.DefaultText();
             if (textObj?.content?.length > 0) {
-                currentTextMeta = {
-                    textId: textObj.id || '',
-                    textTitle: textObj.title || '',
-                    categoryId: textObj.categoryId || '',
-                };
-                return textObj.content;
+                return await resolveTextContent(textObj);
             }
             console.error('Internal layer returned empty text');
             return '';
@@ -102,12 +126,7 @@
                 console.error('Text not found:', textId);
                 return;
             }
-            currentTextMeta = {
-                textId: textObj.id || '',
-                textTitle: textObj.title || '',
-                categoryId: textObj.categoryId || '',
-            };
-            currentText = textObj.content;
+            currentText = await resolveTextContent(textObj);
             window.TypingEngine?.reset();
             window.UIManager?.renderText(currentText);
             setInitialTarget(currentText);
@@ -207,7 +226,7 @@
 
     /**
      * Get current text metadata
-     * @returns {{textId: string, textTitle: string, categoryId: string}|null}
+     * @returns {{textId: string, textTitle: string, categoryId: string, textRevision: number, segmentId: string}|null}
      */
     function getTextMeta() {
         return currentTextMeta ? { ...currentTextMeta } : null;
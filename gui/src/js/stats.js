@@ -33,7 +33,10 @@
                     textId: textMeta.textId || '',
                     textTitle: textMeta.textTitle || '',
                     categoryId: textMeta.categoryId || '',
+                    textRevision: textMeta.textRevision || 0,
+                    segmentId: textMeta.segmentId || '',
                     mistakes: sessionData.mistakes || {},
+                    bigramMistakes: sessionData.bigramMistakes || {},
                     wpm: sessionData.wpm || 0,
                     cpm: sessionData.cpm || 0,
                     accuracy: sessionData.accuracy || 100,
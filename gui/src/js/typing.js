@@ -19,6 +19,7 @@
         currentIndex: 0,
         startTime: null,
         mistakes: {},
+        bigramMistakes: {}, // previous character + mistyped one → count
         keystrokes: [],
         isActive: false,
         isPaused: false,
@@ -249,6 +250,11 @@
                 session.mistakes[expectedKey] = 0;
             }
             session.mistakes[expectedKey]++;
+            // Track mistake by bigram (previous character + expected one)
+            if (session.currentIndex > 0) {
+                const bigram = session.text[session.currentIndex - 1] + expectedChar;
+                session.bigramMistakes[bigram] = (session.bigramMistakes[bigram] || 0) + 1;
+            }
 
             window.EventBus.emit('typing:error', {
                 char: expectedChar,
@@ -291,6 +297,7 @@
             endTime: Date.now(),
             duration: getElapsedTimeSeconds(),
             mistakes: { ...session.mistakes },
+            bigramMistakes: { ...session.bigramMistakes },
             keystrokes: [...session.keystrokes],
             totalErrors: session.totalErrors,
             totalKeystrokes: session.totalKeystrokes,
@@ -405,6 +412,7 @@
         session.currentIndex = 0;
         session.startTime = null;
         session.mistakes = {};
+        session.bigramMistakes = {};
         session.keystrokes = [];
         session.isActive = false;
         session.isPaused = false;
@@ -434,6 +442,7 @@
             currentIndex: session.currentIndex,
             startTime: session.startTime,
             mistakes: { ...session.mistakes },
+            bigramMistakes: { ...session.bigramMistakes },
             keystrokes: [...session.keystrokes],
             isActive: session.isActive,
             isPaused: session.isPaused,
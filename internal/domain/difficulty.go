@@ -0,0 +1,205 @@
+// Copyright 2025 Asher Buk
+// SPDX-License-Identifier: Apache-2.0
+// https://github.com/AshBuk/FingerGo
+
+package domain
+
+import (
+	"cmp"
+	"math"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// MetricsVersion is the version of AnalyzeText's formulas. Cached metrics
+// of another version are computed again.
+const MetricsVersion = 1
+
+// Difficulty estimate defaults.
+const (
+	DefaultWPM          = 30 // typing speed assumed without session history
+	charactersPerWord   = 5  // WPM counts five characters as a word
+	maxRareBigramsShown = 5
+	defaultIndentWidth  = 4 // spaces per indentation level unless a smaller step is used
+)
+
+// TextMetrics describes how hard a text is to type on a keyboard layout.
+type TextMetrics struct {
+	Layout         string   `json:"layout"`                   // keyboard layout Shift and bigrams were judged on
+	TopRareBigrams []string `json:"topRareBigrams,omitempty"` // most frequent rare bigrams of the text
+	Letters        float64  `json:"letters"`                  // share of characters that are letters
+	Digits         float64  `json:"digits"`                   // share of digits
+	Spaces         float64  `json:"spaces"`                   // share of spaces, tabs and line breaks
+	Symbols        float64  `json:"symbols"`                  // share of punctuation and other symbols
+	Shift          float64  `json:"shift"`                    // share of characters typed with Shift or AltGr
+	RareBigrams    float64  `json:"rareBigrams"`              // share of letter bigrams uncommon in the layout's language
+	Score          float64  `json:"score"`                    // overall difficulty, 0 (easy) to 100 (hard)
+	Characters     int      `json:"characters"`
+	Lines          int      `json:"lines"`
+	IndentDepth    int      `json:"indentDepth"` // deepest indentation level
+	Version        int      `json:"version"`     // MetricsVersion computed with
+}
+
+// layoutCommonBigrams lists the most frequent letter bigrams of the language
+// each keyboard layout is made for. Letter pairs outside the list are rare:
+// slower to type because the fingers have not drilled them.
+var layoutCommonBigrams = map[string]string{
+	"en-qwerty": englishBigrams,
+	"en-dvorak": englishBigrams,
+	"de-qwertz": "en er ch de ei te in nd ie ge st ne be es un re an he au ng se it di ic sc le da ns is ra ht ih rt at ag eu li el ta al",
+	"fr-azerty": "es le de en on nt re er ou an te ai se it me la ne ra el ur ti co ue is et qu ns ar ce em ie ll pa tr ma ss us",
+	"ru-jcuken": "ст но то на ен ов ни ра во ко ро ре ал ан ос по ер пр ли ол ет ла не ти ть ва ор ел ка ом ил ит ле от де ве ог ак го",
+}
+
+const englishBigrams = "th he in er an re on at en nd ti es or te of ed is it al ar st to nt ng se ha as ou io le ve co me de hi ri ro ic ne ea ra ce li ch ll be ma si om ur"
+
+// commonBigrams is layoutCommonBigrams as lookup sets, built at
+// initialization.
+var commonBigrams map[string]map[string]bool
+
+func init() {
+	commonBigrams = make(map[string]map[string]bool, len(layoutCommonBigrams))
+	for id, list := range layoutCommonBigrams {
+		set := make(map[string]bool)
+		for _, b := range strings.Fields(list) {
+			set[b] = true
+		}
+		commonBigrams[id] = set
+	}
+}
+
+// AnalyzeText computes the difficulty metrics of content on keyboard layout
+// id. Unknown layouts count only uppercase letters as shifted and judge no
+// bigram rare.
+func AnalyzeText(content, layout string) TextMetrics {
+	m := TextMetrics{Layout: layout, Version: MetricsVersion}
+	var letters, digits, spaces, symbols, shifted, bigrams, rare int
+	rareCounts := make(map[string]int)
+	common := commonBigrams[layout]
+	var prev rune
+	for _, r := range content {
+		m.Characters++
+		switch {
+		case unicode.IsLetter(r):
+			letters++
+			if unicode.IsLetter(prev) && common != nil {
+				bigrams++
+				if b := strings.ToLower(string([]rune{prev, r})); !common[b] {
+					rare++
+					rareCounts[b]++
+				}
+			}
+		case unicode.IsDigit(r):
+			digits++
+		case unicode.IsSpace(r):
+			spaces++
+		default:
+			symbols++
+		}
+		if NeedsModifier(layout, r) {
+			shifted++
+		}
+		prev = r
+	}
+	if m.Characters == 0 {
+		return m
+	}
+	share := func(n, of int) float64 {
+		if of == 0 {
+			return 0
+		}
+		return round2(float64(n) / float64(of))
+	}
+	m.Letters = share(letters, m.Characters)
+	m.Digits = share(digits, m.Characters)
+	m.Spaces = share(spaces, m.Characters)
+	m.Symbols = share(symbols, m.Characters)
+	m.Shift = share(shifted, m.Characters)
+	m.RareBigrams = share(rare, bigrams)
+	m.TopRareBigrams = topBigrams(rareCounts, maxRareBigramsShown)
+	m.Lines = strings.Count(strings.TrimRight(content, "\n"), "\n") + 1
+	m.IndentDepth = indentDepth(content)
+	m.Score = difficultyScore(&m)
+	return m
+}
+
+// difficultyScore weighs the metrics into 0-100. Each metric saturates at a
+// level typical of hard texts: a quarter symbols (dense code), 15% shifted
+// characters, half of the letter pairs rare, 10% digits, six indentation
+// levels.
+func difficultyScore(m *TextMetrics) float64 {
+	saturate := func(v, hard float64) float64 { return min(v/hard, 1) }
+	score := 35*saturate(m.Symbols, 0.25) +
+		25*saturate(m.Shift, 0.15) +
+		20*saturate(m.RareBigrams, 0.5) +
+		10*saturate(m.Digits, 0.1) +
+		10*saturate(float64(m.IndentDepth), 6)
+	return math.Round(score*10) / 10
+}
+
+// indentDepth returns the deepest indentation level of content: a tab is a
+// level, and so is the smallest step of space indentation used, up to
+// defaultIndentWidth spaces.
+func indentDepth(content string) int {
+	type indent struct{ tabs, spaces int }
+	var indents []indent
+	width := 0 // smallest nonzero space indentation
+	for line := range strings.SplitSeq(content, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		var in indent
+	scan:
+		for _, r := range line {
+			switch r {
+			case '\t':
+				in.tabs++
+			case ' ':
+				in.spaces++
+			default:
+				break scan
+			}
+		}
+		if in.spaces > 0 && (width == 0 || in.spaces < width) {
+			width = in.spaces
+		}
+		indents = append(indents, in)
+	}
+	if width < 2 || width > defaultIndentWidth {
+		width = defaultIndentWidth
+	}
+	depth := 0
+	for _, in := range indents {
+		depth = max(depth, in.tabs+in.spaces/width)
+	}
+	return depth
+}
+
+// topBigrams returns the n most counted bigrams, ties in alphabetical order.
+func topBigrams(counts map[string]int, n int) []string {
+	if len(counts) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(counts))
+	for b := range counts {
+		keys = append(keys, b)
+	}
+	slices.SortFunc(keys, func(a, b string) int {
+		return cmp.Or(cmp.Compare(counts[b], counts[a]), cmp.Compare(a, b))
+	})
+	return keys[:min(n, len(keys))]
+}
+
+// EstimateDuration estimates the time to type a text with metrics m at wpm
+// words per minute (DefaultWPM when not positive). Harder texts are typed
+// slower: at score 100 the speed halves.
+func EstimateDuration(m *TextMetrics, wpm float64) time.Duration {
+	if wpm <= 0 {
+		wpm = DefaultWPM
+	}
+	effective := wpm * (1 - m.Score/200)
+	minutes := float64(m.Characters) / charactersPerWord / effective
+	return time.Duration(minutes * float64(time.Minute)).Round(time.Second)
+}
@@ -0,0 +1,98 @@
+// Copyright 2025 Asher Buk
+// SPDX-License-Identifier: Apache-2.0
+// https://github.com/AshBuk/FingerGo
+
+package domain
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+const (
+	proseSample = "the cat sat on the mat and then it ran to the end of the street"
+	codeSample  = "func (r *Repo) Get(id string) (*Item, error) {\n\tif v, ok := r.m[id]; ok {\n\t\treturn &v, nil\n\t}\n\treturn nil, ErrNotFound{ID: id}\n}\n"
+)
+
+func TestAnalyzeText(t *testing.T) {
+	prose := AnalyzeText(proseSample, "en-qwerty")
+	code := AnalyzeText(codeSample, "en-qwerty")
+	if prose.Score >= code.Score {
+		t.Errorf("prose score %v >= code score %v", prose.Score, code.Score)
+	}
+	if prose.Symbols != 0 || prose.Shift != 0 || prose.Lines != 1 || prose.IndentDepth != 0 {
+		t.Errorf("prose metrics = %+v, want no symbols, shift or indentation", prose)
+	}
+	if code.Symbols < 0.15 || code.Shift == 0 || code.Lines != 6 || code.IndentDepth != 2 {
+		t.Errorf("code metrics = %+v, want dense symbols, shift, 6 lines, depth 2", code)
+	}
+	if sum := prose.Letters + prose.Digits + prose.Spaces + prose.Symbols; sum < 0.99 || sum > 1.01 {
+		t.Errorf("class shares sum to %v, want 1", sum)
+	}
+	if prose.Layout != "en-qwerty" || prose.Version != MetricsVersion || prose.Characters != len(proseSample) {
+		t.Errorf("prose metrics = %+v, want layout, version and character count", prose)
+	}
+	if empty := AnalyzeText("", "en-qwerty"); empty.Score != 0 || empty.Lines != 0 {
+		t.Errorf("empty metrics = %+v, want zero", empty)
+	}
+}
+
+func TestAnalyzeText_Layouts(t *testing.T) {
+	tests := []struct {
+		content, layout string
+		shift           float64
+	}{
+		{"1234", "en-qwerty", 0},
+		{"1234", "fr-azerty", 1}, // AZERTY digits take Shift
+		{"@@", "de-qwertz", 1},   // AltGr+Q
+		{"Ab", "xx-unknown", 0.5},
+		{"№,", "ru-jcuken", 1},
+	}
+	for _, tc := range tests {
+		if got := AnalyzeText(tc.content, tc.layout).Shift; got != tc.shift {
+			t.Errorf("AnalyzeText(%q, %s).Shift = %v, want %v", tc.content, tc.layout, got, tc.shift)
+		}
+	}
+
+	// Rare bigrams are judged by the language of the layout
+	en := AnalyzeText("the then there", "en-qwerty")
+	de := AnalyzeText("the then there", "de-qwertz")
+	if en.RareBigrams >= de.RareBigrams {
+		t.Errorf("rare bigrams: en %v >= de %v", en.RareBigrams, de.RareBigrams)
+	}
+	if rare := AnalyzeText("zzxq zzxq zx", "en-qwerty"); !slices.Equal(rare.TopRareBigrams, []string{"zx", "xq", "zz"}) {
+		t.Errorf("TopRareBigrams = %q, want by count then alphabetical", rare.TopRareBigrams)
+	}
+}
+
+func TestIndentDepth(t *testing.T) {
+	tests := []struct {
+		content string
+		want    int
+	}{
+		{"a\n  b\n    c\n  d", 2},
+		{"a\n\t\tb", 2},
+		{"a\n        b", 2}, // no smaller step: 4 spaces a level
+		{"  \n   \n", 0},    // blank lines do not count
+	}
+	for _, tc := range tests {
+		if got := indentDepth(tc.content); got != tc.want {
+			t.Errorf("indentDepth(%q) = %d, want %d", tc.content, got, tc.want)
+		}
+	}
+}
+
+func TestEstimateDuration(t *testing.T) {
+	m := TextMetrics{Characters: 300}
+	if got := EstimateDuration(&m, 60); got != time.Minute {
+		t.Errorf("EstimateDuration(60 wpm) = %v, want 1m", got)
+	}
+	if got := EstimateDuration(&m, 0); got != 2*time.Minute {
+		t.Errorf("EstimateDuration(default wpm) = %v, want 2m", got)
+	}
+	m.Score = 100
+	if got := EstimateDuration(&m, 60); got != 2*time.Minute {
+		t.Errorf("EstimateDuration(score 100) = %v, want 2m", got)
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright 2025 Asher Buk
+// SPDX-License-Identifier: Apache-2.0
+// https://github.com/AshBuk/FingerGo
+
+package domain
+
+import "unicode"
+
+// asciiPrintable is every printable ASCII character except space.
+const asciiPrintable = "!\"#$%&'()*+,-./0123456789:;<=>?@ABCDEFGHIJKLMNOPQRSTUVWXYZ[\\]^_`abcdefghijklmnopqrstuvwxyz{|}~"
+
+// layoutCharacters lists what each keyboard layout of the GUI
+// (gui/src/js/layouts) types: plain and shifted keys, AltGr symbols and the
+// common dead-key letters. Space, tab and newline are typeable everywhere.
+var layoutCharacters = map[string]string{
+	"en-qwerty": asciiPrintable,
+	"en-dvorak": asciiPrintable,
+	"de-qwertz": asciiPrintable + "°§ßüÜöÖäÄ´²³€µ",
+	"fr-azerty": asciiPrintable + "²éèçàù°¨£µ§€¤" + "âêîôûÂÊÎÔÛäëïöüÿÄËÏÖÜ",
+	"ru-jcuken": "!\"%()*+,-./0123456789:;=?\\_№" +
+		"ёйцукенгшщзхъфывапролджэячсмитьбю" +
+		"ЁЙЦУКЕНГШЩЗХЪФЫВАПРОЛДЖЭЯЧСМИТЬБЮ",
+}
+
+// layoutModified lists the characters of each keyboard layout, other than
+// uppercase letters, typed with Shift or AltGr.
+var layoutModified = map[string]string{
+	"en-qwerty": "~!@#$%^&*()_+{}|:\"<>?",
+	"en-dvorak": "~!@#$%^&*()_+{}|:\"<>?",
+	"de-qwertz": "°!\"§$%&/()=?`*'>;:_" + "²³{[]}\\~@€|µ",
+	"fr-azerty": "1234567890°+¨£µ%§/.?" + "~#{[|`\\^@]}€¤",
+	"ru-jcuken": "!\"№;%:?*()_+/,",
+}
+
+// layoutRunes is layoutCharacters as lookup sets, built at initialization.
+var layoutRunes map[string]map[rune]bool
+
+// layoutModifiedRunes is layoutModified as lookup sets, built at
+// initialization.
+var layoutModifiedRunes map[string]map[rune]bool
+
+func init() {
+	layoutRunes = make(map[string]map[rune]bool, len(layoutCharacters))
+	for id, chars := range layoutCharacters {
+		set := map[rune]bool{' ': true, '\t': true, '\n': true}
+		for _, r := range chars {
+			set[r] = true
+		}
+		layoutRunes[id] = set
+	}
+	layoutModifiedRunes = make(map[string]map[rune]bool, len(layoutModified))
+	for id, chars := range layoutModified {
+		set := make(map[rune]bool, len(chars))
+		for _, r := range chars {
+			set[r] = true
+		}
+		layoutModifiedRunes[id] = set
+	}
+}
+
+// IsKnownLayout reports whether id names a keyboard layout of the GUI.
+func IsKnownLayout(id string) bool {
+	_, ok := layoutRunes[id]
+	return ok
+}
+
+// CanType reports whether r can be typed on keyboard layout id. Unknown
+// layouts type nothing.
+func CanType(id string, r rune) bool {
+	return layoutRunes[id][r]
+}
+
+// NeedsModifier reports whether typing r on keyboard layout id takes Shift or
+// AltGr. Uppercase letters always do.
+func NeedsModifier(id string, r rune) bool {
+	return unicode.IsUpper(r) || layoutModifiedRunes[id][r]
+}
@@ -0,0 +1,40 @@
+// Copyright 2025 Asher Buk
+// SPDX-License-Identifier: Apache-2.0
+// https://github.com/AshBuk/FingerGo
+
+package domain
+
+import "testing"
+
+func TestCanType(t *testing.T) {
+	tests := []struct {
+		layout string
+		r      rune
+		want   bool
+	}{
+		{"en-qwerty", 'a', true},
+		{"en-qwerty", '~', true},
+		{"en-qwerty", '\t', true},
+		{"en-qwerty", 'ü', false},
+		{"en-dvorak", '{', true},
+		{"de-qwertz", 'ß', true},
+		{"de-qwertz", '@', true}, // AltGr+Q
+		{"fr-azerty", 'ê', true}, // dead circumflex
+		{"ru-jcuken", 'Ж', true},
+		{"ru-jcuken", 'a', false},
+		{"xx-unknown", 'a', false},
+	}
+	for _, tc := range tests {
+		if got := CanType(tc.layout, tc.r); got != tc.want {
+			t.Errorf("CanType(%q, %q) = %v, want %v", tc.layout, tc.r, got, tc.want)
+		}
+	}
+}
+
+func TestLayoutCharactersCoverGUILayouts(t *testing.T) {
+	for _, id := range []string{"en-qwerty", "en-dvorak", "de-qwertz", "fr-azerty", "ru-jcuken"} {
+		if !IsKnownLayout(id) {
+			t.Errorf("layout %q of gui/src/js/layouts is missing", id)
+		}
+	}
+}
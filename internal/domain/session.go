@@ -18,12 +18,16 @@ type TypingSession struct {
 	StartedAt   time.Time      `json:"startedAt"`   // session start time (UTC)
 	CompletedAt time.Time      `json:"completedAt"` // session end time (UTC)
 	Mistakes    map[string]int `json:"mistakes,omitempty"`
+	// BigramMistakes counts mistakes by the preceding character plus the
+	// expected one ("th" when "h" was mistyped after "t").
+	BigramMistakes map[string]int `json:"bigramMistakes,omitempty"`
 
 	TextPreview string `json:"textPreview"` // excerpt from the source text
 	TextTitle   string `json:"textTitle"`   // human readable label
 	CategoryID  string `json:"categoryId,omitempty"`
-	TextID      string `json:"textId,omitempty"` // optional reference to text catalog
-	ID          string `json:"id"`               // stable identifier (UUID)
+	TextID      string `json:"textId,omitempty"`    // optional reference to text catalog
+	SegmentID   string `json:"segmentId,omitempty"` // segment typed of a chaptered text
+	ID          string `json:"id"`                  // stable identifier (UUID)
 
 	WPM      float64 `json:"wpm"`
 	CPM      float64 `json:"cpm"`
@@ -33,21 +37,27 @@ type TypingSession struct {
 	TotalKeystrokes int `json:"totalKeystrokes"`
 	TotalErrors     int `json:"totalErrors"`
 	CharacterCount  int `json:"characterCount"`
+	TextRevision    int `json:"textRevision,omitempty"` // Text.Revision that was typed (0 = unknown)
+	SegmentIndex    int `json:"segmentIndex,omitempty"` // position of SegmentID in the text when typed (0-based)
 }
 
 // SessionTextMeta aggregates textual metadata provided by the GUI payload.
 type SessionTextMeta struct {
-	Text       string `json:"text"`
-	TextTitle  string `json:"textTitle"`
-	CategoryID string `json:"categoryId"`
-	TextID     string `json:"textId"`
+	Text         string `json:"text"`
+	TextTitle    string `json:"textTitle"`
+	CategoryID   string `json:"categoryId"`
+	TextID       string `json:"textId"`
+	SegmentID    string `json:"segmentId"`    // segment typed of a chaptered text; empty for the whole text
+	TextRevision int    `json:"textRevision"` // Text.Revision loaded by the GUI; 0 lets the app fill it in
+	SegmentIndex int    `json:"segmentIndex"` // position of SegmentID; the app fills it in
 }
 
 // SessionPayload mirrors the structure sent from the GUI when a session completes.
 type SessionPayload struct {
 	*SessionTextMeta
 
-	Mistakes map[string]int `json:"mistakes"` // key → mistake count
+	Mistakes       map[string]int `json:"mistakes"`       // key → mistake count
+	BigramMistakes map[string]int `json:"bigramMistakes"` // two characters → mistake count of the second
 
 	WPM      float64 `json:"wpm"`
 	CPM      float64 `json:"cpm"`
@@ -79,8 +89,13 @@ func (p *SessionPayload) ToTypingSession(fallback time.Time) TypingSession {
 			end = start.Add(duration)
 		}
 	}
-	rawText, rawTitle, rawCategory, rawTextID := "", "", "", ""
+	rawText, rawTitle, rawCategory, rawTextID, rawSegmentID := "", "", "", "", ""
+	revision, segmentIndex := 0, 0
 	if p.SessionTextMeta != nil {
+		revision = max(0, p.TextRevision)
+		if rawSegmentID = strings.TrimSpace(p.SegmentID); rawSegmentID != "" {
+			segmentIndex = max(0, p.SegmentIndex)
+		}
 		rawText = p.Text
 		rawTitle = p.TextTitle
 		rawCategory = p.CategoryID
@@ -92,6 +107,7 @@ func (p *SessionPayload) ToTypingSession(fallback time.Time) TypingSession {
 	}
 	preview := derivePreview(rawText)
 	mistakes := cloneMistakes(p.Mistakes)
+	bigrams := cloneBigramMistakes(p.BigramMistakes)
 	charCount := utf8.RuneCountInString(rawText)
 	// Clamp metrics to valid ranges (defense in depth)
 	wpm := max(0.0, p.WPM)
@@ -101,6 +117,9 @@ func (p *SessionPayload) ToTypingSession(fallback time.Time) TypingSession {
 	totalErrors := clamp(p.TotalErrors, 0, totalKeystrokes)
 	return TypingSession{
 		TextID:          strings.TrimSpace(rawTextID),
+		TextRevision:    revision,
+		SegmentID:       rawSegmentID,
+		SegmentIndex:    segmentIndex,
 		TextTitle:       title,
 		TextPreview:     preview,
 		CategoryID:      strings.TrimSpace(rawCategory),
@@ -114,6 +133,7 @@ func (p *SessionPayload) ToTypingSession(fallback time.Time) TypingSession {
 		TotalErrors:     totalErrors,
 		CharacterCount:  charCount,
 		Mistakes:        mistakes,
+		BigramMistakes:  bigrams,
 	}
 }
 
@@ -174,6 +194,20 @@ func cloneMistakes(src map[string]int) map[string]int {
 	return dst
 }
 
+// cloneBigramMistakes is cloneMistakes keeping only two-character keys.
+func cloneBigramMistakes(src map[string]int) map[string]int {
+	dst := cloneMistakes(src)
+	for k := range dst {
+		if utf8.RuneCountInString(k) != 2 {
+			delete(dst, k)
+		}
+	}
+	if len(dst) == 0 {
+		return nil
+	}
+	return dst
+}
+
 func round2(value float64) float64 {
 	if value == 0 {
 		return 0
@@ -174,6 +174,31 @@ func TestSessionPayload_ToTypingSession(t *testing.T) {
 			t.Error("valid mistake should be preserved")
 		}
 	})
+
+	t.Run("keeps only two-character bigrams", func(t *testing.T) {
+		payload := &SessionPayload{
+			SessionTextMeta: &SessionTextMeta{Text: "test"},
+			BigramMistakes:  map[string]int{"th": 2, "ше": 1, "t": 4, "the": 1, "er": 0},
+		}
+		session := payload.ToTypingSession(fallback)
+		if len(session.BigramMistakes) != 2 || session.BigramMistakes["th"] != 2 || session.BigramMistakes["ше"] != 1 {
+			t.Errorf("got bigram mistakes %v, want th and ше", session.BigramMistakes)
+		}
+	})
+
+	t.Run("keeps segment only with an ID", func(t *testing.T) {
+		payload := &SessionPayload{
+			SessionTextMeta: &SessionTextMeta{Text: "test", SegmentID: " 3f2a ", SegmentIndex: 2},
+		}
+		if session := payload.ToTypingSession(fallback); session.SegmentID != "3f2a" || session.SegmentIndex != 2 {
+			t.Errorf("got segment %q#%d, want 3f2a#2", session.SegmentID, session.SegmentIndex)
+		}
+
+		payload.SegmentID = ""
+		if session := payload.ToTypingSession(fallback); session.SegmentIndex != 0 {
+			t.Errorf("got segment index %d without an ID, want 0", session.SegmentIndex)
+		}
+	})
 }
 
 func TestTruncateRunes(t *testing.T) {
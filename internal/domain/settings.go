@@ -6,25 +6,42 @@ package domain
 
 // Settings holds user preferences persisted in settings.json.
 type Settings struct {
-	Theme          string  `json:"theme"`          // "dark" | "light"
-	LastTextID     string  `json:"lastTextId"`     // last opened text ID for session restore
-	KeyboardLayout string  `json:"keyboardLayout"` // keyboard layout ID (e.g., "en-qwerty", "en-dvorak")
-	ShowKeyboard   bool    `json:"showKeyboard"`   // keyboard section visibility
-	ShowStatsBar   bool    `json:"showStatsBar"`   // stats bar visibility
-	ZenMode        bool    `json:"zenMode"`        // focus mode (hides both keyboard and stats)
-	StrictMode     bool    `json:"strictMode"`     // require backspace to fix errors (true) or allow direct correction (false)
-	TextZoom       float64 `json:"textZoom"`       // text display zoom multiplier (0.5–2.0, default 1.0)
+	Theme              string  `json:"theme"`              // "dark" | "light"
+	LastTextID         string  `json:"lastTextId"`         // last opened text ID for session restore
+	KeyboardLayout     string  `json:"keyboardLayout"`     // keyboard layout ID (e.g., "en-qwerty", "en-dvorak")
+	ShowKeyboard       bool    `json:"showKeyboard"`       // keyboard section visibility
+	ShowStatsBar       bool    `json:"showStatsBar"`       // stats bar visibility
+	ZenMode            bool    `json:"zenMode"`            // focus mode (hides both keyboard and stats)
+	StrictMode         bool    `json:"strictMode"`         // require backspace to fix errors (true) or allow direct correction (false)
+	TextZoom           float64 `json:"textZoom"`           // text display zoom multiplier (0.5–2.0, default 1.0)
+	TrashRetentionDays int     `json:"trashRetentionDays"` // days deleted texts stay in the trash (0 = keep forever)
+	SessionArchiveDays int     `json:"sessionArchiveDays"` // age at which sessions move to the yearly archive (0 = never)
+
+	NormalizeRules map[string]NormalizeRules `json:"normalizeRules,omitempty"` // language key → rules replacing the defaults
+}
+
+// NormalizeRules selects the content normalization steps applied to the
+// texts of one language (see storage.NormalizeContent).
+type NormalizeRules struct {
+	TabWidth      int  `json:"tabWidth"`      // tab stop width tabs are expanded to; 0 keeps tabs
+	LineEndings   bool `json:"lineEndings"`   // CRLF and lone CR become LF
+	Emoji         bool `json:"emoji"`         // emoji and pictographs are dropped
+	Spaces        bool `json:"spaces"`        // Unicode spaces become spaces, invisible characters are dropped
+	Punctuation   bool `json:"punctuation"`   // curly quotes, dashes and ellipses become ASCII
+	TrailingSpace bool `json:"trailingSpace"` // spaces and tabs at line ends are dropped
 }
 
 // DefaultSettings returns factory defaults for new installations.
 func DefaultSettings() Settings {
 	return Settings{
-		Theme:          "dark",
-		ShowKeyboard:   true,
-		ShowStatsBar:   true,
-		ZenMode:        false,
-		StrictMode:     true,        // require backspace to fix errors (false = cheat mode)
-		KeyboardLayout: "en-qwerty", // default keyboard layout
-		TextZoom:       1.0,         // 100% text size
+		Theme:              "dark",
+		ShowKeyboard:       true,
+		ShowStatsBar:       true,
+		ZenMode:            false,
+		StrictMode:         true,        // require backspace to fix errors (false = cheat mode)
+		KeyboardLayout:     "en-qwerty", // default keyboard layout
+		TextZoom:           1.0,         // 100% text size
+		TrashRetentionDays: 30,          // purge trash entries after a month
+		SessionArchiveDays: 0,           // keep the whole history in the live journal
 	}
 }
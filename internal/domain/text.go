@@ -4,25 +4,42 @@
 
 package domain
 
-import "time"
+import (
+	"path/filepath"
+	"strings"
+	"time"
+)
 
 // Text represents a single training entry available to the typing engine.
 type Text struct {
-	CreatedAt  time.Time `json:"createdAt"`  // when the text was added
-	ID         string    `json:"id"`         // unique identifier (UUID)
-	Title      string    `json:"title"`      // display name in library
-	Content    string    `json:"content"`    // the actual text to type
-	CategoryID string    `json:"categoryId"` // parent category (empty if root)
-	Language   string    `json:"language"`   // tokenization rules: go, js, py, plain
-	IsFavorite bool      `json:"isFavorite"` // user-pinned for quick access
+	CreatedAt   time.Time    `json:"createdAt"`             // when the text was added
+	RevisedAt   time.Time    `json:"revisedAt,omitzero"`    // when the current revision was saved (zero = saved before it was recorded)
+	Metrics     *TextMetrics `json:"metrics,omitempty"`     // difficulty, computed by the store when the content is saved
+	ID          string       `json:"id"`                    // unique identifier (UUID)
+	Title       string       `json:"title"`                 // display name in library
+	Content     string       `json:"content"`               // the actual text to type
+	CategoryID  string       `json:"categoryId"`            // parent category (empty if root)
+	Language    string       `json:"language"`              // tokenization rules: go, js, py, plain
+	SegmentMode string       `json:"segmentMode,omitempty"` // how a chaptered text is split into segments (empty = typed whole)
+	Revision    int          `json:"revision,omitempty"`    // content revision, bumped by each content change (0 = saved before revisions)
+	SegmentSize int          `json:"segmentSize,omitempty"` // paragraphs, lines or characters per segment (0 = mode default)
+	IsFavorite  bool         `json:"isFavorite"`            // user-pinned for quick access
 }
 
+// Segment modes of chaptered texts (Text.SegmentMode).
+const (
+	SegmentParagraphs = "paragraphs" // SegmentSize paragraphs per segment
+	SegmentLines      = "lines"      // SegmentSize lines per segment
+	SegmentChars      = "chars"      // up to SegmentSize characters, split at paragraph, line or word boundaries
+)
+
 // Category groups texts into hierarchical collections for browsing.
 type Category struct {
-	ID       string `json:"id"`                 // unique identifier (UUID)
-	Name     string `json:"name"`               // display name in library
-	ParentID string `json:"parentId,omitempty"` // parent for nesting (empty if root)
-	Icon     string `json:"icon,omitempty"`     // emoji for visual representation
+	ID        string `json:"id"`                  // unique identifier (UUID)
+	Name      string `json:"name"`                // display name in library
+	ParentID  string `json:"parentId,omitempty"`  // parent for nesting (empty if root)
+	Icon      string `json:"icon,omitempty"`      // emoji for visual representation
+	SortOrder int    `json:"sortOrder,omitempty"` // display position among siblings (ascending)
 }
 
 // TextLibrary aggregates available texts and their categories.
@@ -34,23 +51,24 @@ type TextLibrary struct {
 
 // LanguageInfo describes a supported programming language.
 type LanguageInfo struct {
-	Key   string `json:"key"`   // identifier used in Text.Language
-	Icon  string `json:"icon"`  // emoji for UI display
-	Label string `json:"label"` // human-readable name
+	Key   string `json:"key"`             // identifier used in Text.Language
+	Icon  string `json:"icon"`            // emoji for UI display
+	Label string `json:"label"`           // human-readable name
+	Prose bool   `json:"prose,omitempty"` // natural language rather than code
 }
 
 // supportedLanguages is the single source of truth for language definitions.
 var supportedLanguages = []LanguageInfo{
 	// General text
-	{Key: "text", Icon: "📄", Label: "Plain Text"},
-	{Key: "english", Icon: "🇬🇧", Label: "English"},
-	{Key: "russian", Icon: "🇷🇺", Label: "Russian"},
-	{Key: "french", Icon: "🇫🇷", Label: "French"},
-	{Key: "german", Icon: "🇩🇪", Label: "German"},
-	{Key: "spanish", Icon: "🇪🇸", Label: "Spanish"},
-	{Key: "italian", Icon: "🇮🇹", Label: "Italian"},
-	{Key: "chinese", Icon: "🇨🇳", Label: "Chinese"},
-	{Key: "hindi", Icon: "🇮🇳", Label: "Hindi"},
+	{Key: "text", Icon: "📄", Label: "Plain Text", Prose: true},
+	{Key: "english", Icon: "🇬🇧", Label: "English", Prose: true},
+	{Key: "russian", Icon: "🇷🇺", Label: "Russian", Prose: true},
+	{Key: "french", Icon: "🇫🇷", Label: "French", Prose: true},
+	{Key: "german", Icon: "🇩🇪", Label: "German", Prose: true},
+	{Key: "spanish", Icon: "🇪🇸", Label: "Spanish", Prose: true},
+	{Key: "italian", Icon: "🇮🇹", Label: "Italian", Prose: true},
+	{Key: "chinese", Icon: "🇨🇳", Label: "Chinese", Prose: true},
+	{Key: "hindi", Icon: "🇮🇳", Label: "Hindi", Prose: true},
 	// Systems programming
 	{Key: "c", Icon: "🔧", Label: "C"},
 	{Key: "cpp", Icon: "⚙️", Label: "C++"},
@@ -83,14 +101,72 @@ var supportedLanguages = []LanguageInfo{
 	{Key: "bash", Icon: "🖥️", Label: "Bash"},
 }
 
+// languageExtensions lists the file extensions (lowercase, with dot) that
+// import detects as each language.
+var languageExtensions = map[string][]string{
+	"text":    {".txt", ".text", ".md", ".rst"},
+	"c":       {".c", ".h"},
+	"cpp":     {".cc", ".cpp", ".cxx", ".hh", ".hpp", ".hxx"},
+	"rust":    {".rs"},
+	"go":      {".go"},
+	"zig":     {".zig"},
+	"js":      {".js", ".mjs", ".cjs", ".jsx"},
+	"ts":      {".ts", ".mts", ".cts", ".tsx"},
+	"py":      {".py", ".pyw", ".pyi"},
+	"rb":      {".rb", ".rake"},
+	"php":     {".php"},
+	"lua":     {".lua"},
+	"java":    {".java"},
+	"kotlin":  {".kt", ".kts"},
+	"scala":   {".scala", ".sc"},
+	"csharp":  {".cs"},
+	"haskell": {".hs", ".lhs"},
+	"elixir":  {".ex", ".exs"},
+	"swift":   {".swift"},
+	"dart":    {".dart"},
+	"sql":     {".sql"},
+	"json":    {".json"},
+	"yaml":    {".yaml", ".yml"},
+	"bash":    {".sh", ".bash", ".zsh"},
+}
+
+// languageAliases maps common names of a language (Markdown fence info
+// strings, editor modes) to its key, for names that are neither a key nor an
+// extension in languageExtensions.
+var languageAliases = map[string]string{
+	"plain": "text", "plaintext": "text", "txt": "text", "markdown": "text",
+	"c++": "cpp", "cxx": "cpp", "golang": "go", "javascript": "js", "node": "js",
+	"typescript": "ts", "python": "py", "python3": "py", "ruby": "rb",
+	"c#": "csharp", "cs": "csharp", "kt": "kotlin",
+	"shell": "bash", "sh": "bash", "zsh": "bash", "console": "bash", "shell-session": "bash",
+	"postgresql": "sql", "postgres": "sql", "mysql": "sql", "sqlite": "sql",
+	"jsonc": "json", "yml": "yaml",
+}
+
+// extensionLanguages is the reverse of languageExtensions, built at initialization.
+var extensionLanguages map[string]string
+
 // validLanguageKeys is a lookup map for O(1) validation.
 // Built from supportedLanguages at initialization.
 var validLanguageKeys map[string]bool
 
+// proseLanguageKeys holds the keys of natural languages, built with validLanguageKeys.
+var proseLanguageKeys map[string]bool
+
 func init() {
 	validLanguageKeys = make(map[string]bool, len(supportedLanguages))
+	proseLanguageKeys = make(map[string]bool)
 	for _, lang := range supportedLanguages {
 		validLanguageKeys[lang.Key] = true
+		if lang.Prose {
+			proseLanguageKeys[lang.Key] = true
+		}
+	}
+	extensionLanguages = make(map[string]string)
+	for key, exts := range languageExtensions {
+		for _, ext := range exts {
+			extensionLanguages[ext] = key
+		}
 	}
 }
 
@@ -99,6 +175,47 @@ func SupportedLanguages() []LanguageInfo {
 	return supportedLanguages
 }
 
+// LanguageForFile returns the language key for a file name based on its
+// extension, or "text" when the extension is unknown.
+func LanguageForFile(name string) string {
+	if key, ok := DetectLanguage(name); ok {
+		return key
+	}
+	return "text"
+}
+
+// DetectLanguage returns the language key for a file name's extension and
+// whether the extension is known.
+func DetectLanguage(name string) (string, bool) {
+	key, ok := extensionLanguages[strings.ToLower(filepath.Ext(name))]
+	return key, ok
+}
+
+// LanguageForAlias resolves a language name as written by people, such as
+// the info string of a Markdown code fence ("go", "golang", "py", "c++"), to a
+// language key. The lookup is case-insensitive and tries keys, aliases and
+// file extensions in that order.
+func LanguageForAlias(name string) (string, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return "", false
+	}
+	if IsValidLanguage(name) {
+		return name, true
+	}
+	if key, ok := languageAliases[name]; ok {
+		return key, true
+	}
+	key, ok := extensionLanguages["."+name]
+	return key, ok
+}
+
+// IsProseLanguage reports whether key is a natural language (plain text,
+// English, ...) rather than code.
+func IsProseLanguage(key string) bool {
+	return proseLanguageKeys[key]
+}
+
 // IsValidLanguage checks if a language key is supported.
 // Uses O(1) map lookup for performance.
 func IsValidLanguage(key string) bool {
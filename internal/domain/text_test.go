@@ -71,3 +71,55 @@ func TestIsValidLanguage(t *testing.T) {
 		}
 	})
 }
+
+func TestLanguageForFile(t *testing.T) {
+	tests := map[string]string{
+		"main.go":           "go",
+		"lib.RS":            "rust",
+		"script.py":         "py",
+		"deploy.yml":        "yaml",
+		"notes.txt":         "text",
+		"README":            "text",
+		"archive.tar.gz":    "text",
+		"dir/component.tsx": "ts",
+	}
+	for name, want := range tests {
+		if got := LanguageForFile(name); got != want {
+			t.Errorf("LanguageForFile(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestLanguageForAlias(t *testing.T) {
+	tests := map[string]string{
+		"go":     "go",
+		"Golang": "go",
+		"python": "py",
+		"c++":    "cpp",
+		"sh":     "bash",
+		"tsx":    "ts",
+		"yml":    "yaml",
+		"sql":    "sql",
+		"":       "",
+		"cobol":  "",
+	}
+	for name, want := range tests {
+		got, ok := LanguageForAlias(name)
+		if got != want || ok != (want != "") {
+			t.Errorf("LanguageForAlias(%q) = %q, %v; want %q", name, got, ok, want)
+		}
+	}
+}
+
+func TestLanguageExtensionsUseValidKeys(t *testing.T) {
+	for key := range languageExtensions {
+		if !IsValidLanguage(key) {
+			t.Errorf("languageExtensions has unknown language %q", key)
+		}
+	}
+	for alias, key := range languageAliases {
+		if !IsValidLanguage(key) {
+			t.Errorf("languageAliases maps %q to unknown language %q", alias, key)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+// Copyright 2025 Asher Buk
+// SPDX-License-Identifier: Apache-2.0
+// https://github.com/AshBuk/FingerGo
+
+package drill
+
+// CharSet restricts a drill to the letters of part of the keyboard.
+type CharSet string
+
+// Character sets; the letters depend on the keyboard layout.
+const (
+	CharSetAll       CharSet = ""           // every letter of the word list
+	CharSetHomeRow   CharSet = "home-row"   // the row the fingers rest on
+	CharSetTopRow    CharSet = "top-row"    // the letter row above it
+	CharSetBottomRow CharSet = "bottom-row" // the letter row below it
+	CharSetLeftHand  CharSet = "left-hand"  // letters typed with the left hand
+	CharSetRightHand CharSet = "right-hand" // letters typed with the right hand
+)
+
+// layoutRows lists the lowercase letters of each letter row (top, home,
+// bottom) of the keyboard layouts in domain.IsKnownLayout, left to right.
+var layoutRows = map[string][3]string{
+	"en-qwerty": {"qwertyuiop", "asdfghjkl", "zxcvbnm"},
+	"en-dvorak": {"pyfgcrl", "aoeuidhtns", "qjkxbmwvz"},
+	"de-qwertz": {"qwertzuiopü", "asdfghjklöä", "yxcvbnm"},
+	"fr-azerty": {"azertyuiop", "qsdfghjklm", "wxcvbn"},
+	"ru-jcuken": {"йцукенгшщзхъ", "фывапролджэ", "ячсмитьбю"},
+}
+
+// leftKeys is how many letters of each row (top, home, bottom) the left hand
+// types, per layout. Dvorak puts punctuation under the left hand, so it
+// types fewer letters there.
+var leftKeys = map[string][3]int{
+	"en-qwerty": {5, 5, 5},
+	"en-dvorak": {2, 5, 4},
+	"de-qwertz": {5, 5, 5},
+	"fr-azerty": {5, 5, 5},
+	"ru-jcuken": {5, 5, 5},
+}
+
+// CharSets returns the character sets a drill can be restricted to.
+func CharSets() []CharSet {
+	return []CharSet{CharSetAll, CharSetHomeRow, CharSetTopRow, CharSetBottomRow, CharSetLeftHand, CharSetRightHand}
+}
+
+// letters returns the letters of set on layout, or nil for CharSetAll. ok is
+// false for an unknown set or layout.
+func letters(set CharSet, layout string) (chars []rune, ok bool) {
+	if set == CharSetAll {
+		return nil, true
+	}
+	rows, known := layoutRows[layout]
+	if !known {
+		return nil, false
+	}
+	split := leftKeys[layout]
+	for i, row := range rows {
+		r := []rune(row)
+		switch set {
+		case CharSetTopRow, CharSetHomeRow, CharSetBottomRow:
+			if rowSets[i] == set {
+				chars = append(chars, r...)
+			}
+		case CharSetLeftHand:
+			chars = append(chars, r[:split[i]]...)
+		case CharSetRightHand:
+			chars = append(chars, r[split[i]:]...)
+		default:
+			return nil, false
+		}
+	}
+	return chars, true
+}
+
+// rowSets names the rows of layoutRows.
+var rowSets = [3]CharSet{CharSetTopRow, CharSetHomeRow, CharSetBottomRow}
@@ -0,0 +1,362 @@
+// Copyright 2025 Asher Buk
+// SPDX-License-Identifier: Apache-2.0
+// https://github.com/AshBuk/FingerGo
+
+// Package drill generates practice texts from embedded word lists.
+//
+// Words are drawn from per-language frequency lists, optionally restricted
+// to the letters of part of the keyboard or weighted toward focus letters
+// and bigrams, with punctuation, numbers and capitals mixed in. Weakness
+// drills pick the focus from the mistakes of recent sessions. A drill is
+// determined by its Options alone: generating
+// with the same seed replays it exactly.
+package drill
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	domain "github.com/AshBuk/FingerGo/internal/domain"
+)
+
+// Option defaults and limits.
+const (
+	defaultLanguage   = "english"
+	defaultLayout     = "en-qwerty"
+	defaultWords      = 50
+	defaultVocabulary = 100
+	maxWords          = 1000
+	minPoolWords      = 10        // below this a character set drills letter groups instead of words
+	maxFocus          = 20        // characters and bigrams in Options.Focus
+	focusShare        = 0.6       // share of words drawn for a focus entry
+	maxSeed           = 1<<53 - 1 // largest integer a JavaScript number holds exactly
+)
+
+// Letter groups drilled when a character set leaves too few words.
+const (
+	minGroupLength = 2
+	maxGroupLength = 6
+)
+
+// Generation errors.
+var (
+	ErrInvalidOptions  = errors.New("drill: invalid options")
+	ErrUnknownLanguage = errors.New("drill: no word list for language")
+)
+
+// Options describes a drill. Zero values select the defaults.
+type Options struct {
+	Language    string   `json:"language"`        // word list (see Languages); default english
+	Layout      string   `json:"layout"`          // keyboard layout the character set is taken from; default en-qwerty
+	CharSet     CharSet  `json:"charSet"`         // letters words are limited to; empty for all
+	Focus       []string `json:"focus,omitempty"` // letters or bigrams (lowercase) the drill over-represents
+	Seed        int64    `json:"seed"`            // 0 picks a random seed, returned in Drill.Options
+	Punctuation float64  `json:"punctuation"`     // share of words followed by a punctuation mark (0-1)
+	Numbers     float64  `json:"numbers"`         // share of words replaced by a number (0-1)
+	Capitals    float64  `json:"capitals"`        // share of words capitalized (0-1)
+	Words       int      `json:"words"`           // words in the drill; default 50
+	Vocabulary  int      `json:"vocabulary"`      // most frequent words of the list to draw from; default 100
+}
+
+// Drill is a generated practice text and the options that replay it.
+type Drill struct {
+	Text    domain.Text `json:"text"`    // not in the library; has no ID until saved
+	Options Options     `json:"options"` // with defaults and the seed filled in
+}
+
+// punctuationMarks follow a word; sentence ends capitalize the next word.
+// Commas and periods are listed twice to appear as often as in prose.
+var punctuationMarks = []string{",", ",", ".", ".", ";", ":", "!", "?", "()", `""`}
+
+// Generate builds the drill described by opts.
+func Generate(opts Options) (Drill, error) {
+	opts, err := opts.resolve()
+	if err != nil {
+		return Drill{}, err
+	}
+	src, err := newWordSource(&opts)
+	if err != nil {
+		return Drill{}, err
+	}
+	rng := rand.New(rand.NewPCG(uint64(opts.Seed), 0))
+	words := make([]string, opts.Words)
+	sentenceStart := false
+	for i := range words {
+		// Every word consumes the same draws, so changing a density
+		// keeps the word sequence of a seed
+		words[i], sentenceStart = opts.decorate(rng, src.next(rng), sentenceStart)
+	}
+	return Drill{
+		Text: domain.Text{
+			Title:    title(&opts),
+			Content:  strings.Join(words, " "),
+			Language: opts.Language,
+		},
+		Options: opts,
+	}, nil
+}
+
+// wordSource is what the words of a drill are drawn from.
+type wordSource struct {
+	pool     []string     // nil when the character set leaves too few words
+	focus    []focusEntry // words for Options.Focus
+	alphabet []rune       // letters of the character set, or of the word list
+}
+
+// newWordSource resolves the word list and character set of opts (resolved)
+// into the words and letters a drill is drawn from.
+func newWordSource(opts *Options) (wordSource, error) {
+	list, ok := loadWordLists()[opts.Language]
+	if !ok {
+		return wordSource{}, fmt.Errorf("%w: %q", ErrUnknownLanguage, opts.Language)
+	}
+	chars, ok := letters(opts.CharSet, opts.Layout)
+	if !ok {
+		return wordSource{}, fmt.Errorf("%w: character set %q on layout %q", ErrInvalidOptions, opts.CharSet, opts.Layout)
+	}
+	for _, f := range opts.Focus {
+		if chars != nil && !onlyLetters(f, chars) {
+			return wordSource{}, fmt.Errorf("%w: focus entry %q outside character set %q", ErrInvalidOptions, f, opts.CharSet)
+		}
+	}
+	src := wordSource{
+		pool:     wordPool(list, chars, opts.Vocabulary),
+		focus:    focusWords(list, chars, opts.Focus),
+		alphabet: chars,
+	}
+	if src.alphabet == nil {
+		src.alphabet = listLetters(list)
+	}
+	return src, nil
+}
+
+// next draws a word, from the focus entries for a focusShare of them.
+func (s *wordSource) next(rng *rand.Rand) string {
+	if len(s.focus) > 0 && rng.Float64() < focusShare {
+		return pickFocusWord(rng, s.focus, s.alphabet)
+	}
+	return pickWord(rng, s.pool, s.alphabet)
+}
+
+// decorate applies the number, capital and punctuation densities to word.
+// It reports whether the word ends a sentence, which capitalizes the next.
+func (o *Options) decorate(rng *rand.Rand, word string, sentenceStart bool) (string, bool) {
+	number, capital, mark := rng.Float64(), rng.Float64(), rng.Float64()
+	markIdx := rng.IntN(len(punctuationMarks))
+	digits := strconv.Itoa(rng.IntN(10_000))
+
+	if number < o.Numbers {
+		word = digits
+	} else if capital < o.Capitals || sentenceStart {
+		word = capitalize(word)
+	}
+	if mark >= o.Punctuation {
+		return word, false
+	}
+	switch p := punctuationMarks[markIdx]; len(p) {
+	case 2:
+		return p[:1] + word + p[1:], false
+	default:
+		return word + p, p == "." || p == "!" || p == "?"
+	}
+}
+
+// TextID returns the library ID of the drill generated from opts (resolved,
+// as in Drill.Options): the same drill always saves under the same ID.
+func TextID(opts Options) string {
+	data, _ := json.Marshal(opts) // plain struct: cannot fail
+	sum := sha256.Sum256(data)
+	return "drill-" + hex.EncodeToString(sum[:6])
+}
+
+// resolve fills in defaults, picks a seed and checks the ranges.
+func (o Options) resolve() (Options, error) {
+	if o.Language == "" {
+		o.Language = defaultLanguage
+	}
+	if o.Layout == "" {
+		o.Layout = defaultLayout
+	}
+	if o.Words == 0 {
+		o.Words = defaultWords
+	}
+	if o.Vocabulary == 0 {
+		o.Vocabulary = defaultVocabulary
+	}
+	if o.Seed == 0 {
+		o.Seed = rand.Int64N(maxSeed) + 1
+	}
+	return o, o.validate()
+}
+
+// validate checks the ranges of resolved options.
+func (o *Options) validate() error {
+	switch {
+	case o.Seed < 0 || o.Seed > maxSeed:
+		return fmt.Errorf("%w: seed out of range [1, %d]: %d", ErrInvalidOptions, int64(maxSeed), o.Seed)
+	case o.Words < 0 || o.Words > maxWords:
+		return fmt.Errorf("%w: words out of range [1, %d]: %d", ErrInvalidOptions, maxWords, o.Words)
+	case o.Vocabulary < 0:
+		return fmt.Errorf("%w: negative vocabulary: %d", ErrInvalidOptions, o.Vocabulary)
+	case !domain.IsKnownLayout(o.Layout):
+		return fmt.Errorf("%w: unknown layout %q", ErrInvalidOptions, o.Layout)
+	}
+	if err := validateFocus(o.Focus); err != nil {
+		return err
+	}
+	return o.validateDensities()
+}
+
+// validateFocus checks that focus holds at most maxFocus lowercase
+// characters or bigrams.
+func validateFocus(focus []string) error {
+	if len(focus) > maxFocus {
+		return fmt.Errorf("%w: more than %d focus entries", ErrInvalidOptions, maxFocus)
+	}
+	for _, f := range focus {
+		if n := utf8.RuneCountInString(f); n < 1 || n > 2 || f != strings.ToLower(f) || strings.ContainsFunc(f, unicode.IsSpace) {
+			return fmt.Errorf("%w: focus entry %q is not a lowercase character or bigram", ErrInvalidOptions, f)
+		}
+	}
+	return nil
+}
+
+// validateDensities checks that the punctuation, number and capital shares
+// are in [0, 1], in that order so the error names the same one every time.
+func (o *Options) validateDensities() error {
+	densities := []struct {
+		name  string
+		value float64
+	}{{"punctuation", o.Punctuation}, {"numbers", o.Numbers}, {"capitals", o.Capitals}}
+	for _, d := range densities {
+		if math.IsNaN(d.value) || d.value < 0 || d.value > 1 {
+			return fmt.Errorf("%w: %s density out of range [0, 1]: %v", ErrInvalidOptions, d.name, d.value)
+		}
+	}
+	return nil
+}
+
+// wordPool returns the first vocabulary words of list made only of chars
+// (any word when chars is nil), or nil when fewer than minPoolWords qualify.
+func wordPool(list []string, chars []rune, vocabulary int) []string {
+	var pool []string
+	for _, w := range list {
+		if len(pool) == vocabulary {
+			break
+		}
+		if chars == nil || onlyLetters(w, chars) {
+			pool = append(pool, w)
+		}
+	}
+	if len(pool) < minPoolWords {
+		return nil
+	}
+	return pool
+}
+
+// onlyLetters reports whether every character of word is in chars.
+func onlyLetters(word string, chars []rune) bool {
+	for _, r := range word {
+		if !slices.Contains(chars, r) {
+			return false
+		}
+	}
+	return true
+}
+
+// pickWord draws a word from pool, or a letter group from alphabet when the
+// character set left no pool.
+func pickWord(rng *rand.Rand, pool []string, alphabet []rune) string {
+	if pool != nil {
+		return pool[rng.IntN(len(pool))]
+	}
+	return letterGroup(rng, "", alphabet)
+}
+
+// letterGroup returns random letters of alphabet around core.
+func letterGroup(rng *rand.Rand, core string, alphabet []rune) string {
+	n := max(0, minGroupLength+rng.IntN(maxGroupLength-minGroupLength+1)-utf8.RuneCountInString(core))
+	group := make([]rune, n)
+	for i := range group {
+		group[i] = alphabet[rng.IntN(len(alphabet))]
+	}
+	at := rng.IntN(n + 1)
+	return string(group[:at]) + core + string(group[at:])
+}
+
+// focusEntry is a focus character or bigram and the list words containing it.
+type focusEntry struct {
+	text  string
+	words []string // nil when no word fits: letter groups are drilled instead
+}
+
+// focusWords finds, for each focus entry, the most frequent words of list
+// that contain it and are made only of chars (any word when chars is nil).
+func focusWords(list []string, chars []rune, focus []string) []focusEntry {
+	const maxFocusWords = 50
+	entries := make([]focusEntry, 0, len(focus))
+	for _, f := range focus {
+		e := focusEntry{text: f}
+		for _, w := range list {
+			if len(e.words) == maxFocusWords {
+				break
+			}
+			if strings.Contains(w, f) && (chars == nil || onlyLetters(w, chars)) {
+				e.words = append(e.words, w)
+			}
+		}
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+// pickFocusWord draws a focus entry, every entry equally often, and one of
+// its words or a letter group around it.
+func pickFocusWord(rng *rand.Rand, focus []focusEntry, alphabet []rune) string {
+	e := focus[rng.IntN(len(focus))]
+	if e.words != nil {
+		return e.words[rng.IntN(len(e.words))]
+	}
+	return letterGroup(rng, e.text, alphabet)
+}
+
+// listLetters returns the distinct letters of list in order of appearance.
+func listLetters(list []string) []rune {
+	var letters []rune
+	for _, w := range list {
+		for _, r := range w {
+			if unicode.IsLetter(r) && !slices.Contains(letters, r) {
+				letters = append(letters, r)
+			}
+		}
+	}
+	return letters
+}
+
+// capitalize upper-cases the first letter of word.
+func capitalize(word string) string {
+	r, size := utf8.DecodeRuneInString(word)
+	return string(unicode.ToUpper(r)) + word[size:]
+}
+
+// title names a drill by its language, character set and seed.
+func title(o *Options) string {
+	set := "all letters"
+	if o.CharSet != CharSetAll {
+		set = string(o.CharSet) + " (" + o.Layout + ")"
+	}
+	if len(o.Focus) > 0 {
+		set += ", focus " + strings.Join(o.Focus, " ")
+	}
+	return fmt.Sprintf("Drill: %s, %s, seed %d", o.Language, set, o.Seed)
+}
@@ -0,0 +1,185 @@
+// Copyright 2025 Asher Buk
+// SPDX-License-Identifier: Apache-2.0
+// https://github.com/AshBuk/FingerGo
+
+package drill
+
+import (
+	"errors"
+	"math"
+	"slices"
+	"strings"
+	"testing"
+	"unicode"
+
+	domain "github.com/AshBuk/FingerGo/internal/domain"
+)
+
+func TestGenerate_Deterministic(t *testing.T) {
+	opts := Options{Seed: 42, Words: 30, Punctuation: 0.3, Numbers: 0.1, Capitals: 0.2}
+	a, err := Generate(opts)
+	if err != nil {
+		t.Fatalf("Generate() error: %v", err)
+	}
+	b, _ := Generate(a.Options)
+	if a.Text.Content != b.Text.Content || a.Text.Title != b.Text.Title {
+		t.Errorf("replay differs:\n%q\n%q", a.Text.Content, b.Text.Content)
+	}
+	if c, _ := Generate(Options{Seed: 43, Words: 30}); c.Text.Content == a.Text.Content {
+		t.Error("another seed generated the same drill")
+	}
+	if got := len(strings.Fields(a.Text.Content)); got != 30 {
+		t.Errorf("words = %d, want 30", got)
+	}
+	if a.Text.Language != "english" || a.Text.ID != "" {
+		t.Errorf("text = %+v, want an english text without ID", a.Text)
+	}
+
+	// Densities decorate the same words
+	plain, _ := Generate(Options{Seed: 42, Words: 30})
+	for i, w := range strings.Fields(plain.Text.Content) {
+		got := strings.ToLower(strings.Trim(strings.Fields(a.Text.Content)[i], `,.;:!?()"`))
+		if got != w && strings.Trim(got, "0123456789") != "" {
+			t.Errorf("word %d = %q, want %q decorated", i, got, w)
+		}
+	}
+
+	// A zero seed picks one and reports it
+	if d, err := Generate(Options{}); err != nil || d.Options.Seed <= 0 || d.Options.Words != defaultWords {
+		t.Errorf("Generate(zero options) = %+v, %v; want defaults and a seed", d.Options, err)
+	}
+	if TextID(a.Options) != TextID(b.Options) || TextID(a.Options) == TextID(plain.Options) {
+		t.Error("TextID does not follow the options")
+	}
+}
+
+func TestGenerate_Densities(t *testing.T) {
+	d, err := Generate(Options{Seed: 7, Words: 200, Punctuation: 1, Numbers: 0, Capitals: 1})
+	if err != nil {
+		t.Fatalf("Generate() error: %v", err)
+	}
+	for _, w := range strings.Fields(d.Text.Content) {
+		if !strings.ContainsAny(w, `,.;:!?()"`) {
+			t.Fatalf("word %q without punctuation at density 1", w)
+		}
+		if first := []rune(strings.TrimLeft(w, `("`))[0]; !unicode.IsUpper(first) {
+			t.Fatalf("word %q not capitalized at density 1", w)
+		}
+	}
+	d, _ = Generate(Options{Seed: 7, Words: 200, Numbers: 1})
+	if strings.Trim(strings.ReplaceAll(d.Text.Content, " ", ""), "0123456789") != "" {
+		t.Errorf("content = %q, want only numbers", d.Text.Content)
+	}
+}
+
+func TestGenerate_CharSets(t *testing.T) {
+	tests := []struct {
+		language, layout string
+		set              CharSet
+		allowed          string
+	}{
+		{"english", "en-qwerty", CharSetHomeRow, "asdfghjkl"},
+		{"english", "en-qwerty", CharSetLeftHand, "qwertasdfgzxcvb"},
+		{"english", "en-dvorak", CharSetHomeRow, "aoeuidhtns"},
+		{"german", "de-qwertz", CharSetRightHand, "zuiopühjklöänm"},
+		{"russian", "ru-jcuken", CharSetTopRow, "йцукенгшщзхъ"},
+	}
+	for _, tc := range tests {
+		t.Run(string(tc.set)+"/"+tc.layout, func(t *testing.T) {
+			d, err := Generate(Options{Seed: 1, Language: tc.language, Layout: tc.layout, CharSet: tc.set, Words: 100})
+			if err != nil {
+				t.Fatalf("Generate() error: %v", err)
+			}
+			for _, r := range strings.ReplaceAll(d.Text.Content, " ", "") {
+				if !strings.ContainsRune(tc.allowed, r) {
+					t.Fatalf("content %q has %q outside %s", d.Text.Content, r, tc.set)
+				}
+			}
+		})
+	}
+
+	// Enough words of the list qualify: the drill uses real words
+	d, _ := Generate(Options{Seed: 1, CharSet: CharSetLeftHand, Vocabulary: 200, Words: 50})
+	list := loadWordLists()["english"]
+	for _, w := range strings.Fields(d.Text.Content) {
+		if !slices.Contains(list, w) {
+			t.Errorf("left-hand drill word %q is not from the word list", w)
+		}
+	}
+}
+
+func TestGenerate_Focus(t *testing.T) {
+	count := func(opts Options, sub string) int {
+		t.Helper()
+		d, err := Generate(opts)
+		if err != nil {
+			t.Fatalf("Generate() error: %v", err)
+		}
+		return strings.Count(d.Text.Content, sub)
+	}
+	plain := Options{Seed: 5, Words: 300}
+	focused := plain
+	focused.Focus = []string{"w", "th"}
+	for _, f := range focused.Focus {
+		if p, got := count(plain, f), count(focused, f); got < 2*p || got < 50 {
+			t.Errorf("%q appears %d times with focus, %d without; want it over-represented", f, got, p)
+		}
+	}
+
+	// No word of the list has a "q": letter groups drill it instead
+	if got := count(Options{Seed: 5, Words: 100, Focus: []string{"q"}}, "q"); got < 30 {
+		t.Errorf("q appears %d times, want letter groups around it", got)
+	}
+}
+
+func TestGenerate_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+		want error
+	}{
+		{"unknown language", Options{Language: "klingon"}, ErrUnknownLanguage},
+		{"unknown layout", Options{Layout: "xx"}, ErrInvalidOptions},
+		{"unknown char set", Options{CharSet: "pinkies"}, ErrInvalidOptions},
+		{"too many words", Options{Words: maxWords + 1}, ErrInvalidOptions},
+		{"negative seed", Options{Seed: -1}, ErrInvalidOptions},
+		{"density above 1", Options{Punctuation: 1.5}, ErrInvalidOptions},
+		{"NaN density", Options{Capitals: math.NaN()}, ErrInvalidOptions},
+		{"uppercase focus", Options{Focus: []string{"A"}}, ErrInvalidOptions},
+		{"focus trigram", Options{Focus: []string{"the"}}, ErrInvalidOptions},
+		{"focus outside char set", Options{CharSet: CharSetHomeRow, Focus: []string{"q"}}, ErrInvalidOptions},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := Generate(tc.opts); !errors.Is(err, tc.want) {
+				t.Errorf("Generate() error = %v, want %v", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestGenerate_DensityErrorIsStable(t *testing.T) {
+	opts := Options{Punctuation: 2, Numbers: -1, Capitals: 3}
+	for range 20 {
+		if _, err := Generate(opts); err == nil || !strings.Contains(err.Error(), "punctuation density") {
+			t.Fatalf("Generate() error = %v, want the punctuation density named first", err)
+		}
+	}
+}
+
+func TestLanguages(t *testing.T) {
+	langs := Languages()
+	for _, want := range []string{"english", "german", "russian"} {
+		if !slices.Contains(langs, want) {
+			t.Errorf("Languages() = %v, missing %s", langs, want)
+		}
+	}
+	for _, lang := range langs {
+		if !domain.IsValidLanguage(lang) {
+			t.Errorf("word list %s is not a text language", lang)
+		}
+		if n := len(loadWordLists()[lang]); n < minPoolWords {
+			t.Errorf("%s word list has %d words", lang, n)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+// Copyright 2025 Asher Buk
+// SPDX-License-Identifier: Apache-2.0
+// https://github.com/AshBuk/FingerGo
+
+package drill
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	domain "github.com/AshBuk/FingerGo/internal/domain"
+)
+
+// Weakness analysis defaults and limits.
+const (
+	DefaultWeaknessSessions = 50 // recent sessions analyzed
+	defaultHalfLifeDays     = 7
+	defaultWeakKeys         = 5
+	defaultWeakBigrams      = 3
+)
+
+// WeaknessOptions describes a drill targeting the keys and bigrams mistyped
+// most in recent sessions.
+type WeaknessOptions struct {
+	Drill        Options `json:"drill"`        // language, layout, length and densities; Focus is filled in
+	HalfLifeDays float64 `json:"halfLifeDays"` // age at which a session's mistakes count half; default 7
+	Sessions     int     `json:"sessions"`     // recent sessions analyzed; default 50
+	Keys         int     `json:"keys"`         // weakest letters targeted; negative for the default 5, 0 for none
+	Bigrams      int     `json:"bigrams"`      // weakest letter bigrams targeted; negative for the default 3, 0 for none
+}
+
+// Weakness is a letter or bigram with its recency-weighted mistake count.
+type Weakness struct {
+	Text  string  `json:"text"`
+	Score float64 `json:"score"` // mistakes, each weighted by 0.5^(age/half-life)
+}
+
+// WeaknessDrill is a drill over-representing the weakest letters and bigrams.
+// Without mistakes to target it is a plain drill.
+type WeaknessDrill struct {
+	Drill
+	Keys    []Weakness `json:"keys"`    // targeted letters, weakest first
+	Bigrams []Weakness `json:"bigrams"` // targeted bigrams, weakest first
+}
+
+// Weaknesses aggregates the mistakes of sessions by letter and letter bigram
+// (case-folded), weighting each session by its age at now. Both lists are
+// sorted weakest first. halfLife must be positive.
+func Weaknesses(sessions []domain.TypingSession, now time.Time, halfLife time.Duration) (keys, bigrams []Weakness, err error) {
+	if halfLife <= 0 {
+		return nil, nil, fmt.Errorf("%w: half-life must be positive: %v", ErrInvalidOptions, halfLife)
+	}
+	keyScores := make(map[string]float64)
+	bigramScores := make(map[string]float64)
+	for i := range sessions {
+		s := &sessions[i]
+		age := max(0, now.Sub(s.CompletedAt))
+		weight := math.Pow(0.5, float64(age)/float64(halfLife))
+		for key, n := range s.Mistakes {
+			if k := strings.ToLower(key); isLetters(k, 1) {
+				keyScores[k] += float64(n) * weight
+			}
+		}
+		for bigram, n := range s.BigramMistakes {
+			if b := strings.ToLower(bigram); isLetters(b, 2) {
+				bigramScores[b] += float64(n) * weight
+			}
+		}
+	}
+	return rankWeaknesses(keyScores), rankWeaknesses(bigramScores), nil
+}
+
+// GenerateWeakness builds a drill focused on the weakest letters and bigrams
+// of sessions (newest first, as SessionStore.List returns them). Weaknesses
+// outside the drill's character set are left out.
+func GenerateWeakness(sessions []domain.TypingSession, now time.Time, opts WeaknessOptions) (WeaknessDrill, error) {
+	opts = opts.resolve()
+	if opts.HalfLifeDays < 0 || math.IsNaN(opts.HalfLifeDays) {
+		return WeaknessDrill{}, fmt.Errorf("%w: negative half-life: %v", ErrInvalidOptions, opts.HalfLifeDays)
+	}
+	if len(sessions) > opts.Sessions {
+		sessions = sessions[:opts.Sessions]
+	}
+	halfLife := time.Duration(opts.HalfLifeDays * float64(24*time.Hour))
+	keys, bigrams, err := Weaknesses(sessions, now, halfLife)
+	if err != nil {
+		return WeaknessDrill{}, err
+	}
+
+	layout := cmp.Or(opts.Drill.Layout, defaultLayout)
+	chars, ok := letters(opts.Drill.CharSet, layout)
+	if !ok {
+		return WeaknessDrill{}, fmt.Errorf("%w: character set %q on layout %q", ErrInvalidOptions, opts.Drill.CharSet, layout)
+	}
+	keys = typeable(keys, chars, opts.Keys)
+	bigrams = typeable(bigrams, chars, opts.Bigrams)
+
+	drillOpts := opts.Drill
+	drillOpts.Focus = nil
+	for _, w := range slices.Concat(keys, bigrams) {
+		drillOpts.Focus = append(drillOpts.Focus, w.Text)
+	}
+	d, err := Generate(drillOpts)
+	if err != nil {
+		return WeaknessDrill{}, err
+	}
+	return WeaknessDrill{Drill: d, Keys: keys, Bigrams: bigrams}, nil
+}
+
+// resolve fills in defaults. Keys and Bigrams of 0 stay: a drill may target
+// only letters or only bigrams.
+func (o WeaknessOptions) resolve() WeaknessOptions {
+	if o.HalfLifeDays == 0 {
+		o.HalfLifeDays = defaultHalfLifeDays
+	}
+	if o.Sessions <= 0 {
+		o.Sessions = DefaultWeaknessSessions
+	}
+	if o.Keys < 0 {
+		o.Keys = defaultWeakKeys
+	}
+	if o.Bigrams < 0 {
+		o.Bigrams = defaultWeakBigrams
+	}
+	return o
+}
+
+// isLetters reports whether s is n letters.
+func isLetters(s string, n int) bool {
+	count := 0
+	for _, r := range s {
+		if !unicode.IsLetter(r) {
+			return false
+		}
+		count++
+	}
+	return count == n
+}
+
+// rankWeaknesses sorts scores weakest first, ties by text.
+func rankWeaknesses(scores map[string]float64) []Weakness {
+	out := make([]Weakness, 0, len(scores))
+	for text, score := range scores {
+		if score > 0 {
+			out = append(out, Weakness{Text: text, Score: math.Round(score*100) / 100})
+		}
+	}
+	slices.SortFunc(out, func(a, b Weakness) int {
+		return cmp.Or(cmp.Compare(b.Score, a.Score), cmp.Compare(a.Text, b.Text))
+	})
+	return out
+}
+
+// typeable returns the first n weaknesses made only of chars (any, when
+// chars is nil).
+func typeable(weak []Weakness, chars []rune, n int) []Weakness {
+	var out []Weakness
+	for _, w := range weak {
+		if len(out) == n {
+			break
+		}
+		if chars == nil || onlyLetters(w.Text, chars) {
+			out = append(out, w)
+		}
+	}
+	return out
+}
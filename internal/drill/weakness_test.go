@@ -0,0 +1,75 @@
+// Copyright 2025 Asher Buk
+// SPDX-License-Identifier: Apache-2.0
+// https://github.com/AshBuk/FingerGo
+
+package drill
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	domain "github.com/AshBuk/FingerGo/internal/domain"
+)
+
+func TestWeaknesses(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	sessions := []domain.TypingSession{
+		{CompletedAt: now, Mistakes: map[string]int{"e": 2, "R": 1, "Enter": 9, " ": 5}, BigramMistakes: map[string]int{"er": 2, "e ": 4}},
+		{CompletedAt: now.Add(-7 * 24 * time.Hour), Mistakes: map[string]int{"r": 2, "x": 4}, BigramMistakes: map[string]int{"Th": 2}},
+	}
+	keys, bigrams, err := Weaknesses(sessions, now, 7*24*time.Hour)
+	if err != nil {
+		t.Fatalf("Weaknesses() error: %v", err)
+	}
+	// A week-old session counts half: r = 1 + 2/2, x = 4/2
+	want := []Weakness{{"e", 2}, {"r", 2}, {"x", 2}}
+	if !slices.Equal(keys, want) {
+		t.Errorf("keys = %v, want %v", keys, want)
+	}
+	if want := []Weakness{{"er", 2}, {"th", 1}}; !slices.Equal(bigrams, want) {
+		t.Errorf("bigrams = %v, want %v", bigrams, want)
+	}
+	if _, _, err := Weaknesses(sessions, now, 0); !errors.Is(err, ErrInvalidOptions) {
+		t.Errorf("Weaknesses(zero half-life): expected ErrInvalidOptions, got %v", err)
+	}
+}
+
+func TestGenerateWeakness(t *testing.T) {
+	now := time.Now()
+	sessions := []domain.TypingSession{
+		{CompletedAt: now, Mistakes: map[string]int{"k": 6, "p": 3, "a": 1}, BigramMistakes: map[string]int{"ou": 4}},
+	}
+	d, err := GenerateWeakness(sessions, now, WeaknessOptions{Drill: Options{Seed: 9, Words: 100}, Keys: 2, Bigrams: 1})
+	if err != nil {
+		t.Fatalf("GenerateWeakness() error: %v", err)
+	}
+	if want := []string{"k", "p", "ou"}; !slices.Equal(d.Options.Focus, want) {
+		t.Errorf("focus = %v, want %v", d.Options.Focus, want)
+	}
+	if replay, _ := Generate(d.Options); replay.Text.Content != d.Text.Content {
+		t.Error("the returned options do not replay the drill")
+	}
+
+	// Weaknesses outside the character set are not targeted
+	d, err = GenerateWeakness(sessions, now, WeaknessOptions{Drill: Options{Seed: 9, CharSet: CharSetHomeRow}, Keys: -1, Bigrams: -1})
+	if err != nil {
+		t.Fatalf("GenerateWeakness(home row) error: %v", err)
+	}
+	if want := []string{"k", "a"}; !slices.Equal(d.Options.Focus, want) {
+		t.Errorf("home row focus = %v, want %v", d.Options.Focus, want)
+	}
+
+	// Zero keys targets bigrams only
+	d, err = GenerateWeakness(sessions, now, WeaknessOptions{Drill: Options{Seed: 9}, Keys: 0, Bigrams: -1})
+	if err != nil || !slices.Equal(d.Options.Focus, []string{"ou"}) {
+		t.Errorf("bigram-only focus = %v, %v; want [ou]", d.Options.Focus, err)
+	}
+
+	// Without mistakes the drill is a plain one
+	d, err = GenerateWeakness(nil, now, WeaknessOptions{Drill: Options{Seed: 9}})
+	if err != nil || d.Options.Focus != nil || d.Text.Content == "" {
+		t.Errorf("GenerateWeakness(no sessions) = %+v, %v; want a plain drill", d, err)
+	}
+}
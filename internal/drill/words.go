@@ -0,0 +1,57 @@
+// Copyright 2025 Asher Buk
+// SPDX-License-Identifier: Apache-2.0
+// https://github.com/AshBuk/FingerGo
+
+package drill
+
+import (
+	"embed"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// wordFS holds one frequency list per language key (words/{language}.txt):
+// one lowercase word per line, most frequent first.
+//
+//go:embed words/*.txt
+var wordFS embed.FS
+
+var (
+	wordListsOnce sync.Once
+	wordLists     map[string][]string // language key → words, most frequent first
+)
+
+// loadWordLists reads the embedded lists once.
+func loadWordLists() map[string][]string {
+	wordListsOnce.Do(func() {
+		wordLists = make(map[string][]string)
+		entries, _ := wordFS.ReadDir("words") // embedded: cannot fail
+		for _, e := range entries {
+			data, err := wordFS.ReadFile(path.Join("words", e.Name()))
+			if err != nil {
+				continue
+			}
+			var words []string
+			for _, line := range strings.Split(string(data), "\n") {
+				if w := strings.TrimSpace(line); w != "" {
+					words = append(words, w)
+				}
+			}
+			wordLists[strings.TrimSuffix(e.Name(), ".txt")] = words
+		}
+	})
+	return wordLists
+}
+
+// Languages returns the language keys with an embedded word list, sorted.
+func Languages() []string {
+	lists := loadWordLists()
+	keys := make([]string, 0, len(lists))
+	for key := range lists {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,81 @@
+// Copyright 2025 Asher Buk
+// SPDX-License-Identifier: Apache-2.0
+// https://github.com/AshBuk/FingerGo
+
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	domain "github.com/AshBuk/FingerGo/internal/domain"
+	"github.com/AshBuk/FingerGo/internal/storage"
+)
+
+// ImportFiles imports each file in paths as a text in categoryID (empty for
+// the library root). The language comes from the file extension
+// (domain.LanguageForFile), the title from the file name. IDs are derived from
+// the absolute path, so importing a file twice skips it the second time.
+// Only an unknown category fails the whole call; per-file problems are
+// reported in the Report.
+func ImportFiles(store storage.TextStore, paths []string, categoryID string) (Report, error) {
+	var report Report
+	if err := checkCategory(store, categoryID); err != nil {
+		return report, err
+	}
+	now := time.Now().UTC()
+	for _, path := range paths {
+		report.add(importFile(store, path, categoryID, now))
+	}
+	return report, nil
+}
+
+// importFile reads, checks and saves a single file.
+func importFile(store storage.TextStore, path, categoryID string, now time.Time) FileResult {
+	res := FileResult{Path: path}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		res.Status, res.Reason = StatusRejected, err.Error()
+		return res
+	}
+	info, err := os.Stat(abs)
+	switch {
+	case err != nil:
+		res.Status, res.Reason = StatusRejected, err.Error()
+		return res
+	case info.IsDir():
+		res.Status, res.Reason = StatusSkipped, "is a directory"
+		return res
+	case info.Size() > 2*storage.MaxContentLength:
+		// CRLF files shrink when normalized; anything past twice the limit never fits
+		res.Status, res.Reason = StatusRejected, fmt.Sprintf("content exceeds %d bytes", storage.MaxContentLength)
+		return res
+	}
+	raw, err := os.ReadFile(abs)
+	if err != nil {
+		res.Status, res.Reason = StatusRejected, err.Error()
+		return res
+	}
+	content, err := decodeContent(raw)
+	if errors.Is(err, errEmpty) {
+		res.Status, res.Reason = StatusSkipped, err.Error()
+		return res
+	}
+	if err != nil {
+		res.Status, res.Reason = StatusRejected, err.Error()
+		return res
+	}
+	language := domain.LanguageForFile(abs)
+	text := &domain.Text{
+		ID:         textID(filepath.Base(abs), abs),
+		Title:      fileTitle(abs, language),
+		Content:    content,
+		CategoryID: categoryID,
+		Language:   language,
+		CreatedAt:  now,
+	}
+	return saveResult(store, path, text)
+}
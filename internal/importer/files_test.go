@@ -0,0 +1,174 @@
+// Copyright 2025 Asher Buk
+// SPDX-License-Identifier: Apache-2.0
+// https://github.com/AshBuk/FingerGo
+
+package importer
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	domain "github.com/AshBuk/FingerGo/internal/domain"
+	"github.com/AshBuk/FingerGo/internal/storage"
+)
+
+// setupStore creates a JSON text repository in a temporary data directory.
+func setupStore(t *testing.T) *storage.TextRepository {
+	t.Helper()
+	mgr, err := storage.New(t.TempDir())
+	if err != nil {
+		t.Fatalf("storage.New: %v", err)
+	}
+	if err := mgr.Init(); err != nil {
+		t.Fatalf("Init: %v", err)
+	}
+	t.Cleanup(func() { _ = mgr.Close() })
+	repo, err := storage.NewTextRepository(mgr)
+	if err != nil {
+		t.Fatalf("NewTextRepository: %v", err)
+	}
+	return repo
+}
+
+// writeFiles creates files (name → content) in a temp dir and returns their paths.
+func writeFiles(t *testing.T, files map[string]string) map[string]string {
+	t.Helper()
+	dir := t.TempDir()
+	paths := make(map[string]string, len(files))
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("MkdirAll: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+		paths[name] = path
+	}
+	return paths
+}
+
+func TestImportFiles(t *testing.T) {
+	store := setupStore(t)
+	paths := writeFiles(t, map[string]string{
+		"main.go":    "package main\r\n\r\nfunc main() {}\r\n",
+		"lib.rs":     "fn main() {}\n",
+		"notes.txt":  "\xef\xbb\xbfPlain notes\n",
+		"empty.py":   "  \n",
+		"binary.bin": "\x00\x01\x02",
+	})
+	report, err := ImportFiles(store, []string{
+		paths["main.go"], paths["lib.rs"], paths["notes.txt"], paths["empty.py"], paths["binary.bin"],
+		filepath.Dir(paths["main.go"]), filepath.Join(filepath.Dir(paths["main.go"]), "missing.go"),
+	}, "")
+	if err != nil {
+		t.Fatalf("ImportFiles: %v", err)
+	}
+	if report.Imported != 3 || report.Skipped != 2 || report.Rejected != 2 {
+		t.Fatalf("report counts = %d/%d/%d, want 3/2/2: %+v", report.Imported, report.Skipped, report.Rejected, report.Results)
+	}
+
+	want := map[string]struct{ title, language string }{
+		paths["main.go"]:   {"main.go", "go"},
+		paths["lib.rs"]:    {"lib.rs", "rust"},
+		paths["notes.txt"]: {"notes", "text"},
+	}
+	for _, res := range report.Results {
+		w, ok := want[res.Path]
+		if !ok {
+			continue
+		}
+		if res.Status != StatusImported || res.Title != w.title || res.Language != w.language {
+			t.Errorf("%s: got %+v, want imported %q (%s)", filepath.Base(res.Path), res, w.title, w.language)
+		}
+		text, err := store.Text(res.TextID)
+		if err != nil {
+			t.Fatalf("Text(%s): %v", res.TextID, err)
+		}
+		if strings.Contains(text.Content, "\r") || strings.HasPrefix(text.Content, "\xef\xbb\xbf") {
+			t.Errorf("%s: content not normalized: %q", res.TextID, text.Content)
+		}
+	}
+}
+
+func TestImportFiles_SecondImportIsSkipped(t *testing.T) {
+	store := setupStore(t)
+	paths := writeFiles(t, map[string]string{"a.go": "package a\n"})
+	if _, err := ImportFiles(store, []string{paths["a.go"]}, ""); err != nil {
+		t.Fatalf("ImportFiles: %v", err)
+	}
+	report, err := ImportFiles(store, []string{paths["a.go"]}, "")
+	if err != nil {
+		t.Fatalf("ImportFiles: %v", err)
+	}
+	if report.Skipped != 1 || report.Results[0].Reason != "already in library" {
+		t.Errorf("second import = %+v, want skipped", report.Results)
+	}
+}
+
+func TestImportFiles_Normalized(t *testing.T) {
+	store := setupStore(t)
+	store.SetNormalizer(storage.NewNormalizer("en-qwerty", nil))
+	paths := writeFiles(t, map[string]string{
+		"config.yml": "name: “demo”  \nlist:\n\t- ü\n",
+		"clean.go":   "package clean\n",
+	})
+	report, err := ImportFiles(store, []string{paths["config.yml"], paths["clean.go"]}, "")
+	if err != nil || report.Imported != 2 {
+		t.Fatalf("ImportFiles = %+v, %v", report, err)
+	}
+	yml, clean := report.Results[0], report.Results[1]
+	if clean.Normalized != nil {
+		t.Errorf("clean.go: Normalized = %+v, want nil", clean.Normalized)
+	}
+	if yml.Normalized == nil || len(yml.Normalized.Changes) != 2 || len(yml.Normalized.Untypeable) != 3 {
+		t.Fatalf("config.yml: Normalized = %+v, want tabs, trailing space, quotes and ü", yml.Normalized)
+	}
+	if text, _ := store.Text(yml.TextID); text.Content != "name: “demo”\nlist:\n  - ü\n" {
+		t.Errorf("config.yml content = %q", text.Content)
+	}
+}
+
+func TestImportFiles_Category(t *testing.T) {
+	store := setupStore(t)
+	paths := writeFiles(t, map[string]string{"a.go": "package a\n"})
+	if _, err := ImportFiles(store, []string{paths["a.go"]}, "missing"); !errors.Is(err, ErrCategoryNotFound) {
+		t.Errorf("unknown category: got %v, want ErrCategoryNotFound", err)
+	}
+	if err := store.SaveCategory(&domain.Category{ID: "code", Name: "Code"}); err != nil {
+		t.Fatalf("SaveCategory: %v", err)
+	}
+	report, err := ImportFiles(store, []string{paths["a.go"]}, "code")
+	if err != nil {
+		t.Fatalf("ImportFiles: %v", err)
+	}
+	text, _ := store.Text(report.Results[0].TextID)
+	if text.CategoryID != "code" {
+		t.Errorf("CategoryID = %q, want code", text.CategoryID)
+	}
+}
+
+func TestImportFiles_TooLarge(t *testing.T) {
+	store := setupStore(t)
+	paths := writeFiles(t, map[string]string{"big.txt": strings.Repeat("a", storage.MaxContentLength+1)})
+	report, _ := ImportFiles(store, []string{paths["big.txt"]}, "")
+	if report.Rejected != 1 {
+		t.Errorf("oversized file = %+v, want rejected", report.Results)
+	}
+}
+
+func TestTextID(t *testing.T) {
+	id := textID("Hello World!.go", "/src/hello.go")
+	if !strings.HasPrefix(id, "hello-world-go-") {
+		t.Errorf("textID = %q, want hello-world-go- prefix", id)
+	}
+	if id != textID("Hello World!.go", "/src/hello.go") {
+		t.Error("textID is not stable")
+	}
+	if textID("файл.txt", "/x") == "" || !strings.HasPrefix(textID("файл", "/x"), "text-") {
+		t.Errorf("non-ASCII name: got %q", textID("файл", "/x"))
+	}
+}
@@ -0,0 +1,234 @@
+// Copyright 2025 Asher Buk
+// SPDX-License-Identifier: Apache-2.0
+// https://github.com/AshBuk/FingerGo
+
+package importer
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	domain "github.com/AshBuk/FingerGo/internal/domain"
+	"github.com/AshBuk/FingerGo/internal/storage"
+)
+
+// GoSnippetOptions configures ImportGoSnippets.
+type GoSnippetOptions struct {
+	CategoryID    string `json:"categoryId"`    // category for the snippets (empty for the root)
+	StripComments bool   `json:"stripComments"` // drop doc and inline comments
+	MaxLines      int    `json:"maxLines"`      // longer snippets are skipped (0 = no limit)
+	MaxLength     int    `json:"maxLength"`     // longer snippets in bytes are skipped (0 = storage.MaxContentLength)
+}
+
+// goSnippet is one top-level declaration cut out of a Go file.
+type goSnippet struct {
+	title   string // symbol name: Func, Type.Method, Type
+	content string
+}
+
+// ImportGoSnippets splits Go source files into one text per top-level
+// function, method and type declaration, titled with the symbol name
+// (methods as Type.Method) and stored with language "go". Imports, constants
+// and variables are left out. With StripComments the declarations are
+// re-printed by go/printer without comments; otherwise the original source,
+// including the doc comment, is kept as written.
+//
+// IDs derive from the file path and the symbol, so importing a file again
+// updates the snippets that changed.
+func ImportGoSnippets(store storage.TextStore, paths []string, opts GoSnippetOptions) (Report, error) {
+	var report Report
+	if err := checkCategory(store, opts.CategoryID); err != nil {
+		return report, err
+	}
+	if opts.MaxLength <= 0 || opts.MaxLength > storage.MaxContentLength {
+		opts.MaxLength = storage.MaxContentLength
+	}
+	now := time.Now().UTC()
+	for _, path := range paths {
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			report.add(FileResult{Path: path, Status: StatusRejected, Reason: err.Error()})
+			continue
+		}
+		if filepath.Ext(abs) != ".go" {
+			report.add(FileResult{Path: path, Status: StatusRejected, Reason: "not a Go source file"})
+			continue
+		}
+		snippets, err := extractGoSnippets(abs, opts.StripComments)
+		if err != nil {
+			report.add(FileResult{Path: path, Status: StatusRejected, Reason: err.Error()})
+			continue
+		}
+		seen := make(map[string]int, len(snippets))
+		for _, snip := range snippets {
+			// Several init functions may share a title; keep their IDs apart
+			seen[snip.title]++
+			source := abs + "#" + snip.title
+			if n := seen[snip.title]; n > 1 {
+				source += "#" + strconv.Itoa(n)
+			}
+			if reason := snippetTooLong(snip.content, opts); reason != "" {
+				report.add(FileResult{Path: path, Status: StatusSkipped, Title: snip.title, Language: "go", Reason: reason})
+				continue
+			}
+			text := &domain.Text{
+				ID:         textID(snip.title, source),
+				Title:      truncateBytes(snip.title, storage.MaxTitleLength),
+				Content:    snip.content,
+				CategoryID: opts.CategoryID,
+				Language:   "go",
+				CreatedAt:  now,
+			}
+			report.add(upsertResult(store, path, text))
+		}
+	}
+	return report, nil
+}
+
+// snippetTooLong returns why content exceeds the option limits, or "".
+func snippetTooLong(content string, opts GoSnippetOptions) string {
+	if opts.MaxLines > 0 {
+		if lines := strings.Count(content, "\n") + 1; lines > opts.MaxLines {
+			return fmt.Sprintf("%d lines, limit is %d", lines, opts.MaxLines)
+		}
+	}
+	if len(content) > opts.MaxLength {
+		return fmt.Sprintf("%d bytes, limit is %d", len(content), opts.MaxLength)
+	}
+	return ""
+}
+
+// extractGoSnippets parses path and returns its functions, methods and types in source order.
+func extractGoSnippets(path string, stripComments bool) ([]goSnippet, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	content, err := decodeContent(raw)
+	if err != nil {
+		return nil, err
+	}
+	src := []byte(content)
+	mode := parser.ParseComments
+	if stripComments {
+		mode = 0 // without comments in the AST, go/printer has none to print
+	}
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, path, src, mode)
+	if err != nil {
+		return nil, fmt.Errorf("parse Go source: %w", err)
+	}
+	var snippets []goSnippet
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			content, err := declSource(fset, src, d, d.Doc, stripComments)
+			if err != nil {
+				return nil, err
+			}
+			snippets = append(snippets, goSnippet{title: funcTitle(d), content: content})
+		case *ast.GenDecl:
+			if d.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range d.Specs {
+				ts, ok := spec.(*ast.TypeSpec)
+				if !ok {
+					continue
+				}
+				content, err := typeSource(fset, src, d, ts, stripComments)
+				if err != nil {
+					return nil, err
+				}
+				snippets = append(snippets, goSnippet{title: ts.Name.Name, content: content})
+			}
+		}
+	}
+	return snippets, nil
+}
+
+// funcTitle names a function "Name" and a method "Type.Name".
+func funcTitle(d *ast.FuncDecl) string {
+	if d.Recv == nil || len(d.Recv.List) == 0 {
+		return d.Name.Name
+	}
+	return receiverType(d.Recv.List[0].Type) + "." + d.Name.Name
+}
+
+// receiverType strips pointers and type parameters from a receiver type.
+func receiverType(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return receiverType(t.X)
+	case *ast.IndexExpr:
+		return receiverType(t.X)
+	case *ast.IndexListExpr:
+		return receiverType(t.X)
+	case *ast.Ident:
+		return t.Name
+	}
+	return "?"
+}
+
+// declSource returns the text of node: the original bytes (with its doc
+// comment) or, when stripping comments, the node re-printed without them.
+func declSource(fset *token.FileSet, src []byte, node ast.Node, doc *ast.CommentGroup, strip bool) (string, error) {
+	if strip {
+		var buf bytes.Buffer
+		if err := printer.Fprint(&buf, fset, node); err != nil {
+			return "", fmt.Errorf("print declaration: %w", err)
+		}
+		return dropBlankAfterBrace(buf.String()), nil
+	}
+	start := node.Pos()
+	if doc != nil {
+		start = doc.Pos()
+	}
+	return string(src[fset.Position(start).Offset:fset.Position(node.End()).Offset]), nil
+}
+
+// typeSource returns one type declaration; specs of a grouped
+// "type ( ... )" block become standalone "type X ..." declarations.
+func typeSource(fset *token.FileSet, src []byte, d *ast.GenDecl, ts *ast.TypeSpec, strip bool) (string, error) {
+	if !d.Lparen.IsValid() {
+		return declSource(fset, src, d, d.Doc, strip)
+	}
+	if strip {
+		single := &ast.GenDecl{TokPos: ts.Pos(), Tok: token.TYPE, Specs: []ast.Spec{ts}}
+		return declSource(fset, src, single, nil, true)
+	}
+	body, err := declSource(fset, src, ts, nil, false)
+	if err != nil {
+		return "", err
+	}
+	out := "type " + body
+	if ts.Doc != nil {
+		doc := string(src[fset.Position(ts.Doc.Pos()).Offset:fset.Position(ts.Doc.End()).Offset])
+		out = doc + "\n" + out
+	}
+	// Lines after the first carry the block's indentation
+	return strings.ReplaceAll(out, "\n\t", "\n"), nil
+}
+
+// dropBlankAfterBrace removes blank lines that open a block. go/printer keeps
+// the line breaks of stripped comments, which leaves such lines behind.
+func dropBlankAfterBrace(s string) string {
+	lines := strings.Split(s, "\n")
+	out := lines[:1]
+	for _, line := range lines[1:] {
+		if strings.TrimSpace(line) == "" && strings.HasSuffix(out[len(out)-1], "{") {
+			continue
+		}
+		out = append(out, line)
+	}
+	return strings.Join(out, "\n")
+}
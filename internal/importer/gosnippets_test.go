@@ -0,0 +1,125 @@
+// Copyright 2025 Asher Buk
+// SPDX-License-Identifier: Apache-2.0
+// https://github.com/AshBuk/FingerGo
+
+package importer
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+const goSample = `package sample
+
+import "fmt"
+
+const answer = 42
+
+// Greeter says hello.
+type Greeter struct {
+	Name string // who to greet
+}
+
+type (
+	// ID identifies things.
+	ID string
+
+	Pair[T any] struct {
+		A, B T
+	}
+)
+
+// Hello returns a greeting.
+func (g *Greeter) Hello() string {
+	// build the message
+	return fmt.Sprintf("hello %s", g.Name)
+}
+
+func (p Pair[T]) Swap() Pair[T] { return Pair[T]{p.B, p.A} }
+
+func init() {}
+
+func init() {}
+
+func Long() {
+	a := 1
+	b := 2
+	c := 3
+	_ = a + b + c
+}
+`
+
+func TestImportGoSnippets(t *testing.T) {
+	store := setupStore(t)
+	paths := writeFiles(t, map[string]string{"sample.go": goSample, "notes.txt": "x"})
+	report, err := ImportGoSnippets(store, []string{paths["sample.go"], paths["notes.txt"]}, GoSnippetOptions{MaxLines: 5})
+	if err != nil {
+		t.Fatalf("ImportGoSnippets: %v", err)
+	}
+
+	got := make(map[string]FileResult)
+	for _, res := range report.Results {
+		got[res.Title] = res
+	}
+	for _, title := range []string{"Greeter", "ID", "Pair", "Greeter.Hello", "Pair.Swap"} {
+		if got[title].Status != StatusImported {
+			t.Errorf("%s: %+v, want imported", title, got[title])
+		}
+	}
+	if report.Imported != 7 { // the five above plus both init functions
+		t.Errorf("imported %d snippets, want 7: %+v", report.Imported, report.Results)
+	}
+	if got["Long"].Status != StatusSkipped {
+		t.Errorf("Long (6 lines, limit 5): %+v, want skipped", got["Long"])
+	}
+	if report.Rejected != 1 {
+		t.Errorf("non-Go file: rejected = %d, want 1", report.Rejected)
+	}
+
+	hello, _ := store.Text(got["Greeter.Hello"].TextID)
+	if hello.Language != "go" || !strings.HasPrefix(hello.Content, "// Hello returns a greeting.\nfunc (g *Greeter) Hello()") {
+		t.Errorf("Greeter.Hello = %q (%s)", hello.Content, hello.Language)
+	}
+	id, _ := store.Text(got["ID"].TextID)
+	if id.Content != "// ID identifies things.\ntype ID string" {
+		t.Errorf("grouped type = %q", id.Content)
+	}
+}
+
+func TestImportGoSnippets_StripComments(t *testing.T) {
+	store := setupStore(t)
+	paths := writeFiles(t, map[string]string{"sample.go": goSample})
+	report, err := ImportGoSnippets(store, []string{paths["sample.go"]}, GoSnippetOptions{StripComments: true})
+	if err != nil {
+		t.Fatalf("ImportGoSnippets: %v", err)
+	}
+	for _, res := range report.Results {
+		text, err := store.Text(res.TextID)
+		if err != nil {
+			t.Fatalf("Text(%s): %v", res.Title, err)
+		}
+		if strings.Contains(text.Content, "//") || strings.Contains(text.Content, "\n\n") {
+			t.Errorf("%s still has comments: %q", res.Title, text.Content)
+		}
+	}
+}
+
+func TestImportGoSnippets_ReimportUpdates(t *testing.T) {
+	store := setupStore(t)
+	paths := writeFiles(t, map[string]string{"sample.go": goSample})
+	if _, err := ImportGoSnippets(store, []string{paths["sample.go"]}, GoSnippetOptions{}); err != nil {
+		t.Fatalf("ImportGoSnippets: %v", err)
+	}
+	edited := strings.Replace(goSample, `"hello %s"`, `"hi %s"`, 1)
+	if err := os.WriteFile(paths["sample.go"], []byte(edited), 0o600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	report, err := ImportGoSnippets(store, []string{paths["sample.go"]}, GoSnippetOptions{})
+	if err != nil {
+		t.Fatalf("ImportGoSnippets: %v", err)
+	}
+	if report.Imported != 0 || report.Updated != 1 {
+		t.Errorf("re-import: imported %d, updated %d; want 0, 1", report.Imported, report.Updated)
+	}
+}
@@ -0,0 +1,246 @@
+// Copyright 2025 Asher Buk
+// SPDX-License-Identifier: Apache-2.0
+// https://github.com/AshBuk/FingerGo
+
+// Package importer turns local files into library texts.
+//
+// Every importer builds domain.Text values and stores them through
+// storage.TextStore.SaveText, so imported texts pass the same normalization
+// and validation as texts created in the GUI. Results are reported per file.
+package importer
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	domain "github.com/AshBuk/FingerGo/internal/domain"
+	"github.com/AshBuk/FingerGo/internal/storage"
+)
+
+// Status is the outcome of importing one file.
+type Status string
+
+// Import outcomes.
+const (
+	StatusImported Status = "imported" // new text saved
+	StatusUpdated  Status = "updated"  // text from an earlier import replaced with changed content
+	StatusSkipped  Status = "skipped"  // nothing to import (empty, already in library, ...)
+	StatusRejected Status = "rejected" // file cannot become a text (too large, binary, invalid)
+)
+
+// Import errors.
+var (
+	ErrCategoryNotFound = errors.New("importer: category not found")
+	errBinary           = errors.New("binary or not UTF-8 text")
+	errEmpty            = errors.New("empty file")
+)
+
+// Name limits.
+const (
+	maxSlugLength   = 48  // readable part of generated text IDs
+	maxCategoryName = 100 // storage rejects longer category names
+)
+
+// FileResult reports what happened to one input file.
+type FileResult struct {
+	Normalized *storage.NormalizeReport `json:"normalized,omitempty"` // what normalization changed or warns about
+	Path       string                   `json:"path"`
+	Status     Status                   `json:"status"`
+	TextID     string                   `json:"textId,omitempty"`
+	Title      string                   `json:"title,omitempty"`
+	Language   string                   `json:"language,omitempty"`
+	Reason     string                   `json:"reason,omitempty"` // why the file was skipped or rejected
+}
+
+// Report summarizes an import run.
+type Report struct {
+	Results    []FileResult `json:"results"`
+	Imported   int          `json:"imported"`
+	Updated    int          `json:"updated"`
+	Skipped    int          `json:"skipped"`
+	Rejected   int          `json:"rejected"`
+	Categories int          `json:"categories"`          // categories created
+	Truncated  bool         `json:"truncated,omitempty"` // a size or file-count limit stopped the import
+}
+
+// add records a result and updates the counters.
+func (r *Report) add(res FileResult) {
+	switch res.Status {
+	case StatusImported:
+		r.Imported++
+	case StatusUpdated:
+		r.Updated++
+	case StatusSkipped:
+		r.Skipped++
+	case StatusRejected:
+		r.Rejected++
+	}
+	r.Results = append(r.Results, res)
+}
+
+// checkCategory verifies that categoryID (if set) exists in the library.
+func checkCategory(store storage.TextStore, categoryID string) error {
+	if categoryID == "" {
+		return nil
+	}
+	lib, err := store.Library()
+	if err != nil {
+		return err
+	}
+	for _, c := range lib.Categories {
+		if c.ID == categoryID {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrCategoryNotFound, categoryID)
+}
+
+// decodeContent turns raw file bytes into text content: a UTF-8 BOM is
+// dropped and line endings become "\n". Binary data and empty files fail.
+func decodeContent(raw []byte) (string, error) {
+	raw = bytes.TrimPrefix(raw, []byte("\xef\xbb\xbf"))
+	if bytes.IndexByte(raw, 0) >= 0 || !utf8.Valid(raw) {
+		return "", errBinary
+	}
+	// Only line endings here: parsing needs LF, the store applies the other rules
+	content, _ := storage.NormalizeContent(string(raw), storage.NormalizeRules{LineEndings: true})
+	if strings.TrimSpace(content) == "" {
+		return "", errEmpty
+	}
+	if len(content) > storage.MaxContentLength {
+		return "", fmt.Errorf("content exceeds %d bytes", storage.MaxContentLength)
+	}
+	return content, nil
+}
+
+// fileTitle derives a text title from a file name: plain-text files lose
+// their extension, code files keep it (main.go, lib.rs).
+func fileTitle(name, language string) string {
+	base := filepath.Base(name)
+	if language == "text" {
+		if trimmed := strings.TrimSuffix(base, filepath.Ext(base)); trimmed != "" {
+			base = trimmed
+		}
+	}
+	return truncateBytes(base, storage.MaxTitleLength)
+}
+
+// truncateBytes shortens s to at most limit bytes without splitting a rune.
+func truncateBytes(s string, limit int) string {
+	if len(s) <= limit {
+		return s
+	}
+	for limit > 0 && !utf8.RuneStart(s[limit]) {
+		limit--
+	}
+	return s[:limit]
+}
+
+// textID builds a stable, filesystem-safe ID: a slug of name plus a hash of
+// source, so importing the same source again yields the same ID.
+func textID(name, source string) string {
+	sum := sha256.Sum256([]byte(source))
+	return slug(name) + "-" + hex.EncodeToString(sum[:5])
+}
+
+// slug lowercases s and keeps ASCII letters and digits, joining runs of
+// anything else with single hyphens. Never empty.
+func slug(s string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(s) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+		} else {
+			dash = true
+		}
+		if b.Len() >= maxSlugLength {
+			break
+		}
+	}
+	if b.Len() == 0 {
+		return "text"
+	}
+	return b.String()
+}
+
+// uniqueCategoryName returns the first candidate not in names; when all are
+// taken, the last candidate with the hash suffix of id appended.
+func uniqueCategoryName(names map[string]bool, id string, candidates ...string) string {
+	for _, c := range candidates {
+		c = truncateBytes(c, maxCategoryName)
+		if !names[c] {
+			return c
+		}
+	}
+	suffix := " (" + id[strings.LastIndexByte(id, '-')+1:] + ")"
+	return truncateBytes(candidates[len(candidates)-1], maxCategoryName-len(suffix)) + suffix
+}
+
+// normalize applies the normalization of store to text, returning the report
+// for a FileResult.
+func normalize(store storage.TextStore, text *domain.Text) *storage.NormalizeReport {
+	return resultReport(store.Normalizer().Normalize(text))
+}
+
+// resultReport returns report for a FileResult (nil when there is nothing to tell).
+func resultReport(report storage.NormalizeReport) *storage.NormalizeReport {
+	if report.Empty() {
+		return nil
+	}
+	return &report
+}
+
+// saveResult stores text and converts the outcome into a FileResult.
+func saveResult(store storage.TextStore, path string, text *domain.Text) FileResult {
+	res := FileResult{Path: path, TextID: text.ID, Title: text.Title, Language: text.Language}
+	report, err := store.SaveTextWithReport(text)
+	switch {
+	case err == nil:
+		res.Status, res.Normalized = StatusImported, resultReport(report)
+	case errors.Is(err, storage.ErrTextExists):
+		res.Status, res.Reason = StatusSkipped, "already in library"
+	default:
+		res.Status, res.Reason = StatusRejected, err.Error()
+	}
+	return res
+}
+
+// upsertResult saves text, or updates the text an earlier import stored under
+// the same ID when its content, title, language or category changed.
+func upsertResult(store storage.TextStore, path string, text *domain.Text) FileResult {
+	existing, err := store.Text(text.ID)
+	switch {
+	case errors.Is(err, storage.ErrTextNotFound):
+		return saveResult(store, path, text)
+	case err != nil:
+		return FileResult{Path: path, Status: StatusRejected, Reason: err.Error()}
+	}
+	res := FileResult{Path: path, TextID: text.ID, Title: text.Title, Language: text.Language}
+	// Compare normalized content, or a file with trailing spaces would update every time
+	res.Normalized = normalize(store, text)
+	if existing.Content == text.Content && existing.Title == text.Title &&
+		existing.Language == text.Language && existing.CategoryID == text.CategoryID {
+		res.Status, res.Reason = StatusSkipped, "unchanged"
+		return res
+	}
+	// Keep what the user set on the earlier import (favourite, creation date)
+	existing.Content, existing.Title = text.Content, text.Title
+	existing.Language, existing.CategoryID = text.Language, text.CategoryID
+	if err := store.UpdateText(&existing); err != nil {
+		res.Status, res.Reason = StatusRejected, err.Error()
+		return res
+	}
+	res.Status = StatusUpdated
+	return res
+}
@@ -0,0 +1,365 @@
+// Copyright 2025 Asher Buk
+// SPDX-License-Identifier: Apache-2.0
+// https://github.com/AshBuk/FingerGo
+
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	domain "github.com/AshBuk/FingerGo/internal/domain"
+	"github.com/AshBuk/FingerGo/internal/storage"
+)
+
+// minProseWords is the shortest paragraph imported as prose; shorter ones
+// (captions, "See below:") are not worth a text of their own.
+const minProseWords = 5
+
+// markdownExtensions are the file extensions ImportMarkdown accepts.
+var markdownExtensions = map[string]bool{".md": true, ".markdown": true, ".mdown": true, ".mkd": true}
+
+var (
+	atxHeadingRe    = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+	setextRe        = regexp.MustCompile(`^ {0,3}(?:=+|-+)[ \t]*$`)
+	fenceRe         = regexp.MustCompile("^( {0,3})(`{3,}|~{3,})(.*)$")
+	thematicBreakRe = regexp.MustCompile(`^ {0,3}(?:(?:\*[ \t]*){3,}|(?:-[ \t]*){3,}|(?:_[ \t]*){3,})$`)
+	listMarkerRe    = regexp.MustCompile(`^ {0,3}(?:[-*+]|\d{1,9}[.)])[ \t]+`)
+	linkRefDefRe    = regexp.MustCompile(`^ {0,3}\[[^\]]+\]:`)
+	imageRe         = regexp.MustCompile(`!\[([^\]]*)\]\([^)]*\)`)
+	linkRe          = regexp.MustCompile(`\[([^\]]+)\](?:\([^)]*\)|\[[^\]]*\])`)
+	emphasisRe      = regexp.MustCompile(`(\*\*|\*|~~)(\S(?:.*?\S)?)(\*\*|\*|~~)`)
+	underscoreRe    = regexp.MustCompile(`(^|[^\pL\pN_])(__?)(\S(?:.*?\S)?)(__?)([^\pL\pN_]|$)`)
+)
+
+// MarkdownOptions configures ImportMarkdown.
+type MarkdownOptions struct {
+	ParentID     string `json:"parentId"`     // category to create the document categories under (empty for the root)
+	IncludeProse bool   `json:"includeProse"` // also import paragraphs as "english" texts
+}
+
+// mdBlock is a code block or paragraph extracted from a Markdown document.
+type mdBlock struct {
+	heading  string // nearest heading above the block ("" before the first one)
+	language string
+	content  string
+	prose    bool
+}
+
+// ImportMarkdown extracts the fenced code blocks of Markdown files (READMEs,
+// runbooks) as texts. The fence info string selects the language
+// (domain.LanguageForAlias, "text" when unknown) and the nearest heading above
+// a block becomes its title. With IncludeProse, paragraphs and list items are
+// imported as "english" texts with inline markup removed.
+//
+// The texts of each document go into a category named after the file,
+// created under opts.ParentID on first use. IDs derive from the file path and
+// the title, so importing a document again updates blocks that changed.
+func ImportMarkdown(store storage.TextStore, paths []string, opts MarkdownOptions) (Report, error) {
+	var report Report
+	if err := checkCategory(store, opts.ParentID); err != nil {
+		return report, err
+	}
+	lib, err := store.Library()
+	if err != nil {
+		return report, err
+	}
+	im := &mdImporter{
+		store:      store,
+		report:     &report,
+		now:        time.Now().UTC(),
+		categories: make(map[string]bool, len(lib.Categories)),
+		names:      make(map[string]bool, len(lib.Categories)),
+		opts:       opts,
+	}
+	for _, c := range lib.Categories {
+		im.categories[c.ID] = true
+		im.names[c.Name] = true
+	}
+	for _, path := range paths {
+		im.importDocument(path)
+	}
+	return report, nil
+}
+
+// mdImporter carries the state of one ImportMarkdown run.
+type mdImporter struct {
+	store      storage.TextStore
+	report     *Report
+	now        time.Time
+	categories map[string]bool // existing category IDs
+	names      map[string]bool // existing category names (must stay unique)
+	opts       MarkdownOptions
+}
+
+// importDocument imports the blocks of one Markdown file into its category.
+func (im *mdImporter) importDocument(path string) {
+	abs, doc, res, ok := readMarkdown(path)
+	if !ok {
+		im.report.add(res)
+		return
+	}
+	blocks := parseMarkdown(doc, im.opts.IncludeProse)
+	if len(blocks) == 0 {
+		im.report.add(FileResult{Path: path, Status: StatusSkipped, Reason: "no code blocks to import"})
+		return
+	}
+	name := fileTitle(abs, "text")
+	categoryID, err := im.documentCategory(abs, name)
+	if err != nil {
+		im.report.add(FileResult{Path: path, Status: StatusRejected, Reason: err.Error()})
+		return
+	}
+	seen := make(map[string]int, len(blocks))
+	for _, block := range blocks {
+		im.report.add(im.importBlock(path, abs, categoryID, blockTitle(block, name, seen), block))
+	}
+}
+
+// readMarkdown resolves and decodes a Markdown file. When ok is false, res
+// says why the file was rejected or skipped.
+func readMarkdown(path string) (abs, doc string, res FileResult, ok bool) {
+	res = FileResult{Path: path, Status: StatusRejected}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		res.Reason = err.Error()
+		return "", "", res, false
+	}
+	if !markdownExtensions[strings.ToLower(filepath.Ext(abs))] {
+		res.Reason = "not a Markdown file"
+		return "", "", res, false
+	}
+	raw, err := os.ReadFile(abs)
+	if err != nil {
+		res.Reason = err.Error()
+		return "", "", res, false
+	}
+	if doc, err = decodeContent(raw); err != nil {
+		if errors.Is(err, errEmpty) {
+			res.Status = StatusSkipped
+		}
+		res.Reason = err.Error()
+		return "", "", res, false
+	}
+	return abs, doc, res, true
+}
+
+// documentCategory returns the category named after the document, creating
+// it under opts.ParentID on first use.
+func (im *mdImporter) documentCategory(abs, name string) (string, error) {
+	cat := &domain.Category{ID: textID(name, abs), ParentID: im.opts.ParentID}
+	if im.categories[cat.ID] {
+		return cat.ID, nil
+	}
+	cat.Name = uniqueCategoryName(im.names, cat.ID, name, filepath.Base(filepath.Dir(abs))+"/"+name)
+	if err := im.store.SaveCategory(cat); err != nil {
+		return "", fmt.Errorf("create category: %w", err)
+	}
+	im.categories[cat.ID] = true
+	im.names[cat.Name] = true
+	im.report.Categories++
+	return cat.ID, nil
+}
+
+// blockTitle names a block after its heading, or the document name before
+// the first one. Several blocks under one heading become "Install",
+// "Install (2)", ...; code and prose are counted apart in seen so
+// IncludeProse does not renumber code titles.
+func blockTitle(block mdBlock, name string, seen map[string]int) string {
+	title := block.heading
+	if title == "" {
+		title = name
+	}
+	key := blockKind(block) + title
+	seen[key]++
+	if n := seen[key]; n > 1 {
+		title += " (" + strconv.Itoa(n) + ")"
+	}
+	return title
+}
+
+// blockKind separates code and prose blocks in titles and IDs.
+func blockKind(block mdBlock) string {
+	if block.prose {
+		return "#prose#"
+	}
+	return "#code#"
+}
+
+// importBlock creates or updates the text for one block.
+func (im *mdImporter) importBlock(path, abs, categoryID, title string, block mdBlock) FileResult {
+	if len(block.content) > storage.MaxContentLength {
+		return FileResult{Path: path, Status: StatusSkipped, Title: title, Language: block.language,
+			Reason: fmt.Sprintf("content exceeds %d bytes", storage.MaxContentLength)}
+	}
+	text := &domain.Text{
+		ID:         textID(title, abs+blockKind(block)+title),
+		Title:      truncateBytes(title, storage.MaxTitleLength),
+		Content:    block.content,
+		CategoryID: categoryID,
+		Language:   block.language,
+		CreatedAt:  im.now,
+	}
+	return upsertResult(im.store, path, text)
+}
+
+// parseMarkdown returns the fenced code blocks of doc in order, and with
+// prose also its paragraphs. It follows CommonMark for headings and fences;
+// indented code blocks, tables and HTML are ignored.
+func parseMarkdown(doc string, prose bool) []mdBlock {
+	p := &mdParser{prose: prose}
+	lines := strings.Split(doc, "\n")
+	for i := 0; i < len(lines); i++ {
+		if m := openingFence(lines[i]); m != nil {
+			i = p.fence(lines, i, m)
+			continue
+		}
+		p.line(lines[i])
+	}
+	p.flush()
+	return p.blocks
+}
+
+// mdParser carries the state of one parseMarkdown run.
+type mdParser struct {
+	blocks    []mdBlock
+	heading   string   // nearest heading so far
+	paragraph []string // lines of the paragraph being read
+	prose     bool     // collect paragraphs as well as code
+}
+
+// flush ends the current paragraph, keeping it as a prose block when
+// paragraphs are collected and it is long enough.
+func (p *mdParser) flush() {
+	if p.prose && len(p.paragraph) > 0 {
+		text := strings.Join(p.paragraph, " ")
+		if len(strings.Fields(text)) >= minProseWords {
+			p.blocks = append(p.blocks, mdBlock{heading: p.heading, language: "english", content: text, prose: true})
+		}
+	}
+	p.paragraph = nil
+}
+
+// openingFence returns the fenceRe match when line opens a fenced code
+// block; a backtick fence may not have backticks in its info string.
+func openingFence(line string) []string {
+	m := fenceRe.FindStringSubmatch(line)
+	if m == nil || (m[2][0] == '`' && strings.Contains(m[3], "`")) {
+		return nil
+	}
+	return m
+}
+
+// fence reads the code block opened at lines[i] (matched as m) and returns
+// the index of its closing fence, or the last line when it is never closed.
+func (p *mdParser) fence(lines []string, i int, m []string) int {
+	p.flush()
+	var body []string
+	for i++; i < len(lines); i++ {
+		if isClosingFence(lines[i], m[2]) {
+			break
+		}
+		body = append(body, trimIndent(lines[i], len(m[1])))
+	}
+	content := strings.Trim(strings.Join(body, "\n"), "\n")
+	if strings.TrimSpace(content) != "" {
+		p.blocks = append(p.blocks, mdBlock{heading: p.heading, language: fenceLanguage(m[3]), content: content})
+	}
+	return i
+}
+
+// line handles a line outside code blocks: headings, breaks and prose.
+func (p *mdParser) line(line string) {
+	trimmed := strings.TrimSpace(line)
+	switch {
+	case trimmed == "":
+		p.flush()
+	case len(p.paragraph) > 0 && setextRe.MatchString(line):
+		p.heading = plainInline(strings.Join(p.paragraph, " "))
+		p.paragraph = nil
+	case atxHeadingRe.MatchString(line):
+		p.flush()
+		p.heading = plainInline(atxHeadingRe.FindStringSubmatch(line)[2])
+	case thematicBreakRe.MatchString(line):
+		p.flush()
+	case strings.HasPrefix(trimmed, "|"), strings.HasPrefix(trimmed, "<"), linkRefDefRe.MatchString(line):
+		p.flush()
+	case listMarkerRe.MatchString(line):
+		// Every list item is a paragraph of its own
+		p.flush()
+		p.paragraph = append(p.paragraph, plainInline(listMarkerRe.ReplaceAllString(line, "")))
+	case strings.HasPrefix(line, "    ") || strings.HasPrefix(line, "\t"):
+		if len(p.paragraph) > 0 {
+			p.paragraph = append(p.paragraph, plainInline(trimmed)) // lazy continuation line
+		}
+	default:
+		p.paragraph = append(p.paragraph, plainInline(strings.TrimLeft(trimmed, "> ")))
+	}
+}
+
+// isClosingFence reports whether line closes a fence opened with open:
+// the same character, at least as long, nothing but spaces after it.
+func isClosingFence(line, open string) bool {
+	trimmed := strings.TrimLeft(line, " ")
+	if len(line)-len(trimmed) > 3 {
+		return false
+	}
+	run := len(trimmed) - len(strings.TrimLeft(trimmed, open[:1]))
+	return run >= len(open) && strings.TrimSpace(trimmed[run:]) == ""
+}
+
+// trimIndent removes up to n leading spaces, the indentation of the opening fence.
+func trimIndent(line string, n int) string {
+	for i := 0; i < n && strings.HasPrefix(line, " "); i++ {
+		line = line[1:]
+	}
+	return line
+}
+
+// fenceLanguage maps a fence info string ("go", "bash title=x", "{.python}")
+// to a language key, "text" when it names no supported language.
+func fenceLanguage(info string) string {
+	fields := strings.Fields(info)
+	if len(fields) == 0 {
+		return "text"
+	}
+	name := strings.Trim(fields[0], "{}.")
+	name, _, _ = strings.Cut(name, ",") // "rust,ignore"
+	if key, ok := domain.LanguageForAlias(name); ok {
+		return key
+	}
+	return "text"
+}
+
+// plainInline strips inline Markdown: images and links keep their text,
+// emphasis markers and code backticks are dropped. Underscores inside words
+// (snake_case) are left alone.
+func plainInline(s string) string {
+	s = imageRe.ReplaceAllString(s, "$1")
+	s = linkRe.ReplaceAllString(s, "$1")
+	s = strings.ReplaceAll(s, "`", "")
+	for {
+		next := emphasisRe.ReplaceAllStringFunc(s, func(m string) string {
+			if sub := emphasisRe.FindStringSubmatch(m); sub[1] == sub[3] {
+				return sub[2]
+			}
+			return m
+		})
+		next = underscoreRe.ReplaceAllStringFunc(next, func(m string) string {
+			if sub := underscoreRe.FindStringSubmatch(m); sub[2] == sub[4] {
+				return sub[1] + sub[3] + sub[5]
+			}
+			return m
+		})
+		if next == s {
+			return strings.TrimSpace(s)
+		}
+		s = next
+	}
+}
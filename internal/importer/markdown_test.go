@@ -0,0 +1,130 @@
+// Copyright 2025 Asher Buk
+// SPDX-License-Identifier: Apache-2.0
+// https://github.com/AshBuk/FingerGo
+
+package importer
+
+import (
+	"strings"
+	"testing"
+)
+
+const runbook = "# Deploy runbook\n" +
+	"\n" +
+	"Run these steps **in order** on the [build host](https://example.com) before a release.\n" +
+	"\n" +
+	"## Build\n" +
+	"\n" +
+	"```bash title=build.sh\n" +
+	"make build\n" +
+	"```\n" +
+	"\n" +
+	"  ```Golang\n" +
+	"  func main() {\n" +
+	"      run()\n" +
+	"  }\n" +
+	"  ```\n" +
+	"\n" +
+	"Database\n" +
+	"--------\n" +
+	"\n" +
+	"- Check `max_connections` before you run the migration.\n" +
+	"- Short item.\n" +
+	"\n" +
+	"~~~~sql\n" +
+	"SELECT 1;\n" +
+	"```\n" +
+	"not a fence end\n" +
+	"~~~~\n" +
+	"\n" +
+	"```mermaid\n" +
+	"graph TD\n" +
+	"```\n" +
+	"\n" +
+	"```\n" +
+	"```\n"
+
+func TestParseMarkdown(t *testing.T) {
+	blocks := parseMarkdown(runbook, true)
+	want := []mdBlock{
+		{heading: "Deploy runbook", language: "english", content: "Run these steps in order on the build host before a release.", prose: true},
+		{heading: "Build", language: "bash", content: "make build"},
+		{heading: "Build", language: "go", content: "func main() {\n    run()\n}"},
+		{heading: "Database", language: "english", content: "Check max_connections before you run the migration.", prose: true},
+		{heading: "Database", language: "sql", content: "SELECT 1;\n```\nnot a fence end"},
+		{heading: "Database", language: "text", content: "graph TD"},
+	}
+	if len(blocks) != len(want) {
+		t.Fatalf("got %d blocks, want %d: %+v", len(blocks), len(want), blocks)
+	}
+	for i := range want {
+		if blocks[i] != want[i] {
+			t.Errorf("block %d = %+v, want %+v", i, blocks[i], want[i])
+		}
+	}
+
+	if code := parseMarkdown(runbook, false); len(code) != 4 {
+		t.Errorf("without prose: %d blocks, want 4", len(code))
+	}
+}
+
+func TestImportMarkdown(t *testing.T) {
+	store := setupStore(t)
+	paths := writeFiles(t, map[string]string{
+		"runbook.md": runbook,
+		"empty.md":   "# Nothing\n\nJust words.\n",
+		"notes.txt":  "text",
+	})
+	report, err := ImportMarkdown(store, []string{paths["runbook.md"], paths["empty.md"], paths["notes.txt"]}, MarkdownOptions{})
+	if err != nil {
+		t.Fatalf("ImportMarkdown: %v", err)
+	}
+	if report.Imported != 4 || report.Skipped != 1 || report.Rejected != 1 || report.Categories != 1 {
+		t.Fatalf("report = %+v", report)
+	}
+
+	lib, _ := store.Library()
+	cat := lib.Categories[len(lib.Categories)-1]
+	if cat.Name != "runbook" {
+		t.Fatalf("categories = %+v, want one named runbook", lib.Categories)
+	}
+	titles := make([]string, 0, len(report.Results))
+	for _, res := range report.Results[:4] {
+		text, err := store.Text(res.TextID)
+		if err != nil {
+			t.Fatalf("Text(%s): %v", res.TextID, err)
+		}
+		if text.CategoryID != cat.ID {
+			t.Errorf("%s: category %q, want %q", text.Title, text.CategoryID, cat.ID)
+		}
+		titles = append(titles, text.Title)
+	}
+	if got := strings.Join(titles, "|"); got != "Build|Build (2)|Database|Database (2)" {
+		t.Errorf("titles = %s", got)
+	}
+
+	t.Run("reimport with prose", func(t *testing.T) {
+		report, err := ImportMarkdown(store, []string{paths["runbook.md"]}, MarkdownOptions{IncludeProse: true})
+		if err != nil {
+			t.Fatalf("ImportMarkdown: %v", err)
+		}
+		// Code blocks keep their IDs; the two paragraphs are new
+		if report.Imported != 2 || report.Skipped != 4 || report.Categories != 0 {
+			t.Errorf("report = %+v", report)
+		}
+	})
+}
+
+func TestPlainInline(t *testing.T) {
+	tests := map[string]string{
+		"**bold** and *em* and _under_":     "bold and em and under",
+		"use snake_case_names here":         "use snake_case_names here",
+		"![logo](x.png) [docs][ref] `code`": "logo docs code",
+		"~~old~~ new":                       "old new",
+	}
+	for in, want := range tests {
+		if got := plainInline(in); got != want {
+			t.Errorf("plainInline(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,279 @@
+// Copyright 2025 Asher Buk
+// SPDX-License-Identifier: Apache-2.0
+// https://github.com/AshBuk/FingerGo
+
+package importer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	domain "github.com/AshBuk/FingerGo/internal/domain"
+	"github.com/AshBuk/FingerGo/internal/storage"
+)
+
+// Directory import defaults.
+const (
+	defaultMaxFiles     = 1000
+	defaultMaxTotalSize = 50 << 20 // 50 MB of content per run
+	generatedPeekSize   = 1024     // bytes searched for "Code generated" markers
+)
+
+// ErrNotDirectory is returned by ImportTree when root is not a directory.
+var ErrNotDirectory = errors.New("importer: not a directory")
+
+// prunedDirs are never descended into: VCS metadata, dependencies and build output.
+var prunedDirs = map[string]bool{
+	"node_modules": true, "vendor": true, "third_party": true, "bower_components": true,
+	"dist": true, "build": true, "target": true, "out": true, "bin": true, "obj": true,
+	"__pycache__": true, "venv": true, "Pods": true,
+}
+
+// generatedFiles are lock files and other machine-written files, by exact name.
+var generatedFiles = map[string]bool{
+	"package-lock.json": true, "yarn.lock": true, "pnpm-lock.yaml": true, "go.sum": true,
+	"Cargo.lock": true, "poetry.lock": true, "Gemfile.lock": true, "composer.lock": true,
+}
+
+// generatedSuffixes mark generated or minified sources.
+var generatedSuffixes = []string{
+	".min.js", ".min.css", ".pb.go", "_gen.go", ".gen.go", "_generated.go",
+	".g.dart", ".freezed.dart", ".designer.cs",
+}
+
+// TreeOptions configures ImportTree. Zero values select the defaults.
+type TreeOptions struct {
+	ParentID     string `json:"parentId"`     // category to nest the tree under (empty for the root)
+	MaxFiles     int    `json:"maxFiles"`     // source files to process at most (default 1000)
+	MaxFileSize  int64  `json:"maxFileSize"`  // larger files are skipped (default storage.MaxContentLength)
+	MaxTotalSize int64  `json:"maxTotalSize"` // content bytes per run (default 50 MB)
+}
+
+// ImportTree imports the source files below root (e.g. a checked-out git
+// repository) and mirrors its folders as nested categories under
+// opts.ParentID, starting with a category named after root.
+//
+// Walking honours .gitignore files, does not follow symlinks and prunes
+// hidden directories, dependencies and build output (vendor/, node_modules/,
+// dist/, ...). Only files with a known extension (domain.DetectLanguage) are
+// considered; binaries, generated files and files over the size limit are
+// reported as skipped. IDs derive from absolute paths, so running the import
+// again updates changed files in place instead of duplicating them.
+func ImportTree(store storage.TextStore, root string, opts TreeOptions) (Report, error) {
+	var report Report
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		return report, err
+	}
+	info, err := os.Stat(abs)
+	if err != nil {
+		return report, err
+	}
+	if !info.IsDir() {
+		return report, fmt.Errorf("%w: %s", ErrNotDirectory, root)
+	}
+	if err := checkCategory(store, opts.ParentID); err != nil {
+		return report, err
+	}
+	lib, err := store.Library()
+	if err != nil {
+		return report, err
+	}
+	w := &treeWalker{
+		store:      store,
+		opts:       opts.withDefaults(),
+		root:       abs,
+		report:     &report,
+		now:        time.Now().UTC(),
+		categories: make(map[string]bool, len(lib.Categories)),
+		names:      make(map[string]bool, len(lib.Categories)),
+		dirIDs:     make(map[string]string),
+	}
+	for _, c := range lib.Categories {
+		w.categories[c.ID] = true
+		w.names[c.Name] = true
+	}
+	err = w.walk(abs, ".", loadGitignore(nil, abs, "."))
+	if errors.Is(err, errLimitReached) {
+		report.Truncated = true
+		err = nil
+	}
+	return report, err
+}
+
+func (o TreeOptions) withDefaults() TreeOptions {
+	if o.MaxFiles <= 0 {
+		o.MaxFiles = defaultMaxFiles
+	}
+	if o.MaxFileSize <= 0 || o.MaxFileSize > storage.MaxContentLength {
+		o.MaxFileSize = storage.MaxContentLength
+	}
+	if o.MaxTotalSize <= 0 {
+		o.MaxTotalSize = defaultMaxTotalSize
+	}
+	return o
+}
+
+// errLimitReached stops the walk once a TreeOptions limit is hit.
+var errLimitReached = errors.New("importer: limit reached")
+
+// treeWalker carries the state of one ImportTree run.
+type treeWalker struct {
+	store      storage.TextStore
+	report     *Report
+	now        time.Time
+	categories map[string]bool   // existing category IDs
+	names      map[string]bool   // existing category names (must stay unique)
+	dirIDs     map[string]string // relDir → category ID, filled lazily
+	root       string
+	opts       TreeOptions
+	files      int   // source files processed
+	total      int64 // content bytes processed
+}
+
+// walk visits dir (relDir relative to root) in name order.
+func (w *treeWalker) walk(dir, relDir string, rules ignoreRules) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		w.report.add(FileResult{Path: dir, Status: StatusRejected, Reason: err.Error()})
+		return nil
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		rel := path.Join(relDir, name)
+		full := filepath.Join(dir, name)
+		switch {
+		case entry.Type()&os.ModeSymlink != 0, !entry.IsDir() && !entry.Type().IsRegular():
+			continue
+		case entry.IsDir():
+			if strings.HasPrefix(name, ".") || prunedDirs[name] || rules.ignored(rel, true) {
+				continue
+			}
+			if err := w.walk(full, rel, loadGitignore(rules, full, rel)); err != nil {
+				return err
+			}
+		default:
+			language, ok := domain.DetectLanguage(name)
+			if !ok || rules.ignored(rel, false) {
+				continue
+			}
+			if w.files >= w.opts.MaxFiles || w.total >= w.opts.MaxTotalSize {
+				return errLimitReached
+			}
+			w.files++
+			w.report.add(w.importFile(full, relDir, language))
+		}
+	}
+	return nil
+}
+
+// importFile creates or updates the text for one source file.
+func (w *treeWalker) importFile(full, relDir, language string) FileResult {
+	res := FileResult{Path: full, Language: language}
+	name := filepath.Base(full)
+	if generatedFiles[name] || hasAnySuffix(name, generatedSuffixes) {
+		res.Status, res.Reason = StatusSkipped, "generated file"
+		return res
+	}
+	info, err := os.Stat(full)
+	if err != nil {
+		res.Status, res.Reason = StatusRejected, err.Error()
+		return res
+	}
+	if info.Size() > w.opts.MaxFileSize {
+		res.Status, res.Reason = StatusSkipped, fmt.Sprintf("larger than %d bytes", w.opts.MaxFileSize)
+		return res
+	}
+	raw, err := os.ReadFile(full)
+	if err != nil {
+		res.Status, res.Reason = StatusRejected, err.Error()
+		return res
+	}
+	w.total += int64(len(raw))
+	if isGenerated(raw) {
+		res.Status, res.Reason = StatusSkipped, "generated file"
+		return res
+	}
+	content, err := decodeContent(raw)
+	if err != nil {
+		res.Status, res.Reason = StatusSkipped, err.Error()
+		return res
+	}
+	categoryID, err := w.categoryFor(relDir)
+	if err != nil {
+		res.Status, res.Reason = StatusRejected, err.Error()
+		return res
+	}
+	text := &domain.Text{
+		ID:         textID(name, full),
+		Title:      fileTitle(name, language),
+		Content:    content,
+		CategoryID: categoryID,
+		Language:   language,
+		CreatedAt:  w.now,
+	}
+	return upsertResult(w.store, full, text)
+}
+
+// categoryFor returns the category mirroring relDir, creating it and its
+// parents on first use, so folders without importable files stay out of the library.
+func (w *treeWalker) categoryFor(relDir string) (string, error) {
+	if id, ok := w.dirIDs[relDir]; ok {
+		return id, nil
+	}
+	parentID := w.opts.ParentID
+	if relDir != "." {
+		var err error
+		if parentID, err = w.categoryFor(path.Dir(relDir)); err != nil {
+			return "", err
+		}
+	}
+	full := filepath.Join(w.root, filepath.FromSlash(relDir))
+	name := filepath.Base(full)
+	cat := &domain.Category{ID: textID(name, full), Name: name, ParentID: parentID}
+	if !w.categories[cat.ID] {
+		cat.Name = w.uniqueName(name, relDir, cat.ID)
+		if err := w.store.SaveCategory(cat); err != nil {
+			return "", fmt.Errorf("create category for %s: %w", relDir, err)
+		}
+		w.categories[cat.ID] = true
+		w.names[cat.Name] = true
+		w.report.Categories++
+	}
+	w.dirIDs[relDir] = cat.ID
+	return cat.ID, nil
+}
+
+// uniqueName picks a category name not used yet: the folder name, else its
+// path below the import root, else the path with the ID suffix.
+func (w *treeWalker) uniqueName(name, relDir, id string) string {
+	candidates := []string{name}
+	if relDir != "." {
+		candidates = append(candidates, path.Join(filepath.Base(w.root), relDir))
+	}
+	return uniqueCategoryName(w.names, id, candidates...)
+}
+
+// isGenerated looks for the conventional generated-code markers near the top of a file.
+func isGenerated(raw []byte) bool {
+	head := raw[:min(len(raw), generatedPeekSize)]
+	if bytes.Contains(head, []byte("Code generated")) && bytes.Contains(head, []byte("DO NOT EDIT")) {
+		return true
+	}
+	return bytes.Contains(head, []byte("@generated"))
+}
+
+func hasAnySuffix(name string, suffixes []string) bool {
+	for _, s := range suffixes {
+		if strings.HasSuffix(name, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,177 @@
+// Copyright 2025 Asher Buk
+// SPDX-License-Identifier: Apache-2.0
+// https://github.com/AshBuk/FingerGo
+
+package importer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	domain "github.com/AshBuk/FingerGo/internal/domain"
+)
+
+// sampleRepo lays out a small project with ignored, vendored and generated files.
+func sampleRepo(t *testing.T) string {
+	t.Helper()
+	paths := writeFiles(t, map[string]string{
+		".gitignore":                    "*.log\n/secret.go\ntmp/\n!keep.log\n",
+		"main.go":                       "package main\n",
+		"secret.go":                     "package main // ignored by /secret.go\n",
+		"debug.log":                     "not a source file anyway\n",
+		"README.md":                     "# Demo\n",
+		"internal/storage/store.go":     "package storage\n",
+		"internal/storage/store.pb.go":  "package storage\n",
+		"internal/storage/to_string.go": "package storage // hand-written\n",
+		"internal/gen/types.go":         "// Code generated by stringer. DO NOT EDIT.\npackage gen\n",
+		"cmd/storage/main.go":           "package main\n",
+		"vendor/lib/lib.go":             "package lib\n",
+		"node_modules/x/index.js":       "module.exports = 1\n",
+		".git/config.go":                "package git\n",
+		"tmp/scratch.go":                "package tmp\n",
+		"nested/.gitignore":             "local.py\n",
+		"nested/local.py":               "print(1)\n",
+		"nested/kept.py":                "print(2)\n",
+		"assets/logo.json":              "\x00\x01",
+	})
+	return filepath.Dir(paths["main.go"])
+}
+
+func TestImportTree(t *testing.T) {
+	store := setupStore(t)
+	root := sampleRepo(t)
+	report, err := ImportTree(store, root, TreeOptions{})
+	if err != nil {
+		t.Fatalf("ImportTree: %v", err)
+	}
+
+	imported := make(map[string]bool)
+	for _, res := range report.Results {
+		rel, _ := filepath.Rel(root, res.Path)
+		if res.Status == StatusImported {
+			imported[filepath.ToSlash(rel)] = true
+		}
+	}
+	for _, want := range []string{"main.go", "README.md", "internal/storage/store.go", "internal/storage/to_string.go",
+		"cmd/storage/main.go", "nested/kept.py"} {
+		if !imported[want] {
+			t.Errorf("%s not imported; results: %+v", want, report.Results)
+		}
+	}
+	for _, unwanted := range []string{"secret.go", "internal/storage/store.pb.go", "internal/gen/types.go",
+		"vendor/lib/lib.go", "node_modules/x/index.js", ".git/config.go", "tmp/scratch.go", "nested/local.py", "assets/logo.json"} {
+		if imported[unwanted] {
+			t.Errorf("%s should not be imported", unwanted)
+		}
+	}
+
+	lib, _ := store.Library()
+	byID := make(map[string]domain.Category)
+	names := make(map[string]bool)
+	for _, c := range lib.Categories {
+		byID[c.ID] = c
+		if names[c.Name] {
+			t.Errorf("duplicate category name %q", c.Name)
+		}
+		names[c.Name] = true
+	}
+	// internal/storage/store.go → storage → internal → repo root category;
+	// cmd/storage was created first, so the second "storage" gets its path as name
+	base := filepath.Base(root)
+	text, _ := store.Text(textID("store.go", filepath.Join(root, "internal", "storage", "store.go")))
+	chain := []string{}
+	for id := text.CategoryID; id != ""; id = byID[id].ParentID {
+		chain = append(chain, byID[id].Name)
+	}
+	if len(chain) != 3 || chain[0] != base+"/internal/storage" || chain[1] != "internal" || chain[2] != base {
+		t.Errorf("category chain = %v, want [%s/internal/storage internal %s]", chain, base, base)
+	}
+	if report.Categories != len(lib.Categories)-1 { // minus the embedded "welcome" category
+		t.Errorf("report.Categories = %d, library has %d new", report.Categories, len(lib.Categories)-1)
+	}
+}
+
+func TestImportTree_RerunUpdatesChangedFiles(t *testing.T) {
+	store := setupStore(t)
+	root := sampleRepo(t)
+	first, err := ImportTree(store, root, TreeOptions{})
+	if err != nil {
+		t.Fatalf("ImportTree: %v", err)
+	}
+	libBefore, _ := store.Library()
+
+	if err := os.WriteFile(filepath.Join(root, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0o600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	second, err := ImportTree(store, root, TreeOptions{})
+	if err != nil {
+		t.Fatalf("ImportTree: %v", err)
+	}
+	if second.Imported != 0 || second.Updated != 1 || second.Categories != 0 {
+		t.Errorf("rerun = imported %d, updated %d, categories %d; want 0, 1, 0", second.Imported, second.Updated, second.Categories)
+	}
+	libAfter, _ := store.Library()
+	if len(libAfter.Texts) != len(libBefore.Texts) || len(libAfter.Categories) != len(libBefore.Categories) {
+		t.Errorf("rerun duplicated entries: texts %d→%d, categories %d→%d",
+			len(libBefore.Texts), len(libAfter.Texts), len(libBefore.Categories), len(libAfter.Categories))
+	}
+	if first.Imported == 0 {
+		t.Error("first run imported nothing")
+	}
+}
+
+func TestImportTree_Limits(t *testing.T) {
+	store := setupStore(t)
+	root := sampleRepo(t)
+	report, err := ImportTree(store, root, TreeOptions{MaxFiles: 2})
+	if err != nil {
+		t.Fatalf("ImportTree: %v", err)
+	}
+	if !report.Truncated || report.Imported+report.Skipped > 2 {
+		t.Errorf("MaxFiles=2: truncated=%v, processed=%d", report.Truncated, report.Imported+report.Skipped)
+	}
+
+	report, err = ImportTree(setupStore(t), root, TreeOptions{MaxFileSize: 5})
+	if err != nil {
+		t.Fatalf("ImportTree: %v", err)
+	}
+	if report.Imported != 0 {
+		t.Errorf("MaxFileSize=5 imported %d files", report.Imported)
+	}
+}
+
+func TestIgnoreRules(t *testing.T) {
+	var rules ignoreRules
+	for _, line := range []string{"*.log", "!keep.log", "/root-only.txt", "build/", "docs/**/*.tmp", "# comment", ""} {
+		if rule, ok := parseIgnoreLine(line, "."); ok {
+			rules = append(rules, rule)
+		}
+	}
+	sub, _ := parseIgnoreLine("local.py", "pkg")
+	rules = append(rules, sub)
+
+	tests := []struct {
+		rel   string
+		isDir bool
+		want  bool
+	}{
+		{"a.log", false, true},
+		{"deep/dir/a.log", false, true},
+		{"keep.log", false, false},
+		{"root-only.txt", false, true},
+		{"sub/root-only.txt", false, false},
+		{"build", true, true},
+		{"build", false, false},
+		{"docs/a/b/x.tmp", false, true},
+		{"docs/x.tmp", false, true},
+		{"pkg/local.py", false, true},
+		{"local.py", false, false},
+		{"main.go", false, false},
+	}
+	for _, tt := range tests {
+		if got := rules.ignored(tt.rel, tt.isDir); got != tt.want {
+			t.Errorf("ignored(%q, dir=%v) = %v, want %v", tt.rel, tt.isDir, got, tt.want)
+		}
+	}
+}
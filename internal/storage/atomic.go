@@ -0,0 +1,158 @@
+// Copyright 2025 Asher Buk
+// SPDX-License-Identifier: Apache-2.0
+// https://github.com/AshBuk/FingerGo
+
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+const (
+	backupSuffix = ".bak"  // previous generation of a file kept by writeFileBackup
+	tempMarker   = ".tmp-" // infix of in-flight temp files: .{name}.tmp-{random}
+)
+
+// writeFile atomically replaces relPath with data.
+// The content goes to a temp file in the same directory, is fsynced, renamed
+// over the target and the directory is fsynced, so a crash leaves either the
+// old or the new file on disk — never a truncated one.
+func (m *Manager) writeFile(relPath string, data []byte) error {
+	return m.replaceFile(m.join(relPath), data, false)
+}
+
+// writeFileBackup is writeFile that also keeps the previous generation of
+// the target as {relPath}.bak for readFileFallback to recover from.
+func (m *Manager) writeFileBackup(relPath string, data []byte) error {
+	return m.replaceFile(m.join(relPath), data, true)
+}
+
+// replaceFile implements the temp+fsync+rename sequence behind writeFile.
+func (m *Manager) replaceFile(target string, data []byte, keepBackup bool) error {
+	if err := m.checkWritable(); err != nil {
+		return err
+	}
+	return replaceFileAt(target, data, keepBackup)
+}
+
+// replaceFileAt atomically replaces target, which may lie outside the data
+// directory (exported packs). Permissions: 0o600 (rw-------) — owner-only access.
+func replaceFileAt(target string, data []byte, keepBackup bool) (err error) {
+	dir := filepath.Dir(target)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(target)+tempMarker+"*")
+	if err != nil {
+		return fmt.Errorf("storage: create temp for %q: %w", target, err)
+	}
+	tmpPath := tmp.Name()
+	defer func() {
+		if err != nil {
+			_ = os.Remove(tmpPath)
+		}
+	}()
+	if _, err = tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("storage: write %q: %w", target, err)
+	}
+	if err = tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("storage: sync %q: %w", target, err)
+	}
+	if err = tmp.Close(); err != nil {
+		return fmt.Errorf("storage: close %q: %w", target, err)
+	}
+	if err = os.Chmod(tmpPath, 0o600); err != nil {
+		return fmt.Errorf("storage: chmod %q: %w", target, err)
+	}
+	if keepBackup {
+		if err = snapshotFile(target, target+backupSuffix); err != nil {
+			return err
+		}
+	}
+	if err = os.Rename(tmpPath, target); err != nil {
+		return fmt.Errorf("storage: replace %q: %w", target, err)
+	}
+	if err = syncDir(dir); err != nil {
+		return fmt.Errorf("storage: sync directory %q: %w", dir, err)
+	}
+	return nil
+}
+
+// snapshotFile preserves src as dst before src is replaced.
+// A hard link is tried first (no data copy, no window without a backup);
+// filesystems without link support (FAT, some network mounts) get a copy.
+func snapshotFile(src, dst string) error {
+	if _, err := os.Stat(src); errors.Is(err, os.ErrNotExist) {
+		return nil // first generation, nothing to keep
+	}
+	if err := os.Remove(dst); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("storage: remove stale backup %q: %w", dst, err)
+	}
+	if err := os.Link(src, dst); err == nil {
+		return nil
+	}
+	if err := copyFile(src, dst); err != nil {
+		return fmt.Errorf("storage: backup %q: %w", src, err)
+	}
+	return nil
+}
+
+// copyFile copies src to dst and fsyncs the result.
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		_ = out.Close()
+		return err
+	}
+	if err := out.Sync(); err != nil {
+		_ = out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// syncDir flushes directory metadata so a completed rename survives power loss.
+// Windows cannot open directories for syncing; NTFS journals renames itself.
+func syncDir(dir string) error {
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
+
+// removeStaleTemps deletes temp files left behind by writes interrupted by a crash.
+func (m *Manager) removeStaleTemps(relDir string) error {
+	dir := m.join(relDir)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("storage: list %q: %w", dir, err)
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, ".") || !strings.Contains(name, tempMarker) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(dir, name)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("storage: remove stale temp %q: %w", name, err)
+		}
+	}
+	return nil
+}
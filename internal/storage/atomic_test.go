@@ -0,0 +1,144 @@
+// Copyright 2025 Asher Buk
+// SPDX-License-Identifier: Apache-2.0
+// https://github.com/AshBuk/FingerGo
+
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// setupManager creates an initialized manager in a temp directory.
+func setupManager(t *testing.T) *Manager {
+	t.Helper()
+	mgr, err := New(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create manager: %v", err)
+	}
+	if err := mgr.Init(); err != nil {
+		t.Fatalf("failed to init manager: %v", err)
+	}
+	return mgr
+}
+
+func TestManager_WriteFile(t *testing.T) {
+	t.Run("replaces content without leaving temp files", func(t *testing.T) {
+		mgr := setupManager(t)
+		if err := mgr.writeFile("note.txt", []byte("first")); err != nil {
+			t.Fatalf("writeFile() error: %v", err)
+		}
+		if err := mgr.writeFile("note.txt", []byte("second")); err != nil {
+			t.Fatalf("writeFile() error: %v", err)
+		}
+		data, err := os.ReadFile(filepath.Join(mgr.Root(), "note.txt"))
+		if err != nil {
+			t.Fatalf("ReadFile() error: %v", err)
+		}
+		if string(data) != "second" {
+			t.Errorf("got %q, want %q", data, "second")
+		}
+		entries, _ := os.ReadDir(mgr.Root())
+		for _, e := range entries {
+			if filepath.Ext(e.Name()) == backupSuffix || strings.Contains(e.Name(), tempMarker) {
+				t.Errorf("unexpected leftover file %q", e.Name())
+			}
+		}
+	})
+
+	t.Run("backup variant keeps previous generation", func(t *testing.T) {
+		mgr := setupManager(t)
+		_ = mgr.writeFileBackup("doc.json", []byte(`{"v":1}`))
+		_ = mgr.writeFileBackup("doc.json", []byte(`{"v":2}`))
+		data, err := os.ReadFile(filepath.Join(mgr.Root(), "doc.json"+backupSuffix))
+		if err != nil {
+			t.Fatalf("backup not created: %v", err)
+		}
+		if string(data) != `{"v":1}` {
+			t.Errorf("got backup %q, want previous generation", data)
+		}
+	})
+}
+
+func TestManager_ReadFileFallback(t *testing.T) {
+	decodeInto := func(v *map[string]int) func([]byte) error {
+		return func(data []byte) error { return json.Unmarshal(data, v) }
+	}
+
+	t.Run("recovers from truncated primary", func(t *testing.T) {
+		mgr := setupManager(t)
+		_ = mgr.writeFileBackup("doc.json", []byte(`{"v":1}`))
+		_ = mgr.writeFileBackup("doc.json", []byte(`{"v":2}`))
+		// Simulate a torn write on the live file
+		_ = os.WriteFile(filepath.Join(mgr.Root(), "doc.json"), []byte(`{"v":`), 0o600)
+
+		var got map[string]int
+		if err := mgr.readFileFallback("doc.json", decodeInto(&got)); err != nil {
+			t.Fatalf("readFileFallback() error: %v", err)
+		}
+		if got["v"] != 1 {
+			t.Errorf("got v=%d, want 1 from backup", got["v"])
+		}
+	})
+
+	t.Run("returns parse error when backup is missing", func(t *testing.T) {
+		mgr := setupManager(t)
+		_ = os.WriteFile(filepath.Join(mgr.Root(), "doc.json"), []byte(`{`), 0o600)
+
+		var got map[string]int
+		err := mgr.readFileFallback("doc.json", decodeInto(&got))
+		if err == nil {
+			t.Fatal("expected parse error")
+		}
+		if errors.Is(err, os.ErrNotExist) {
+			t.Errorf("parse failure should not look like a missing file: %v", err)
+		}
+	})
+
+	t.Run("preserves not-exist error", func(t *testing.T) {
+		mgr := setupManager(t)
+		var got map[string]int
+		err := mgr.readFileFallback("missing.json", decodeInto(&got))
+		if !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("expected os.ErrNotExist, got %v", err)
+		}
+	})
+}
+
+func TestManager_InitRemovesStaleTemps(t *testing.T) {
+	mgr := setupManager(t)
+	stale := filepath.Join(mgr.Root(), textsDir, ".index.json"+tempMarker+"123")
+	if err := os.WriteFile(stale, []byte("partial"), 0o600); err != nil {
+		t.Fatalf("WriteFile() error: %v", err)
+	}
+	if err := mgr.Init(); err != nil {
+		t.Fatalf("Init() error: %v", err)
+	}
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Error("stale temp file should be removed on Init")
+	}
+}
+
+func TestSettingsRepository_RecoversFromBackup(t *testing.T) {
+	mgr := setupManager(t)
+	repo, _ := NewSettingsRepository(mgr)
+	for _, theme := range []string{"light", "dark"} {
+		if err := repo.Update("theme", theme); err != nil {
+			t.Fatalf("Update() error: %v", err)
+		}
+	}
+	_ = os.WriteFile(filepath.Join(mgr.Root(), configFile), []byte(`{"schemaVersion":`), 0o600)
+
+	reopened, _ := NewSettingsRepository(mgr)
+	settings, err := reopened.Load()
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if settings.Theme != "light" {
+		t.Errorf("got theme %q, want previous generation %q", settings.Theme, "light")
+	}
+}
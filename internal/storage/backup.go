@@ -0,0 +1,504 @@
+// Copyright 2025 Asher Buk
+// SPDX-License-Identifier: Apache-2.0
+// https://github.com/AshBuk/FingerGo
+
+package storage
+
+import (
+	"archive/zip"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	domain "github.com/AshBuk/FingerGo/internal/domain"
+)
+
+// Backup archive layout: a zip of the data files under their root-relative
+// paths (forward slashes) plus manifest.json. Lock, temp, .bak and .corrupt
+// files are never included.
+const (
+	backupManifestFile  = "manifest.json"
+	backupFormatVersion = 1
+	maxBackupEntrySize  = 1 << 30  // per-file cap when extracting (zip bomb guard)
+	maxManifestSize     = 64 << 20 // checksums for very large libraries fit easily
+	restoreStagingInfix = ".restore-"
+	previousRootInfix   = ".pre-restore-"
+)
+
+// Backup errors.
+var (
+	ErrInvalidBackup  = errors.New("storage: invalid backup archive")
+	ErrBackupChecksum = errors.New("storage: backup checksum mismatch")
+)
+
+// archiveYearPattern matches sessions-archive segment names.
+var archiveYearPattern = regexp.MustCompile(`^[0-9]{4}\.jsonl$`)
+
+// BackupManifest describes the contents of a backup archive.
+type BackupManifest struct {
+	CreatedAt     time.Time         `json:"createdAt"`
+	Schemas       map[string]int    `json:"schemas"`   // data file → schema version
+	Checksums     map[string]string `json:"checksums"` // archive path → SHA-256 (hex)
+	AppVersion    string            `json:"appVersion"`
+	FormatVersion int               `json:"formatVersion"`
+}
+
+// BackupOptions configures ExportBackup.
+type BackupOptions struct {
+	Database   *SQLiteDB // open SQLite backend; snapshotted with VACUUM INTO
+	AppVersion string    // recorded in the manifest
+}
+
+// RestoreReport describes a completed restore.
+type RestoreReport struct {
+	Manifest     BackupManifest `json:"manifest"`
+	PreviousData string         `json:"previousData"` // former data directory, kept for manual rollback
+}
+
+// ExportBackup writes a zip of the data directory to dest (replaced atomically).
+// Pass the open database in opts when the SQLite backend is in use, so the
+// snapshot is consistent.
+func (m *Manager) ExportBackup(dest string, opts BackupOptions) (BackupManifest, error) {
+	manifest := BackupManifest{
+		CreatedAt:     time.Now().UTC(),
+		Schemas:       make(map[string]int),
+		Checksums:     make(map[string]string),
+		AppVersion:    opts.AppVersion,
+		FormatVersion: backupFormatVersion,
+	}
+	names, err := m.backupFiles()
+	if err != nil {
+		return manifest, err
+	}
+	dir := filepath.Dir(dest)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(dest)+tempMarker+"*")
+	if err != nil {
+		return manifest, fmt.Errorf("storage: create backup %q: %w", dest, err)
+	}
+	tmpPath := tmp.Name()
+	committed := false
+	defer func() {
+		if !committed {
+			_ = tmp.Close()
+			_ = os.Remove(tmpPath)
+		}
+	}()
+
+	snapshot := tmpPath + ".db"
+	defer os.Remove(snapshot)
+
+	zw := zip.NewWriter(tmp)
+	if err := m.addBackupFiles(zw, names, opts.Database, snapshot, &manifest); err != nil {
+		return manifest, err
+	}
+	if err := addZipJSON(zw, backupManifestFile, &manifest); err != nil {
+		return manifest, fmt.Errorf("storage: write manifest: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return manifest, fmt.Errorf("storage: finish backup: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		return manifest, fmt.Errorf("storage: sync backup: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return manifest, fmt.Errorf("storage: close backup: %w", err)
+	}
+	if err := os.Rename(tmpPath, dest); err != nil {
+		return manifest, fmt.Errorf("storage: rename backup %q: %w", dest, err)
+	}
+	committed = true
+	return manifest, syncDir(dir)
+}
+
+// RestoreBackup replaces the data directory with the contents of archive.
+//
+// Every entry is checked against the manifest checksums and the allowed
+// layout (text IDs via validateTextID, no path traversal), then extracted into
+// a staging directory next to the root. Only when the staged data validates is
+// the root swapped for it with two renames; the old root is kept as
+// {root}.pre-restore-{timestamp}. The lock is released for the swap and
+// re-acquired by Init, which also migrates backups from older versions. When
+// Init fails after the swap, the report still names the old root.
+//
+// Close any SQLiteDB on this manager first; repositories must be recreated.
+func (m *Manager) RestoreBackup(archive string) (RestoreReport, error) {
+	var report RestoreReport
+	if err := m.checkWritable(); err != nil {
+		return report, err
+	}
+	parent, base := filepath.Dir(m.root), filepath.Base(m.root)
+	stale, _ := filepath.Glob(filepath.Join(parent, "."+base+restoreStagingInfix+"*"))
+	for _, dir := range stale {
+		_ = os.RemoveAll(dir)
+	}
+	staging, err := os.MkdirTemp(parent, "."+base+restoreStagingInfix+"*")
+	if err != nil {
+		return report, fmt.Errorf("storage: create staging directory: %w", err)
+	}
+	committed := false
+	defer func() {
+		if !committed {
+			_ = os.RemoveAll(staging)
+		}
+	}()
+	manifest, err := extractBackup(archive, staging)
+	if err != nil {
+		return report, err
+	}
+	if err := verifyStagedData(staging); err != nil {
+		return report, err
+	}
+
+	previous := m.root + previousRootInfix + time.Now().Format(quarantineLayout)
+	for i := 1; ; i++ {
+		if _, err := os.Lstat(previous); errors.Is(err, os.ErrNotExist) {
+			break
+		}
+		previous = fmt.Sprintf("%s%s%s-%d", m.root, previousRootInfix, time.Now().Format(quarantineLayout), i)
+	}
+	if err := m.Close(); err != nil {
+		return report, err
+	}
+	if err := os.Rename(m.root, previous); err != nil {
+		return report, errors.Join(fmt.Errorf("storage: move aside %q: %w", m.root, err), m.acquireLock())
+	}
+	if err := os.Rename(staging, m.root); err != nil {
+		rollbackErr := os.Rename(previous, m.root)
+		return report, errors.Join(fmt.Errorf("storage: swap in restored data: %w", err), rollbackErr, m.acquireLock())
+	}
+	// From here on the old data lives at previous: report it even on failure
+	committed = true
+	report = RestoreReport{Manifest: manifest, PreviousData: previous}
+	if err := syncDir(parent); err != nil {
+		return report, fmt.Errorf("storage: sync restored data (previous data kept at %q): %w", previous, err)
+	}
+	if err := m.Init(); err != nil {
+		return report, fmt.Errorf("storage: initialize restored data (previous data kept at %q): %w", previous, err)
+	}
+	return report, nil
+}
+
+// addBackupFiles archives names and records their checksums and schema
+// versions in manifest. The database, when given, is archived from a
+// VACUUM INTO snapshot written to snapshot.
+func (m *Manager) addBackupFiles(zw *zip.Writer, names []string, db *SQLiteDB, snapshot string, manifest *BackupManifest) error {
+	for _, name := range names {
+		src := m.join(filepath.FromSlash(name))
+		if name == sqliteFile && db != nil {
+			if _, err := db.db.Exec(`VACUUM INTO ?`, snapshot); err != nil {
+				return fmt.Errorf("storage: snapshot database: %w", err)
+			}
+			src = snapshot
+		}
+		sum, err := addZipFile(zw, name, src)
+		if err != nil {
+			return err
+		}
+		manifest.Checksums[name] = sum
+		if version, ok, err := fileSchemaVersion(name, src); err != nil {
+			return err
+		} else if ok {
+			manifest.Schemas[name] = version
+		}
+	}
+	return nil
+}
+
+// backupFiles lists the data files to archive as slash-separated relative paths.
+func (m *Manager) backupFiles() ([]string, error) {
+	var names []string
+	err := filepath.WalkDir(m.root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(m.root, p)
+		if err != nil {
+			return err
+		}
+		if name := filepath.ToSlash(rel); backupEntryAllowed(name) == nil {
+			names = append(names, name)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("storage: list data files: %w", err)
+	}
+	return names, nil
+}
+
+// backupEntryAllowed accepts only the known data layout. Anything else,
+// including absolute or parent-relative paths, is rejected.
+func backupEntryAllowed(name string) error {
+	if strings.Contains(name, `\`) || path.Clean(name) != name || !filepath.IsLocal(filepath.FromSlash(name)) {
+		return fmt.Errorf("%w: unsafe path %q", ErrInvalidBackup, name)
+	}
+	switch name {
+	case textsIndexFile, configFile, sessionsJournalFile, sqliteFile:
+		return nil
+	}
+	if rest, ok := strings.CutPrefix(name, trashDir+"/"); ok {
+		return trashEntryAllowed(name, rest)
+	}
+	dir, file := path.Split(name)
+	switch dir {
+	case textsContentDir + "/":
+		id, ok := strings.CutSuffix(file, ".txt")
+		if !ok {
+			break
+		}
+		if err := validateTextID(id); err != nil {
+			return fmt.Errorf("%w: %q: %w", ErrInvalidBackup, name, err)
+		}
+		return nil
+	case textsRevisionsDir + "/", textsProgressDir + "/":
+		id, ok := strings.CutSuffix(file, ".json")
+		if !ok {
+			break
+		}
+		if err := validateTextID(id); err != nil {
+			return fmt.Errorf("%w: %q: %w", ErrInvalidBackup, name, err)
+		}
+		return nil
+	case sessionsArchiveDir + "/":
+		if archiveYearPattern.MatchString(file) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: unexpected entry %q", ErrInvalidBackup, name)
+}
+
+// trashEntryAllowed accepts trash/{entryID}/entry.json and
+// trash/{entryID}/content/{textID}.txt with valid IDs.
+func trashEntryAllowed(name, rest string) error {
+	parts := strings.Split(rest, "/")
+	if !validIDPattern.MatchString(parts[0]) {
+		return fmt.Errorf("%w: %q: invalid trash entry ID", ErrInvalidBackup, name)
+	}
+	switch {
+	case len(parts) == 2 && parts[1] == trashEntryFile:
+		return nil
+	case len(parts) == 3 && parts[1] == trashContentDir:
+		id, ok := strings.CutSuffix(parts[2], ".txt")
+		if !ok {
+			break
+		}
+		if err := validateTextID(id); err != nil {
+			return fmt.Errorf("%w: %q: %w", ErrInvalidBackup, name, err)
+		}
+		return nil
+	}
+	return fmt.Errorf("%w: unexpected entry %q", ErrInvalidBackup, name)
+}
+
+// addZipFile stores src in the archive as name and returns its checksum.
+func addZipFile(zw *zip.Writer, name, src string) (string, error) {
+	f, err := os.Open(src)
+	if err != nil {
+		return "", fmt.Errorf("storage: open %q: %w", src, err)
+	}
+	defer f.Close()
+	w, err := zw.Create(name)
+	if err != nil {
+		return "", fmt.Errorf("storage: add %q to backup: %w", name, err)
+	}
+	h := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(w, h), f); err != nil {
+		return "", fmt.Errorf("storage: add %q to backup: %w", name, err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// addZipJSON stores v in the archive as indented JSON named name.
+func addZipJSON(zw *zip.Writer, name string, v any) error {
+	w, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// fileSchemaVersion reports the schema version of versioned data files.
+func fileSchemaVersion(name, src string) (int, bool, error) {
+	switch name {
+	case textsIndexFile, configFile:
+		raw, err := os.ReadFile(src)
+		if err != nil {
+			return 0, false, fmt.Errorf("storage: read %q: %w", src, err)
+		}
+		env, err := readEnvelope(raw)
+		if err != nil {
+			return 0, false, fmt.Errorf("%w: %s: %w", ErrInvalidBackup, name, err)
+		}
+		return env.SchemaVersion, true, nil
+	case sqliteFile:
+		version, err := databaseVersion(src)
+		return version, err == nil, err
+	}
+	return 0, false, nil
+}
+
+// databaseVersion reads PRAGMA user_version from a database file.
+func databaseVersion(src string) (int, error) {
+	db, err := sql.Open("sqlite", src+"?_pragma=query_only(1)")
+	if err != nil {
+		return 0, fmt.Errorf("storage: open %q: %w", src, err)
+	}
+	defer db.Close()
+	var version int
+	if err := db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		return 0, fmt.Errorf("storage: read version of %q: %w", src, err)
+	}
+	return version, nil
+}
+
+// extractBackup validates archive entries against the manifest and writes them into dir.
+func extractBackup(archive, dir string) (BackupManifest, error) {
+	zr, err := zip.OpenReader(archive)
+	if err != nil {
+		return BackupManifest{}, fmt.Errorf("%w: %w", ErrInvalidBackup, err)
+	}
+	defer zr.Close()
+	manifest, err := readBackupManifest(&zr.Reader)
+	if err != nil {
+		return manifest, err
+	}
+	seen := make(map[string]bool, len(zr.File))
+	for _, f := range zr.File {
+		if f.Name == backupManifestFile || f.FileInfo().IsDir() {
+			continue
+		}
+		if err := backupEntryAllowed(f.Name); err != nil {
+			return manifest, err
+		}
+		want, listed := manifest.Checksums[f.Name]
+		if !listed || seen[f.Name] {
+			return manifest, fmt.Errorf("%w: %q is duplicated or missing from the manifest", ErrInvalidBackup, f.Name)
+		}
+		seen[f.Name] = true
+		if f.UncompressedSize64 > maxBackupEntrySize {
+			return manifest, fmt.Errorf("%w: %q is too large", ErrInvalidBackup, f.Name)
+		}
+		got, err := extractZipFile(f, filepath.Join(dir, filepath.FromSlash(f.Name)))
+		if err != nil {
+			return manifest, err
+		}
+		if got != want {
+			return manifest, fmt.Errorf("%w: %s", ErrBackupChecksum, f.Name)
+		}
+	}
+	for name := range manifest.Checksums {
+		if !seen[name] {
+			return manifest, fmt.Errorf("%w: %q listed in the manifest is missing", ErrInvalidBackup, name)
+		}
+	}
+	if !seen[textsIndexFile] {
+		return manifest, fmt.Errorf("%w: no text library (%s)", ErrInvalidBackup, textsIndexFile)
+	}
+	return manifest, nil
+}
+
+func readBackupManifest(zr *zip.Reader) (BackupManifest, error) {
+	var manifest BackupManifest
+	f, err := zr.Open(backupManifestFile)
+	if err != nil {
+		return manifest, fmt.Errorf("%w: %s missing", ErrInvalidBackup, backupManifestFile)
+	}
+	defer f.Close()
+	if err := json.NewDecoder(io.LimitReader(f, maxManifestSize)).Decode(&manifest); err != nil {
+		return manifest, fmt.Errorf("%w: %s: %w", ErrInvalidBackup, backupManifestFile, err)
+	}
+	switch {
+	case manifest.FormatVersion > backupFormatVersion:
+		return manifest, fmt.Errorf("%w: backup format v%d, this build supports v%d", ErrSchemaTooNew, manifest.FormatVersion, backupFormatVersion)
+	case manifest.FormatVersion < 1:
+		return manifest, fmt.Errorf("%w: missing format version", ErrInvalidBackup)
+	}
+	return manifest, nil
+}
+
+// extractZipFile writes one entry to target and returns its checksum.
+func extractZipFile(f *zip.File, target string) (string, error) {
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return "", fmt.Errorf("storage: create directory for %q: %w", f.Name, err)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return "", fmt.Errorf("%w: %q: %w", ErrInvalidBackup, f.Name, err)
+	}
+	defer rc.Close()
+	out, err := os.OpenFile(target, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		return "", fmt.Errorf("storage: create %q: %w", target, err)
+	}
+	h := sha256.New()
+	n, err := io.Copy(io.MultiWriter(out, h), io.LimitReader(rc, maxBackupEntrySize+1))
+	if err == nil && n > maxBackupEntrySize {
+		err = fmt.Errorf("%w: %q is too large", ErrInvalidBackup, f.Name)
+	}
+	if err == nil {
+		err = out.Sync()
+	}
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", fmt.Errorf("storage: extract %q: %w", f.Name, err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// verifyStagedData checks that the restored files are readable by this build
+// and that the library holds only valid IDs.
+func verifyStagedData(dir string) error {
+	raw, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(textsIndexFile)))
+	if err != nil {
+		return fmt.Errorf("storage: read staged index: %w", err)
+	}
+	var library domain.TextLibrary
+	if err := decodeDocument(textsIndexFile, raw, &library); err != nil {
+		if errors.Is(err, ErrSchemaTooNew) {
+			return err
+		}
+		return fmt.Errorf("%w: %s: %w", ErrInvalidBackup, textsIndexFile, err)
+	}
+	for i := range library.Texts {
+		if err := validateTextID(library.Texts[i].ID); err != nil {
+			return fmt.Errorf("%w: %s: %w", ErrInvalidBackup, textsIndexFile, err)
+		}
+	}
+	for i := range library.Categories {
+		if err := validateCategoryID(library.Categories[i].ID); err != nil {
+			return fmt.Errorf("%w: %s: %w", ErrInvalidBackup, textsIndexFile, err)
+		}
+	}
+	for name, limit := range map[string]int{configFile: schemaVersion(configFile), sqliteFile: sqliteSchemaVersion} {
+		src := filepath.Join(dir, name)
+		if _, err := os.Stat(src); errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		version, _, err := fileSchemaVersion(name, src)
+		if err != nil {
+			return err
+		}
+		if version > limit {
+			return fmt.Errorf("%w: %s is v%d, this build supports v%d", ErrSchemaTooNew, name, version, limit)
+		}
+	}
+	return nil
+}
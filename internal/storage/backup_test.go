@@ -0,0 +1,256 @@
+// Copyright 2025 Asher Buk
+// SPDX-License-Identifier: Apache-2.0
+// https://github.com/AshBuk/FingerGo
+
+package storage
+
+import (
+	"archive/zip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	domain "github.com/AshBuk/FingerGo/internal/domain"
+)
+
+// writeArchive builds a backup zip from name → content, listing every entry
+// in the manifest with its real checksum.
+func writeArchive(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dest := filepath.Join(t.TempDir(), "backup.zip")
+	f, err := os.Create(dest)
+	if err != nil {
+		t.Fatalf("Create() error: %v", err)
+	}
+	defer f.Close()
+	zw := zip.NewWriter(f)
+	manifest := BackupManifest{FormatVersion: backupFormatVersion, Checksums: make(map[string]string)}
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatalf("zip Create(%q) error: %v", name, err)
+		}
+		_, _ = w.Write([]byte(content))
+		sum := sha256.Sum256([]byte(content))
+		manifest.Checksums[name] = hex.EncodeToString(sum[:])
+	}
+	w, _ := zw.Create(backupManifestFile)
+	_ = json.NewEncoder(w).Encode(&manifest)
+	if err := zw.Close(); err != nil {
+		t.Fatalf("zip Close() error: %v", err)
+	}
+	return dest
+}
+
+func TestManager_BackupRoundTrip(t *testing.T) {
+	mgr := setupManager(t)
+	texts, _ := NewTextRepository(mgr)
+	if err := texts.SaveText(&domain.Text{ID: "kept", Title: "Kept", Content: "in backup", Language: "text"}); err != nil {
+		t.Fatalf("SaveText() error: %v", err)
+	}
+	if err := texts.SaveText(&domain.Text{ID: "binned", Title: "Binned", Content: "in trash", Language: "text"}); err != nil {
+		t.Fatalf("SaveText() error: %v", err)
+	}
+	if err := texts.DeleteText("binned"); err != nil {
+		t.Fatalf("DeleteText() error: %v", err)
+	}
+	sessions, _ := NewSessionRepository(mgr)
+	if _, err := sessions.Record(&domain.SessionPayload{WPM: 55}); err != nil {
+		t.Fatalf("Record() error: %v", err)
+	}
+	archive := filepath.Join(t.TempDir(), "fingergo.zip")
+	manifest, err := mgr.ExportBackup(archive, BackupOptions{AppVersion: "9.9.9"})
+	if err != nil {
+		t.Fatalf("ExportBackup() error: %v", err)
+	}
+	if manifest.AppVersion != "9.9.9" || manifest.Schemas[textsIndexFile] != schemaVersion(textsIndexFile) {
+		t.Errorf("unexpected manifest %+v", manifest)
+	}
+	if _, ok := manifest.Checksums[lockFile]; ok {
+		t.Error("lock file must not be archived")
+	}
+	if _, ok := manifest.Checksums[contentPath("kept")]; !ok {
+		t.Errorf("content file missing from manifest: %v", manifest.Checksums)
+	}
+
+	// Diverge after the backup, then restore
+	if err := texts.DeleteText("kept"); err != nil {
+		t.Fatalf("DeleteText() error: %v", err)
+	}
+	report, err := mgr.RestoreBackup(archive)
+	if err != nil {
+		t.Fatalf("RestoreBackup() error: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(report.PreviousData, contentPath("kept"))); !os.IsNotExist(err) {
+		t.Error("previous data directory should hold the pre-restore state")
+	}
+	restored, _ := NewTextRepository(mgr)
+	if got, err := restored.Text("kept"); err != nil || got.Content != "in backup" {
+		t.Errorf("Text() = %+v, %v; want restored text", got, err)
+	}
+	history, _ := NewSessionRepository(mgr)
+	if got, _ := history.List(0); len(got) != 1 || got[0].WPM != 55 {
+		t.Errorf("sessions not restored: %+v", got)
+	}
+	trash, err := mgr.ListTrash()
+	if err != nil || len(trash) != 1 {
+		t.Fatalf("ListTrash() = %+v, %v; want the trashed text restored", trash, err)
+	}
+	if _, err := mgr.RestoreFromTrash(restored, trash[0].ID); err != nil {
+		t.Fatalf("RestoreFromTrash() error: %v", err)
+	}
+	if got, err := restored.Text("binned"); err != nil || got.Content != "in trash" {
+		t.Errorf("Text() = %+v, %v; want text from the restored trash", got, err)
+	}
+	if _, err := os.Stat(mgr.join(lockFile)); err != nil {
+		t.Errorf("lock should be re-acquired in the restored root: %v", err)
+	}
+	second, _ := New(mgr.Root())
+	if err := second.Init(); !errors.Is(err, ErrLocked) {
+		t.Errorf("restored root must stay locked, got %v", err)
+	}
+}
+
+func TestManager_BackupSQLiteSnapshot(t *testing.T) {
+	mgr := setupManager(t)
+	db := openSQLiteDB(t, mgr)
+	sessions, _ := NewSQLiteSessionRepository(db)
+	if _, err := sessions.Record(&domain.SessionPayload{WPM: 70}); err != nil {
+		t.Fatalf("Record() error: %v", err)
+	}
+	archive := filepath.Join(t.TempDir(), "fingergo.zip")
+	manifest, err := mgr.ExportBackup(archive, BackupOptions{Database: db})
+	if err != nil {
+		t.Fatalf("ExportBackup() error: %v", err)
+	}
+	if manifest.Schemas[sqliteFile] != sqliteSchemaVersion {
+		t.Errorf("got database schema %d, want %d", manifest.Schemas[sqliteFile], sqliteSchemaVersion)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatalf("Close() error: %v", err)
+	}
+	if _, err := mgr.RestoreBackup(archive); err != nil {
+		t.Fatalf("RestoreBackup() error: %v", err)
+	}
+	restored := openSQLiteDB(t, mgr)
+	repo, _ := NewSQLiteSessionRepository(restored)
+	if got, _ := repo.List(0); len(got) != 1 || got[0].WPM != 70 {
+		t.Errorf("sessions not restored from snapshot: %+v", got)
+	}
+}
+
+func TestManager_RestoreBackupRejects(t *testing.T) {
+	index := `{"schemaVersion": 1, "data": {"categories": [], "texts": []}}`
+	tests := []struct {
+		files map[string]string
+		want  error
+		name  string
+	}{
+		{
+			name:  "path traversal",
+			files: map[string]string{textsIndexFile: index, "../escape.txt": "x"},
+			want:  ErrInvalidBackup,
+		},
+		{
+			name:  "absolute path",
+			files: map[string]string{textsIndexFile: index, "/etc/passwd": "x"},
+			want:  ErrInvalidBackup,
+		},
+		{
+			name:  "invalid text ID in content entry",
+			files: map[string]string{textsIndexFile: index, "texts/content/bad id.txt": "x"},
+			want:  ErrInvalidBackup,
+		},
+		{
+			name:  "invalid text ID in index",
+			files: map[string]string{textsIndexFile: `{"schemaVersion": 1, "data": {"texts": [{"id": "../x"}]}}`},
+			want:  ErrInvalidBackup,
+		},
+		{
+			name:  "invalid trash entry ID",
+			files: map[string]string{textsIndexFile: index, "trash/../x/entry.json": "{}"},
+			want:  ErrInvalidBackup,
+		},
+		{
+			name:  "invalid text ID in trash content",
+			files: map[string]string{textsIndexFile: index, "trash/e1/content/bad id.txt": "x"},
+			want:  ErrInvalidBackup,
+		},
+		{
+			name:  "unknown trash file",
+			files: map[string]string{textsIndexFile: index, "trash/e1/run.sh": "x"},
+			want:  ErrInvalidBackup,
+		},
+		{
+			name:  "unknown entry",
+			files: map[string]string{textsIndexFile: index, "run.sh": "x"},
+			want:  ErrInvalidBackup,
+		},
+		{
+			name:  "missing library",
+			files: map[string]string{configFile: `{}`},
+			want:  ErrInvalidBackup,
+		},
+		{
+			name:  "newer schema",
+			files: map[string]string{textsIndexFile: `{"schemaVersion": 99, "data": {}}`},
+			want:  ErrSchemaTooNew,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mgr := setupManager(t)
+			archive := writeArchive(t, tt.files)
+			if _, err := mgr.RestoreBackup(archive); !errors.Is(err, tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, err)
+			}
+			// The live root is untouched and still locked
+			if _, err := os.Stat(mgr.join(textsIndexFile)); err != nil {
+				t.Errorf("data directory damaged by rejected restore: %v", err)
+			}
+			staged, _ := filepath.Glob(filepath.Join(filepath.Dir(mgr.Root()), "*"+restoreStagingInfix+"*"))
+			if len(staged) != 0 {
+				t.Errorf("staging directories left behind: %v", staged)
+			}
+		})
+	}
+}
+
+func TestManager_RestoreBackupChecksumMismatch(t *testing.T) {
+	mgr := setupManager(t)
+	archive := filepath.Join(t.TempDir(), "fingergo.zip")
+	if _, err := mgr.ExportBackup(archive, BackupOptions{}); err != nil {
+		t.Fatalf("ExportBackup() error: %v", err)
+	}
+	// Rewrite the archive with one altered entry but the original manifest
+	zr, err := zip.OpenReader(archive)
+	if err != nil {
+		t.Fatalf("OpenReader() error: %v", err)
+	}
+	tampered := filepath.Join(t.TempDir(), "tampered.zip")
+	out, _ := os.Create(tampered)
+	zw := zip.NewWriter(out)
+	for _, f := range zr.File {
+		w, _ := zw.Create(f.Name)
+		if f.Name == textsIndexFile {
+			_, _ = w.Write([]byte(`{"schemaVersion": 1, "data": {}}`))
+			continue
+		}
+		rc, _ := f.Open()
+		data, _ := io.ReadAll(rc)
+		_ = rc.Close()
+		_, _ = w.Write(data)
+	}
+	_ = zw.Close()
+	_ = out.Close()
+	_ = zr.Close()
+
+	if _, err := mgr.RestoreBackup(tampered); !errors.Is(err, ErrBackupChecksum) {
+		t.Errorf("expected ErrBackupChecksum, got %v", err)
+	}
+}
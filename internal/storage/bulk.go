@@ -0,0 +1,110 @@
+// Copyright 2025 Asher Buk
+// SPDX-License-Identifier: Apache-2.0
+// https://github.com/AshBuk/FingerGo
+
+package storage
+
+import (
+	"errors"
+	"fmt"
+
+	domain "github.com/AshBuk/FingerGo/internal/domain"
+)
+
+// ErrInvalidBulkChange is returned for a BulkChange that changes nothing or
+// combines Delete with other changes.
+var ErrInvalidBulkChange = errors.New("storage: invalid bulk change")
+
+// BulkChange is applied to every text of a batch by TextStore.ApplyBulk.
+// Nil fields are left as they are.
+type BulkChange struct {
+	CategoryID *string `json:"categoryId,omitempty"` // move to this category ("" for uncategorized)
+	Language   *string `json:"language,omitempty"`   // set the language
+	IsFavorite *bool   `json:"isFavorite,omitempty"` // mark or unmark as favorite
+	Delete     bool    `json:"delete,omitempty"`     // move the texts to the trash; excludes the other fields
+}
+
+// BulkResult reports what happened to one ID of a batch.
+type BulkResult struct {
+	ID    string `json:"id"`
+	Error string `json:"error,omitempty"` // why the ID was skipped; empty when the change was applied
+}
+
+// validate checks the change itself; whether the target category exists is
+// up to the backend.
+func (c *BulkChange) validate() error {
+	if c.Delete {
+		if c.CategoryID != nil || c.Language != nil || c.IsFavorite != nil {
+			return fmt.Errorf("%w: delete cannot be combined with other changes", ErrInvalidBulkChange)
+		}
+		return nil
+	}
+	if c.CategoryID == nil && c.Language == nil && c.IsFavorite == nil {
+		return fmt.Errorf("%w: nothing to change", ErrInvalidBulkChange)
+	}
+	if c.CategoryID != nil && *c.CategoryID != "" {
+		if err := validateCategoryID(*c.CategoryID); err != nil {
+			return err
+		}
+	}
+	if c.Language != nil && !domain.IsValidLanguage(*c.Language) {
+		return fmt.Errorf("%w: %s", ErrInvalidLanguage, *c.Language)
+	}
+	return nil
+}
+
+// apply sets the changed fields on text.
+func (c *BulkChange) apply(text *domain.Text) {
+	if c.CategoryID != nil {
+		text.CategoryID = *c.CategoryID
+	}
+	if c.Language != nil {
+		text.Language = *c.Language
+	}
+	if c.IsFavorite != nil {
+		text.IsFavorite = *c.IsFavorite
+	}
+}
+
+// bulkTargets returns one result per distinct ID in ids, in order, and the
+// IDs that exist. Invalid and unknown IDs get their error in the result.
+func bulkTargets(ids []string, exists func(id string) (bool, error)) ([]BulkResult, []string, error) {
+	results := make([]BulkResult, 0, len(ids))
+	var targets []string
+	seen := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		result := BulkResult{ID: id}
+		if err := validateTextID(id); err != nil {
+			result.Error = err.Error()
+		} else if ok, err := exists(id); err != nil {
+			return nil, nil, err
+		} else if !ok {
+			result.Error = fmt.Errorf("%w: %s", ErrTextNotFound, id).Error()
+		} else {
+			targets = append(targets, id)
+		}
+		results = append(results, result)
+	}
+	return results, targets, nil
+}
+
+// bulkTrashName names the trash entry of a bulk deletion.
+func bulkTrashName(texts []domain.Text) string {
+	if len(texts) == 1 {
+		return texts[0].Title
+	}
+	return fmt.Sprintf("%d texts", len(texts))
+}
+
+// idSet returns the IDs in ids as a set.
+func idSet(ids []string) map[string]bool {
+	set := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		set[id] = true
+	}
+	return set
+}
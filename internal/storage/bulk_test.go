@@ -0,0 +1,104 @@
+// Copyright 2025 Asher Buk
+// SPDX-License-Identifier: Apache-2.0
+// https://github.com/AshBuk/FingerGo
+
+package storage
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	domain "github.com/AshBuk/FingerGo/internal/domain"
+)
+
+func TestStores_ApplyBulk(t *testing.T) {
+	for name, open := range backends(t) {
+		t.Run(string(name), func(t *testing.T) {
+			s := open(t)
+			fillLibrary(t, s.texts, trashTreeCategories, trashTreeTexts)
+			b, goLang, yes := "b", "go", true
+
+			results, err := s.texts.ApplyBulk(
+				[]string{"ta", "ta2", "ta", "missing", "../bad"},
+				BulkChange{CategoryID: &b, Language: &goLang, IsFavorite: &yes},
+			)
+			if err != nil {
+				t.Fatalf("ApplyBulk() error: %v", err)
+			}
+			var failed []string
+			for _, r := range results {
+				if r.Error != "" {
+					failed = append(failed, r.ID)
+				}
+			}
+			if len(results) != 4 || !slices.Equal(failed, []string{"missing", "../bad"}) {
+				t.Errorf("results = %+v, want 4 with missing and ../bad failed", results)
+			}
+			for _, id := range []string{"ta", "ta2"} {
+				text, err := s.texts.Text(id)
+				if err != nil || text.CategoryID != "b" || text.Language != "go" || !text.IsFavorite {
+					t.Errorf("Text(%s) = %+v, %v; want moved to b, go, favorite", id, text, err)
+				}
+			}
+			if text, _ := s.texts.Text("tb"); text.IsFavorite || text.Language != "text" {
+				t.Errorf("untouched Text(tb) = %+v", text)
+			}
+			res, err := s.texts.Search(SearchQuery{CategoryID: "b", Favorites: true})
+			if err != nil || res.Total != 2 {
+				t.Errorf("Search(favorites in b) = %+v, %v; want 2 hits", res, err)
+			}
+
+			results, err = s.texts.ApplyBulk([]string{"ta", "tb"}, BulkChange{Delete: true})
+			if err != nil || len(results) != 2 {
+				t.Fatalf("ApplyBulk(delete) = %+v, %v", results, err)
+			}
+			if _, texts := libraryIDs(t, s.texts); !slices.Equal(texts, []string{"quick-sort", "ta2"}) {
+				t.Errorf("texts after bulk delete = %v", texts)
+			}
+			entries, _ := s.mgr.ListTrash()
+			if len(entries) != 1 || entries[0].Name != "2 texts" || len(entries[0].Texts) != 2 {
+				t.Errorf("ListTrash() = %+v, want one entry with both texts", entries)
+			}
+
+			gone, bad := "gone", "klingon"
+			tests := []struct {
+				change BulkChange
+				want   error
+			}{
+				{BulkChange{}, ErrInvalidBulkChange},
+				{BulkChange{Delete: true, IsFavorite: &yes}, ErrInvalidBulkChange},
+				{BulkChange{CategoryID: &gone}, ErrCategoryNotFound},
+				{BulkChange{Language: &bad}, ErrInvalidLanguage},
+			}
+			for _, tc := range tests {
+				if _, err := s.texts.ApplyBulk([]string{"ta2"}, tc.change); !errors.Is(err, tc.want) {
+					t.Errorf("ApplyBulk(%+v): expected %v, got %v", tc.change, tc.want, err)
+				}
+			}
+			if text, _ := s.texts.Text("ta2"); text.CategoryID != "b" {
+				t.Errorf("Text(ta2) changed by a rejected batch: %+v", text)
+			}
+		})
+	}
+}
+
+func TestTextRepository_ApplyBulkPersists(t *testing.T) {
+	mgr := setupManager(t)
+	repo, _ := NewTextRepository(mgr)
+	for _, id := range []string{"x", "y"} {
+		if err := repo.SaveText(&domain.Text{ID: id, Title: id, Content: id}); err != nil {
+			t.Fatalf("SaveText(%s) error: %v", id, err)
+		}
+	}
+	py := "py"
+	if _, err := repo.ApplyBulk([]string{"x", "y"}, BulkChange{Language: &py}); err != nil {
+		t.Fatalf("ApplyBulk() error: %v", err)
+	}
+	reopened, _ := NewTextRepository(mgr)
+	for _, id := range []string{"x", "y"} {
+		if text, err := reopened.Text(id); err != nil || text.Language != "py" || text.Content != id {
+			t.Errorf("reloaded Text(%s) = %+v, %v", id, text, err)
+		}
+	}
+}
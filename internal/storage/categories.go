@@ -0,0 +1,123 @@
+// Copyright 2025 Asher Buk
+// SPDX-License-Identifier: Apache-2.0
+// https://github.com/AshBuk/FingerGo
+
+package storage
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+
+	domain "github.com/AshBuk/FingerGo/internal/domain"
+)
+
+// Category tree operations shared by both TextStore backends. They work on a
+// copy of the flat category list, so a failed write leaves the original intact.
+
+// sortCategories orders categories by SortOrder, keeping the stored order
+// (creation order) for equal values.
+func sortCategories(categories []domain.Category) {
+	slices.SortStableFunc(categories, func(a, b domain.Category) int {
+		return cmp.Compare(a.SortOrder, b.SortOrder)
+	})
+}
+
+// checkCategoryParent verifies that parentID is empty or an existing
+// category outside the subtree of id.
+func checkCategoryParent(categories []domain.Category, id, parentID string) error {
+	if parentID == "" {
+		return nil
+	}
+	parents := make(map[string]string, len(categories))
+	for _, c := range categories {
+		parents[c.ID] = c.ParentID
+	}
+	if _, ok := parents[parentID]; !ok {
+		return fmt.Errorf("%w: parent %s", ErrCategoryNotFound, parentID)
+	}
+	// Walk up from the new parent; meeting id means id would become its own
+	// ancestor. The step limit guards against cycles already on disk
+	for cur, steps := parentID, 0; cur != ""; cur, steps = parents[cur], steps+1 {
+		if cur == id || steps > len(categories) {
+			return fmt.Errorf("%w: %s under %s", ErrCategoryCycle, id, parentID)
+		}
+	}
+	return nil
+}
+
+// updateCategory returns categories with the entry of cat.ID replaced by cat,
+// after checking that it exists, its name stays unique and its parent is valid.
+func updateCategory(categories []domain.Category, cat *domain.Category) ([]domain.Category, error) {
+	idx := -1
+	for i, c := range categories {
+		switch {
+		case c.ID == cat.ID:
+			idx = i
+		case c.Name == cat.Name:
+			return nil, fmt.Errorf("%w: %s", ErrCategoryExists, cat.Name)
+		}
+	}
+	if idx == -1 {
+		return nil, fmt.Errorf("%w: %s", ErrCategoryNotFound, cat.ID)
+	}
+	if err := checkCategoryParent(categories, cat.ID, cat.ParentID); err != nil {
+		return nil, err
+	}
+	out := slices.Clone(categories)
+	out[idx] = *cat
+	return out, nil
+}
+
+// moveCategory returns categories with id moved under parentID ("" for the
+// root) at position among its new siblings in display order; a negative
+// position or one past the end appends. The new siblings are renumbered
+// 0, 1, ... so the resulting order is explicit.
+func moveCategory(categories []domain.Category, id, parentID string, position int) ([]domain.Category, error) {
+	idx := slices.IndexFunc(categories, func(c domain.Category) bool { return c.ID == id })
+	if idx == -1 {
+		return nil, fmt.Errorf("%w: %s", ErrCategoryNotFound, id)
+	}
+	if err := checkCategoryParent(categories, id, parentID); err != nil {
+		return nil, err
+	}
+	out := slices.Clone(categories)
+	out[idx].ParentID = parentID
+	var siblings []int // positions in out, in display order
+	for i, c := range out {
+		if i != idx && c.ParentID == parentID {
+			siblings = append(siblings, i)
+		}
+	}
+	slices.SortStableFunc(siblings, func(a, b int) int {
+		return cmp.Compare(out[a].SortOrder, out[b].SortOrder)
+	})
+	if position < 0 || position > len(siblings) {
+		position = len(siblings)
+	}
+	siblings = slices.Insert(siblings, position, idx)
+	for order, i := range siblings {
+		out[i].SortOrder = order
+	}
+	return out, nil
+}
+
+// subtreeCategories returns the category id and all its descendants, parents
+// before children, or nil if id does not exist.
+func subtreeCategories(categories []domain.Category, id string) []domain.Category {
+	idx := slices.IndexFunc(categories, func(c domain.Category) bool { return c.ID == id })
+	if idx == -1 {
+		return nil
+	}
+	subtree := []domain.Category{categories[idx]}
+	seen := map[string]bool{id: true} // guards against cycles already on disk
+	for i := 0; i < len(subtree); i++ {
+		for _, c := range categories {
+			if c.ParentID == subtree[i].ID && !seen[c.ID] {
+				seen[c.ID] = true
+				subtree = append(subtree, c)
+			}
+		}
+	}
+	return subtree
+}
@@ -0,0 +1,145 @@
+// Copyright 2025 Asher Buk
+// SPDX-License-Identifier: Apache-2.0
+// https://github.com/AshBuk/FingerGo
+
+package storage
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	domain "github.com/AshBuk/FingerGo/internal/domain"
+)
+
+// childIDs returns the IDs of parentID's children in Library order.
+func childIDs(t *testing.T, s TextStore, parentID string) []string {
+	t.Helper()
+	lib, err := s.Library()
+	if err != nil {
+		t.Fatalf("Library() error: %v", err)
+	}
+	var ids []string
+	for _, c := range lib.Categories {
+		if c.ParentID == parentID && c.ID != "welcome" {
+			ids = append(ids, c.ID)
+		}
+	}
+	return ids
+}
+
+func TestStores_UpdateCategory(t *testing.T) {
+	for name, open := range backends(t) {
+		t.Run(string(name), func(t *testing.T) {
+			s := open(t).texts
+			for _, c := range []domain.Category{{ID: "a", Name: "A"}, {ID: "b", Name: "B", ParentID: "a"}, {ID: "c", Name: "C"}} {
+				if err := s.SaveCategory(&c); err != nil {
+					t.Fatalf("SaveCategory(%s) error: %v", c.ID, err)
+				}
+			}
+			if err := s.SaveText(&domain.Text{ID: "t", Title: "T", Content: "kept", CategoryID: "b"}); err != nil {
+				t.Fatalf("SaveText() error: %v", err)
+			}
+
+			renamed := &domain.Category{ID: "b", Name: "Beta", ParentID: "c", Icon: "🐹"}
+			if err := s.UpdateCategory(renamed); err != nil {
+				t.Fatalf("UpdateCategory() error: %v", err)
+			}
+			lib, _ := s.Library()
+			idx := slices.IndexFunc(lib.Categories, func(c domain.Category) bool { return c.ID == "b" })
+			if idx < 0 || lib.Categories[idx] != *renamed {
+				t.Errorf("category b = %+v, want %+v", lib.Categories, *renamed)
+			}
+			if text, err := s.Text("t"); err != nil || text.CategoryID != "b" {
+				t.Errorf("Text(t) = %+v, %v; want it to stay in b", text, err)
+			}
+
+			tests := []struct {
+				cat  domain.Category
+				want error
+			}{
+				{domain.Category{ID: "b", Name: "A"}, ErrCategoryExists},
+				{domain.Category{ID: "nope", Name: "Nope"}, ErrCategoryNotFound},
+				{domain.Category{ID: "b", Name: "Beta", ParentID: "gone"}, ErrCategoryNotFound},
+				{domain.Category{ID: "c", Name: "C", ParentID: "b"}, ErrCategoryCycle},
+				{domain.Category{ID: "c", Name: "C", ParentID: "c"}, ErrCategoryCycle},
+				{domain.Category{ID: "c", Name: ""}, ErrEmptyCategoryName},
+			}
+			for _, tc := range tests {
+				if err := s.UpdateCategory(&tc.cat); !errors.Is(err, tc.want) {
+					t.Errorf("UpdateCategory(%+v): expected %v, got %v", tc.cat, tc.want, err)
+				}
+			}
+		})
+	}
+}
+
+func TestStores_MoveCategory(t *testing.T) {
+	for name, open := range backends(t) {
+		t.Run(string(name), func(t *testing.T) {
+			s := open(t).texts
+			for _, c := range []domain.Category{
+				{ID: "a", Name: "A"}, {ID: "b", Name: "B"}, {ID: "c", Name: "C"},
+				{ID: "a1", Name: "A1", ParentID: "a"}, {ID: "a2", Name: "A2", ParentID: "a1"},
+			} {
+				if err := s.SaveCategory(&c); err != nil {
+					t.Fatalf("SaveCategory(%s) error: %v", c.ID, err)
+				}
+			}
+			move := func(id, parentID string, position int) {
+				t.Helper()
+				if err := s.MoveCategory(id, parentID, position); err != nil {
+					t.Fatalf("MoveCategory(%s, %q, %d) error: %v", id, parentID, position, err)
+				}
+			}
+
+			move("c", "", 0)
+			if got, want := childIDs(t, s, ""), []string{"c", "a", "b"}; !slices.Equal(got, want) {
+				t.Errorf("after moving c first: roots = %v, want %v", got, want)
+			}
+			move("a", "", -1)
+			if got, want := childIDs(t, s, ""), []string{"c", "b", "a"}; !slices.Equal(got, want) {
+				t.Errorf("after moving a last: roots = %v, want %v", got, want)
+			}
+			move("a2", "", 1)
+			if got, want := childIDs(t, s, ""), []string{"c", "a2", "b", "a"}; !slices.Equal(got, want) {
+				t.Errorf("after lifting a2: roots = %v, want %v", got, want)
+			}
+			move("b", "a1", 0)
+			if got, want := childIDs(t, s, "a1"), []string{"b"}; !slices.Equal(got, want) {
+				t.Errorf("children of a1 = %v, want %v", got, want)
+			}
+
+			if err := s.MoveCategory("a", "b", 0); !errors.Is(err, ErrCategoryCycle) {
+				t.Errorf("move under descendant: expected ErrCategoryCycle, got %v", err)
+			}
+			if err := s.MoveCategory("nope", "", 0); !errors.Is(err, ErrCategoryNotFound) {
+				t.Errorf("move unknown: expected ErrCategoryNotFound, got %v", err)
+			}
+			if err := s.MoveCategory("a", "gone", 0); !errors.Is(err, ErrCategoryNotFound) {
+				t.Errorf("move under unknown: expected ErrCategoryNotFound, got %v", err)
+			}
+		})
+	}
+}
+
+func TestTextRepository_MoveCategoryPersists(t *testing.T) {
+	mgr := setupManager(t)
+	repo, _ := NewTextRepository(mgr)
+	for _, c := range []domain.Category{{ID: "a", Name: "A"}, {ID: "b", Name: "B"}} {
+		if err := repo.SaveCategory(&c); err != nil {
+			t.Fatalf("SaveCategory(%s) error: %v", c.ID, err)
+		}
+	}
+	if err := repo.MoveCategory("b", "", 0); err != nil {
+		t.Fatalf("MoveCategory() error: %v", err)
+	}
+	reopened, _ := NewTextRepository(mgr)
+	lib, err := reopened.Library()
+	if err != nil {
+		t.Fatalf("Library() error: %v", err)
+	}
+	if got, want := childIDs(t, reopened, ""), []string{"b", "a"}; !slices.Equal(got, want) {
+		t.Errorf("reloaded roots = %v, want %v (categories %+v)", got, want, lib.Categories)
+	}
+}
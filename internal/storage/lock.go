@@ -0,0 +1,108 @@
+// Copyright 2025 Asher Buk
+// SPDX-License-Identifier: Apache-2.0
+// https://github.com/AshBuk/FingerGo
+
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// lockFile is the advisory lock guarding the data root against a second writer.
+const lockFile = "fingergo.lock"
+
+// lockRetryInterval is how often WaitInit retries a busy lock.
+const lockRetryInterval = 200 * time.Millisecond
+
+// Lock errors.
+var (
+	ErrLocked   = errors.New("storage: data directory is in use by another FingerGo process")
+	ErrReadOnly = errors.New("storage: manager is read-only")
+)
+
+// NewReadOnly creates a manager that reads an existing data directory without
+// taking the lock. Writes fail with ErrReadOnly; Init is not needed (and refused).
+// Intended for tools that inspect data while the GUI is running.
+func NewReadOnly(root string) (*Manager, error) {
+	mgr, err := New(root)
+	if err != nil {
+		return nil, err
+	}
+	mgr.readOnly = true
+	return mgr, nil
+}
+
+// WaitInit is Init for non-GUI tools: it retries while another process holds
+// the data directory lock, until ctx is done.
+func (m *Manager) WaitInit(ctx context.Context) error {
+	for {
+		err := m.Init()
+		if !errors.Is(err, ErrLocked) {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w: %w", err, ctx.Err())
+		case <-time.After(lockRetryInterval):
+		}
+	}
+}
+
+// Close releases the data directory lock. Safe to call more than once.
+func (m *Manager) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.lock == nil {
+		return nil
+	}
+	err := unlockFile(m.lock)
+	if closeErr := m.lock.Close(); err == nil {
+		err = closeErr
+	}
+	m.lock = nil
+	if err != nil {
+		return fmt.Errorf("storage: release lock: %w", err)
+	}
+	return nil
+}
+
+// acquireLock takes the exclusive advisory lock on {root}/fingergo.lock.
+// Re-entrant for the same manager; another holder yields ErrLocked.
+// The lock file records the holder's PID for diagnostics.
+func (m *Manager) acquireLock() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.lock != nil {
+		return nil
+	}
+	path := m.join(lockFile)
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0o600)
+	if err != nil {
+		return fmt.Errorf("storage: open lock %q: %w", path, err)
+	}
+	if err := lockFileExclusive(f); err != nil {
+		_ = f.Close()
+		if errors.Is(err, errLockBusy) {
+			return fmt.Errorf("%w: %s", ErrLocked, m.root)
+		}
+		return fmt.Errorf("storage: lock %q: %w", path, err)
+	}
+	if err := f.Truncate(0); err == nil {
+		_, _ = f.WriteAt([]byte(strconv.Itoa(os.Getpid())+"\n"), 0)
+	}
+	m.lock = f
+	return nil
+}
+
+// checkWritable rejects mutations on read-only managers.
+func (m *Manager) checkWritable() error {
+	if m.readOnly {
+		return ErrReadOnly
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+// Copyright 2025 Asher Buk
+// SPDX-License-Identifier: Apache-2.0
+// https://github.com/AshBuk/FingerGo
+
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	domain "github.com/AshBuk/FingerGo/internal/domain"
+)
+
+func TestManager_Lock(t *testing.T) {
+	t.Run("second manager on same root is rejected", func(t *testing.T) {
+		first := setupManager(t)
+		defer first.Close()
+
+		second, _ := New(first.Root())
+		if err := second.Init(); !errors.Is(err, ErrLocked) {
+			t.Fatalf("expected ErrLocked, got %v", err)
+		}
+	})
+
+	t.Run("Init is re-entrant for the holder", func(t *testing.T) {
+		mgr := setupManager(t)
+		defer mgr.Close()
+		if err := mgr.Init(); err != nil {
+			t.Errorf("second Init() error: %v", err)
+		}
+	})
+
+	t.Run("Close releases the lock", func(t *testing.T) {
+		first := setupManager(t)
+		if err := first.Close(); err != nil {
+			t.Fatalf("Close() error: %v", err)
+		}
+		if err := first.Close(); err != nil {
+			t.Errorf("second Close() error: %v", err)
+		}
+		second, _ := New(first.Root())
+		defer second.Close()
+		if err := second.Init(); err != nil {
+			t.Errorf("Init() after Close error: %v", err)
+		}
+	})
+}
+
+func TestManager_WaitInit(t *testing.T) {
+	t.Run("acquires once the holder closes", func(t *testing.T) {
+		first := setupManager(t)
+		go func() {
+			time.Sleep(2 * lockRetryInterval)
+			_ = first.Close()
+		}()
+		second, _ := New(first.Root())
+		defer second.Close()
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := second.WaitInit(ctx); err != nil {
+			t.Fatalf("WaitInit() error: %v", err)
+		}
+	})
+
+	t.Run("gives up when context expires", func(t *testing.T) {
+		first := setupManager(t)
+		defer first.Close()
+		second, _ := New(first.Root())
+		ctx, cancel := context.WithTimeout(context.Background(), lockRetryInterval)
+		defer cancel()
+		err := second.WaitInit(ctx)
+		if !errors.Is(err, ErrLocked) || !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("expected ErrLocked and DeadlineExceeded, got %v", err)
+		}
+	})
+}
+
+func TestNewReadOnly(t *testing.T) {
+	owner := setupManager(t)
+	defer owner.Close()
+	repo, _ := NewTextRepository(owner)
+	if err := repo.SaveText(&domain.Text{ID: "shared", Title: "Shared", Content: "visible", Language: "text"}); err != nil {
+		t.Fatalf("SaveText() error: %v", err)
+	}
+
+	viewer, err := NewReadOnly(owner.Root())
+	if err != nil {
+		t.Fatalf("NewReadOnly() error: %v", err)
+	}
+	if err := viewer.Init(); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("Init() on read-only manager: expected ErrReadOnly, got %v", err)
+	}
+	view, _ := NewTextRepository(viewer)
+	got, err := view.Text("shared")
+	if err != nil || got.Content != "visible" {
+		t.Fatalf("Text() = %q, %v; want data written by the lock holder", got.Content, err)
+	}
+	err = view.SaveText(&domain.Text{ID: "nope", Title: "Nope", Content: "x", Language: "text"})
+	if !errors.Is(err, ErrReadOnly) {
+		t.Errorf("SaveText() on read-only view: expected ErrReadOnly, got %v", err)
+	}
+	if err := view.DeleteText("shared"); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("DeleteText() on read-only view: expected ErrReadOnly, got %v", err)
+	}
+}
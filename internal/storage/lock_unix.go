@@ -0,0 +1,31 @@
+// Copyright 2025 Asher Buk
+// SPDX-License-Identifier: Apache-2.0
+// https://github.com/AshBuk/FingerGo
+
+//go:build !windows
+
+package storage
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// errLockBusy reports that another process already holds the lock.
+var errLockBusy = errors.New("lock busy")
+
+// lockFileExclusive takes a non-blocking flock(2) on f.
+// flock locks belong to the open file description, so a second Manager in
+// the same process conflicts just like a second process does.
+func lockFileExclusive(f *os.File) error {
+	err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+	if errors.Is(err, syscall.EWOULDBLOCK) {
+		return errLockBusy
+	}
+	return err
+}
+
+func unlockFile(f *os.File) error {
+	return syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+}
@@ -0,0 +1,107 @@
+// Copyright 2025 Asher Buk
+// SPDX-License-Identifier: Apache-2.0
+// https://github.com/AshBuk/FingerGo
+
+package storage
+
+import (
+	"time"
+
+	domain "github.com/AshBuk/FingerGo/internal/domain"
+)
+
+// recentWPMSessions is how many recent sessions RecentWPM averages.
+const recentWPMSessions = 10
+
+// TextDifficulty is the difficulty of a text and how long typing it takes.
+type TextDifficulty struct {
+	Metrics          domain.TextMetrics `json:"metrics"`
+	WPM              float64            `json:"wpm"`              // speed the estimate assumes
+	EstimatedSeconds int                `json:"estimatedSeconds"` // time to type the whole text
+	Sessions         int                `json:"sessions"`         // sessions WPM was averaged over; 0 = domain.DefaultWPM
+}
+
+// analyzeText caches the difficulty metrics of text on the layout of n.
+// SaveText and UpdateText call it after normalization.
+func analyzeText(n *Normalizer, text *domain.Text) {
+	m := domain.AnalyzeText(text.Content, n.Layout())
+	text.Metrics = &m
+}
+
+// metricsCurrent reports whether cached metrics were computed for layout
+// with the current formulas.
+func metricsCurrent(m *domain.TextMetrics, layout string) bool {
+	return m != nil && m.Layout == layout && m.Version == domain.MetricsVersion
+}
+
+// staleMetrics returns the positions in texts of the texts whose metrics
+// are not current for layout, and their recomputed metrics. content reads
+// the content of texts[i].
+func staleMetrics(texts []domain.Text, layout string, content func(i int) (string, error)) ([]int, []domain.TextMetrics, error) {
+	var stale []int
+	var metrics []domain.TextMetrics
+	for i := range texts {
+		if metricsCurrent(texts[i].Metrics, layout) {
+			continue
+		}
+		c, err := content(i)
+		if err != nil {
+			return nil, nil, err
+		}
+		stale = append(stale, i)
+		metrics = append(metrics, domain.AnalyzeText(c, layout))
+	}
+	return stale, metrics, nil
+}
+
+// RecentWPM averages the speed of the latest sessions with a speed recorded
+// and returns how many it averaged; (0, 0) without history.
+func RecentWPM(store SessionStore) (float64, int, error) {
+	sessions, err := store.List(recentWPMSessions)
+	if err != nil {
+		return 0, 0, err
+	}
+	var sum float64
+	n := 0
+	for i := range sessions {
+		if sessions[i].WPM > 0 {
+			sum += sessions[i].WPM
+			n++
+		}
+	}
+	if n == 0 {
+		return 0, 0, nil
+	}
+	return sum / float64(n), n, nil
+}
+
+// Difficulty returns the difficulty of text id and the time to type it at
+// wpm averaged over sessions (see RecentWPM). Metrics cached for another
+// layout are computed afresh, without saving them.
+func Difficulty(store TextStore, id string, wpm float64, sessions int) (TextDifficulty, error) {
+	text, err := store.Text(id)
+	if err != nil {
+		return TextDifficulty{}, err
+	}
+	layout := store.Normalizer().Layout()
+	var metrics domain.TextMetrics
+	if metricsCurrent(text.Metrics, layout) {
+		metrics = *text.Metrics
+	} else {
+		metrics = domain.AnalyzeText(text.Content, layout)
+	}
+	if wpm <= 0 {
+		wpm, sessions = domain.DefaultWPM, 0
+	}
+	return TextDifficulty{
+		Metrics:          metrics,
+		WPM:              round1(wpm),
+		EstimatedSeconds: int(domain.EstimateDuration(&metrics, wpm) / time.Second),
+		Sessions:         sessions,
+	}, nil
+}
+
+// round1 rounds to one decimal.
+func round1(v float64) float64 {
+	return float64(int(v*10+0.5)) / 10
+}
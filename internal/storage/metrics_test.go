@@ -0,0 +1,103 @@
+// Copyright 2025 Asher Buk
+// SPDX-License-Identifier: Apache-2.0
+// https://github.com/AshBuk/FingerGo
+
+package storage
+
+import (
+	"errors"
+	"testing"
+
+	domain "github.com/AshBuk/FingerGo/internal/domain"
+)
+
+func TestStores_TextMetrics(t *testing.T) {
+	for name, open := range backends(t) {
+		t.Run(string(name), func(t *testing.T) {
+			s := open(t).texts
+			s.SetNormalizer(NewNormalizer("en-qwerty", nil))
+			text := &domain.Text{ID: "code", Title: "Code", Content: "if (a) {\n\treturn b;\n}"}
+			if err := s.SaveText(text); err != nil {
+				t.Fatalf("SaveText() error: %v", err)
+			}
+			// The seeded default text was saved without metrics
+			if n, err := s.RefreshMetrics(); err != nil || n != 1 {
+				t.Errorf("RefreshMetrics() = %d, %v; want the default text analyzed", n, err)
+			}
+			lib, err := s.Library()
+			if err != nil || len(lib.Texts) != 2 {
+				t.Fatalf("Library() = %+v, %v", lib, err)
+			}
+			if m := lib.Texts[1].Metrics; m == nil || m.Layout != "en-qwerty" || m.Lines != 3 || m.IndentDepth != 1 {
+				t.Fatalf("cached metrics = %+v, want en-qwerty metrics of 3 lines", m)
+			}
+
+			// Another layout: computed on request, cached by RefreshMetrics
+			s.SetNormalizer(NewNormalizer("fr-azerty", nil))
+			d, err := Difficulty(s, "code", 0, 0)
+			if err != nil || d.Metrics.Layout != "fr-azerty" || d.WPM != domain.DefaultWPM || d.EstimatedSeconds <= 0 {
+				t.Errorf("Difficulty() = %+v, %v; want fr-azerty metrics at the default speed", d, err)
+			}
+			if n, err := s.RefreshMetrics(); err != nil || n != 2 {
+				t.Errorf("RefreshMetrics() = %d, %v; want 2", n, err)
+			}
+			if n, err := s.RefreshMetrics(); err != nil || n != 0 {
+				t.Errorf("second RefreshMetrics() = %d, %v; want 0", n, err)
+			}
+			if got, err := s.Text("code"); err != nil || got.Metrics == nil || got.Metrics.Layout != "fr-azerty" {
+				t.Errorf("Text() metrics = %+v, %v; want fr-azerty", got.Metrics, err)
+			}
+
+			text.Content = "plain words"
+			if err := s.UpdateText(text); err != nil {
+				t.Fatalf("UpdateText() error: %v", err)
+			}
+			if got, _ := s.Text("code"); got.Metrics == nil || got.Metrics.Lines != 1 || got.Metrics.Symbols != 0 {
+				t.Errorf("metrics after update = %+v, want the new content's", got.Metrics)
+			}
+			if _, err := Difficulty(s, "missing", 0, 0); !errors.Is(err, ErrTextNotFound) {
+				t.Errorf("Difficulty(missing) error = %v, want ErrTextNotFound", err)
+			}
+		})
+	}
+}
+
+func TestTextRepository_MetricsPersist(t *testing.T) {
+	mgr := setupManager(t)
+	repo, _ := NewTextRepository(mgr)
+	repo.SetNormalizer(NewNormalizer("ru-jcuken", nil))
+	if err := repo.SaveText(&domain.Text{ID: "ru", Title: "Ru", Content: "Привет, мир!"}); err != nil {
+		t.Fatalf("SaveText() error: %v", err)
+	}
+	if _, err := repo.RefreshMetrics(); err != nil {
+		t.Fatalf("RefreshMetrics() error: %v", err)
+	}
+	reopened, _ := NewTextRepository(mgr)
+	reopened.SetNormalizer(NewNormalizer("ru-jcuken", nil))
+	if n, err := reopened.RefreshMetrics(); err != nil || n != 0 {
+		t.Errorf("RefreshMetrics() after reload = %d, %v; want the cached metrics kept", n, err)
+	}
+	text, err := reopened.Text("ru")
+	if err != nil || text.Metrics == nil || text.Metrics.Shift == 0 {
+		t.Errorf("Text() metrics = %+v, %v; want ru-jcuken metrics with Shift", text.Metrics, err)
+	}
+}
+
+func TestRecentWPM(t *testing.T) {
+	for name, open := range backends(t) {
+		t.Run(string(name), func(t *testing.T) {
+			s := open(t).sessions
+			if wpm, n, err := RecentWPM(s); err != nil || wpm != 0 || n != 0 {
+				t.Errorf("RecentWPM(no sessions) = %v, %d, %v", wpm, n, err)
+			}
+			for _, wpm := range []float64{100, 0, 40, 50} {
+				if _, err := s.Record(&domain.SessionPayload{WPM: wpm}); err != nil {
+					t.Fatalf("Record() error: %v", err)
+				}
+			}
+			if wpm, n, err := RecentWPM(s); err != nil || n != 3 || wpm < 63.3 || wpm > 63.4 {
+				t.Errorf("RecentWPM() = %v, %d, %v; want 63.3 over 3 sessions", wpm, n, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,166 @@
+// Copyright 2025 Asher Buk
+// SPDX-License-Identifier: Apache-2.0
+// https://github.com/AshBuk/FingerGo
+
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+
+	domain "github.com/AshBuk/FingerGo/internal/domain"
+)
+
+// migration upgrades one document from schema version to-1 to version to.
+// apply receives the bare payload and returns the upgraded payload.
+type migration struct {
+	apply func(data json.RawMessage) (json.RawMessage, error)
+	file  string // relative path of the document
+	name  string // short description for logs
+	to    int    // schema version produced
+}
+
+// migrations is the ordered registry of schema upgrades.
+// Append new steps at the end; never edit or reorder released ones.
+var migrations = []migration{
+	{file: textsIndexFile, to: 1, name: "wrap library in schema envelope", apply: keepPayload},
+	{file: sessionsFile, to: 1, name: "wrap sessions in schema envelope", apply: keepPayload},
+	{file: configFile, to: 1, name: "default missing text zoom", apply: migrateSettingsTextZoom},
+	{file: textsIndexFile, to: 2, name: "add category sort order", apply: keepPayload},
+	{file: textsIndexFile, to: 3, name: "add text revisions", apply: keepPayload},
+	{file: textsIndexFile, to: 4, name: "add text segmentation", apply: keepPayload},
+	{file: textsIndexFile, to: 5, name: "add text metrics", apply: keepPayload},
+	{file: configFile, to: 2, name: "default missing trash retention", apply: migrateSettingsTrashRetention},
+}
+
+// schemaVersion returns the current (latest) schema version of a document.
+func schemaVersion(relPath string) int {
+	version := 0
+	for _, m := range migrations {
+		if m.file == relPath && m.to > version {
+			version = m.to
+		}
+	}
+	return version
+}
+
+// upgradeDocument applies pending migrations to env in order.
+// before, if set, runs ahead of each step (used to back up the file on disk).
+func upgradeDocument(relPath string, env envelope, before func(step migration) error) (json.RawMessage, int, error) {
+	current := schemaVersion(relPath)
+	if env.SchemaVersion > current {
+		return nil, env.SchemaVersion, fmt.Errorf("%w: %s has schema %d, supported %d",
+			ErrSchemaTooNew, relPath, env.SchemaVersion, current)
+	}
+	data, version := env.Data, env.SchemaVersion
+	for _, step := range migrations {
+		if step.file != relPath || step.to != version+1 {
+			continue
+		}
+		if before != nil {
+			if err := before(step); err != nil {
+				return nil, version, err
+			}
+		}
+		upgraded, err := step.apply(data)
+		if err != nil {
+			return nil, version, fmt.Errorf("storage: migrate %s to v%d (%s): %w", relPath, step.to, step.name, err)
+		}
+		data, version = upgraded, step.to
+	}
+	if version != current {
+		return nil, version, fmt.Errorf("storage: no migration path for %s from v%d to v%d", relPath, version, current)
+	}
+	return data, version, nil
+}
+
+// migrate upgrades every versioned document on disk to its current schema.
+// The first step is preceded by a copy of the file as {name}.v{from}.bak.
+// Unparsable files are skipped here and handled by repository recovery;
+// files from a newer version are left alone and reported by the repositories.
+func (m *Manager) migrate() error {
+	for _, relPath := range []string{textsIndexFile, sessionsFile, configFile} {
+		if err := m.migrateDocument(relPath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *Manager) migrateDocument(relPath string) error {
+	raw, err := os.ReadFile(m.join(relPath))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("storage: read %q: %w", relPath, err)
+	}
+	env, err := readEnvelope(raw)
+	if err != nil || !json.Valid(env.Data) {
+		return nil //nolint:nilerr // unparsable files are recovered by the repositories
+	}
+	if env.SchemaVersion >= schemaVersion(relPath) {
+		return nil
+	}
+	from := env.SchemaVersion
+	backedUp := false
+	data, version, err := upgradeDocument(relPath, env, func(step migration) error {
+		// Later steps only rewrite the payload in memory: one backup of the
+		// original file is enough
+		if !backedUp {
+			backup := fmt.Sprintf("%s.v%d%s", relPath, from, backupSuffix)
+			if err := copyFile(m.join(relPath), m.join(backup)); err != nil {
+				return fmt.Errorf("storage: back up %q before migration: %w", relPath, err)
+			}
+			backedUp = true
+		}
+		log.Printf("storage: migrating %s v%d → v%d: %s", relPath, step.to-1, step.to, step.name)
+		return nil
+	})
+	if err != nil {
+		// Payload the migration cannot understand is treated like corruption on load
+		m.warnf("%s could not be upgraded from schema v%d (%v)", relPath, from, err)
+		return nil
+	}
+	out, err := marshalEnvelope(envelope{SchemaVersion: version, Data: data})
+	if err != nil {
+		return err
+	}
+	if err := m.writeFileBackup(relPath, out); err != nil {
+		return fmt.Errorf("storage: write migrated %q (from v%d): %w", relPath, from, err)
+	}
+	return nil
+}
+
+// keepPayload is a no-op step for migrations that only change the envelope.
+func keepPayload(data json.RawMessage) (json.RawMessage, error) {
+	return data, nil
+}
+
+// migrateSettingsTextZoom fills textZoom for settings saved before zoom existed.
+func migrateSettingsTextZoom(data json.RawMessage) (json.RawMessage, error) {
+	var fields map[string]any
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	if zoom, ok := fields["textZoom"].(float64); !ok || zoom == 0 {
+		fields["textZoom"] = 1.0
+	}
+	return json.Marshal(fields)
+}
+
+// migrateSettingsTrashRetention fills trashRetentionDays for settings saved
+// before the trash was purged; left at 0 it would keep the trash forever.
+func migrateSettingsTrashRetention(data json.RawMessage) (json.RawMessage, error) {
+	var fields map[string]any
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	if _, ok := fields["trashRetentionDays"]; !ok {
+		fields["trashRetentionDays"] = domain.DefaultSettings().TrashRetentionDays
+	}
+	return json.Marshal(fields)
+}
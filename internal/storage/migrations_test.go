@@ -0,0 +1,197 @@
+// Copyright 2025 Asher Buk
+// SPDX-License-Identifier: Apache-2.0
+// https://github.com/AshBuk/FingerGo
+
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	domain "github.com/AshBuk/FingerGo/internal/domain"
+)
+
+// writeRaw places a file in the data directory, bypassing the repositories.
+func writeRaw(t *testing.T, mgr *Manager, relPath, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(mgr.Root(), relPath), []byte(content), 0o600); err != nil {
+		t.Fatalf("WriteFile() error: %v", err)
+	}
+}
+
+// readVersion returns the schemaVersion stamped on a document.
+func readVersion(t *testing.T, mgr *Manager, relPath string) int {
+	t.Helper()
+	raw, err := os.ReadFile(filepath.Join(mgr.Root(), relPath))
+	if err != nil {
+		t.Fatalf("ReadFile() error: %v", err)
+	}
+	env, err := readEnvelope(raw)
+	if err != nil {
+		t.Fatalf("readEnvelope() error: %v", err)
+	}
+	return env.SchemaVersion
+}
+
+func TestManager_InitSeedsCurrentSchema(t *testing.T) {
+	mgr := setupManager(t)
+	if got, want := readVersion(t, mgr, textsIndexFile), schemaVersion(textsIndexFile); got != want {
+		t.Errorf("got schema v%d, want v%d", got, want)
+	}
+	if _, err := os.Stat(filepath.Join(mgr.Root(), textsIndexFile+".v0"+backupSuffix)); !os.IsNotExist(err) {
+		t.Error("fresh install should not take a migration backup")
+	}
+}
+
+func TestManager_MigratesLegacyFiles(t *testing.T) {
+	mgr := setupManager(t)
+	legacySettings := `{"theme":"light","keyboardLayout":"en-dvorak"}`
+	writeRaw(t, mgr, configFile, legacySettings)
+	writeRaw(t, mgr, sessionsFile, `[{"id":"s1","wpm":50}]`)
+	_ = os.Remove(filepath.Join(mgr.Root(), sessionsJournalFile))
+
+	if err := mgr.Init(); err != nil {
+		t.Fatalf("Init() error: %v", err)
+	}
+
+	if got := readVersion(t, mgr, configFile); got != schemaVersion(configFile) {
+		t.Errorf("got settings schema v%d after Init", got)
+	}
+	backup, err := os.ReadFile(filepath.Join(mgr.Root(), configFile+".v0"+backupSuffix))
+	if err != nil {
+		t.Fatalf("migration backup missing: %v", err)
+	}
+	if string(backup) != legacySettings {
+		t.Errorf("backup = %q, want original legacy content", backup)
+	}
+
+	settingsRepo, _ := NewSettingsRepository(mgr)
+	settings, err := settingsRepo.Load()
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if settings.Theme != "light" || settings.KeyboardLayout != "en-dvorak" {
+		t.Errorf("fields lost in migration: %+v", settings)
+	}
+	if settings.TextZoom != 1.0 {
+		t.Errorf("got TextZoom %v, want 1.0 default", settings.TextZoom)
+	}
+	if want := domain.DefaultSettings().TrashRetentionDays; settings.TrashRetentionDays != want {
+		t.Errorf("got TrashRetentionDays %d, want %d default", settings.TrashRetentionDays, want)
+	}
+
+	sessionsRepo, _ := NewSessionRepository(mgr)
+	sessions, _ := sessionsRepo.List(0)
+	if len(sessions) != 1 || sessions[0].ID != "s1" {
+		t.Errorf("sessions lost in migration: %+v", sessions)
+	}
+	if _, err := os.Stat(filepath.Join(mgr.Root(), sessionsFile+".v1"+backupSuffix)); err != nil {
+		t.Errorf("legacy sessions file should be retired as a backup: %v", err)
+	}
+}
+
+func TestManager_MigrationBacksUpOnce(t *testing.T) {
+	mgr := setupManager(t)
+	legacy := `{"texts":[],"categories":[]}`
+	writeRaw(t, mgr, textsIndexFile, legacy)
+	if err := mgr.Init(); err != nil {
+		t.Fatalf("Init() error: %v", err)
+	}
+	backup, err := os.ReadFile(filepath.Join(mgr.Root(), textsIndexFile+".v0"+backupSuffix))
+	if err != nil || string(backup) != legacy {
+		t.Fatalf("v0 backup = %q, %v; want the original file", backup, err)
+	}
+	for v := 1; v < schemaVersion(textsIndexFile); v++ {
+		name := fmt.Sprintf("%s.v%d%s", textsIndexFile, v, backupSuffix)
+		if _, err := os.Stat(filepath.Join(mgr.Root(), name)); !os.IsNotExist(err) {
+			t.Errorf("%s exists; want a single backup before the first step", name)
+		}
+	}
+}
+
+func TestDecodeDocument(t *testing.T) {
+	t.Run("upgrades legacy payload in memory", func(t *testing.T) {
+		var fields map[string]any
+		if err := decodeDocument(configFile, []byte(`{"theme":"dark"}`), &fields); err != nil {
+			t.Fatalf("decodeDocument() error: %v", err)
+		}
+		if fields["textZoom"] != 1.0 {
+			t.Errorf("got textZoom %v, want 1.0", fields["textZoom"])
+		}
+	})
+
+	t.Run("keeps an explicit trash retention", func(t *testing.T) {
+		var fields map[string]any
+		data := []byte(`{"schemaVersion": 1, "data": {"textZoom": 1, "trashRetentionDays": 0}}`)
+		if err := decodeDocument(configFile, data, &fields); err != nil {
+			t.Fatalf("decodeDocument() error: %v", err)
+		}
+		if fields["trashRetentionDays"] != 0.0 {
+			t.Errorf("got trashRetentionDays %v, want 0 kept", fields["trashRetentionDays"])
+		}
+	})
+
+	t.Run("rejects newer schema", func(t *testing.T) {
+		var fields map[string]any
+		err := decodeDocument(configFile, []byte(`{"schemaVersion": 99, "data": {}}`), &fields)
+		if !errors.Is(err, ErrSchemaTooNew) {
+			t.Errorf("expected ErrSchemaTooNew, got %v", err)
+		}
+	})
+
+	t.Run("round-trips through encodeDocument", func(t *testing.T) {
+		out, err := encodeDocument(sessionsFile, []string{"a"})
+		if err != nil {
+			t.Fatalf("encodeDocument() error: %v", err)
+		}
+		var got []string
+		if err := decodeDocument(sessionsFile, out, &got); err != nil {
+			t.Fatalf("decodeDocument() error: %v", err)
+		}
+		if len(got) != 1 || got[0] != "a" {
+			t.Errorf("got %v, want [a]", got)
+		}
+	})
+}
+
+func TestSettingsRepository_KeepsNewerSchemaFile(t *testing.T) {
+	mgr := setupManager(t)
+	newer := `{"schemaVersion": 99, "data": {"theme": "light"}}`
+	writeRaw(t, mgr, configFile, newer)
+
+	repo, _ := NewSettingsRepository(mgr)
+	if _, err := repo.Load(); !errors.Is(err, ErrSchemaTooNew) {
+		t.Fatalf("expected ErrSchemaTooNew, got %v", err)
+	}
+	if err := repo.Update("theme", "dark"); err == nil {
+		t.Error("Update() must not overwrite a file from a newer version")
+	}
+	data, _ := os.ReadFile(filepath.Join(mgr.Root(), configFile))
+	if string(data) != newer {
+		t.Errorf("newer file was modified: %q", data)
+	}
+}
+
+func TestMigrationsRegistry(t *testing.T) {
+	// Every document must have a gap-free chain starting at v1
+	seen := make(map[string]map[int]bool)
+	for _, m := range migrations {
+		if seen[m.file] == nil {
+			seen[m.file] = make(map[int]bool)
+		}
+		if seen[m.file][m.to] {
+			t.Errorf("%s: duplicate migration to v%d", m.file, m.to)
+		}
+		seen[m.file][m.to] = true
+	}
+	for file, versions := range seen {
+		for v := 1; v <= schemaVersion(file); v++ {
+			if !versions[v] {
+				t.Errorf("%s: missing migration to v%d", file, v)
+			}
+		}
+	}
+}
@@ -0,0 +1,357 @@
+// Copyright 2025 Asher Buk
+// SPDX-License-Identifier: Apache-2.0
+// https://github.com/AshBuk/FingerGo
+
+package storage
+
+import (
+	"fmt"
+	"maps"
+	"strings"
+
+	domain "github.com/AshBuk/FingerGo/internal/domain"
+)
+
+// Normalization limits.
+const (
+	maxUntypeableReported = 20 // distinct characters listed in a NormalizeReport
+	maxTabWidth           = 16 // widest tab stop accepted in NormalizeRules
+)
+
+// NormalizeRule names one step of the content normalization pipeline.
+type NormalizeRule string
+
+// Normalization steps, in the order they run.
+const (
+	RuleLineEndings   NormalizeRule = "lineEndings"   // CRLF and lone CR become LF
+	RuleEmoji         NormalizeRule = "emoji"         // emoji and pictographs are dropped
+	RuleSpaces        NormalizeRule = "spaces"        // no-break and other Unicode spaces become spaces; invisible characters are dropped
+	RulePunctuation   NormalizeRule = "punctuation"   // curly quotes, dashes and ellipses become ASCII
+	RuleTabs          NormalizeRule = "tabs"          // tabs become spaces up to the next tab stop
+	RuleTrailingSpace NormalizeRule = "trailingSpace" // spaces and tabs at line ends are dropped
+)
+
+// NormalizeRules selects the normalization steps applied to a text's
+// content; TabWidth enables RuleTabs, the flags the other rules. Overrides
+// are saved in domain.Settings.NormalizeRules.
+type NormalizeRules = domain.NormalizeRules
+
+// languageTabWidths lists the languages whose tabs are converted to spaces by
+// default: YAML forbids tab indentation and Python style uses spaces. Every
+// other language (Go, Makefile-style Bash, plain text) keeps its tabs.
+var languageTabWidths = map[string]int{
+	"yaml": 2,
+	"py":   4,
+}
+
+// DefaultNormalizeRules returns the rules applied to texts of language when a
+// Normalizer has no override for it. Emoji and typographic punctuation are
+// only rewritten in prose: in code they are usually meant literally, e.g. in
+// string literals.
+func DefaultNormalizeRules(language string) NormalizeRules {
+	prose := domain.IsProseLanguage(language)
+	return NormalizeRules{
+		TabWidth:      languageTabWidths[language],
+		LineEndings:   true,
+		Emoji:         prose,
+		Spaces:        true,
+		Punctuation:   prose,
+		TrailingSpace: true,
+	}
+}
+
+// WithNormalizeRules returns a copy of overrides (language key → rules, as
+// in domain.Settings.NormalizeRules) with the rules of language replaced, or
+// removed when rules is nil so the defaults apply again.
+func WithNormalizeRules(overrides map[string]NormalizeRules, language string, rules *NormalizeRules) (map[string]NormalizeRules, error) {
+	if !domain.IsValidLanguage(language) {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidLanguage, language)
+	}
+	out := maps.Clone(overrides)
+	if rules == nil {
+		delete(out, language)
+		return out, nil
+	}
+	if rules.TabWidth < 0 || rules.TabWidth > maxTabWidth {
+		return nil, fmt.Errorf("storage: tab width must be in [0, %d]: %d", maxTabWidth, rules.TabWidth)
+	}
+	if out == nil {
+		out = make(map[string]NormalizeRules, 1)
+	}
+	out[language] = *rules
+	return out, nil
+}
+
+// NormalizeChange counts what one step changed.
+type NormalizeChange struct {
+	Rule  NormalizeRule `json:"rule"`
+	Count int           `json:"count"` // characters replaced or dropped; lines for RuleTrailingSpace
+}
+
+// UntypeableChar is a character the keyboard layout cannot type.
+type UntypeableChar struct {
+	Char  string `json:"char"`
+	Count int    `json:"count"` // occurrences in the content
+	Line  int    `json:"line"`  // first line it appears on (1-based)
+}
+
+// NormalizeReport tells what normalization changed in a text and which of
+// the remaining characters cannot be typed on the keyboard layout.
+type NormalizeReport struct {
+	Layout     string            `json:"layout,omitempty"`     // layout checked; empty when unknown
+	Changes    []NormalizeChange `json:"changes,omitempty"`    // steps that changed something, in order
+	Untypeable []UntypeableChar  `json:"untypeable,omitempty"` // in order of appearance, at most maxUntypeableReported
+}
+
+// Empty reports whether nothing was changed and nothing is untypeable.
+func (r *NormalizeReport) Empty() bool {
+	return len(r.Changes) == 0 && len(r.Untypeable) == 0
+}
+
+// Normalizer is the content normalization of a TextStore: per-language rules
+// plus the keyboard layout untypeable characters are checked against. A nil
+// Normalizer applies DefaultNormalizeRules and checks no layout.
+type Normalizer struct {
+	rules  map[string]NormalizeRules // language → rules overriding the defaults
+	layout string                    // domain.Settings.KeyboardLayout
+}
+
+// NewNormalizer returns a Normalizer that checks characters against layout
+// and applies rules (by language key) instead of DefaultNormalizeRules.
+func NewNormalizer(layout string, rules map[string]NormalizeRules) *Normalizer {
+	return &Normalizer{rules: maps.Clone(rules), layout: layout}
+}
+
+// Rules returns the rules applied to texts of language.
+func (n *Normalizer) Rules(language string) NormalizeRules {
+	if n != nil {
+		if rules, ok := n.rules[language]; ok {
+			return rules
+		}
+	}
+	return DefaultNormalizeRules(language)
+}
+
+// Layout returns the keyboard layout untypeable characters are checked
+// against; empty for the nil Normalizer.
+func (n *Normalizer) Layout() string {
+	if n == nil {
+		return ""
+	}
+	return n.layout
+}
+
+// Normalize rewrites text.Content by the rules of its language and reports
+// the changes and the characters the layout cannot type. Normalizing twice
+// changes nothing the second time.
+func (n *Normalizer) Normalize(text *domain.Text) NormalizeReport {
+	language := text.Language
+	if language == "" {
+		language = defaultLanguage
+	}
+	var report NormalizeReport
+	text.Content, report.Changes = NormalizeContent(text.Content, n.Rules(language))
+	if n != nil && domain.IsKnownLayout(n.layout) {
+		report.Layout = n.layout
+		report.Untypeable = untypeableChars(text.Content, n.layout)
+	}
+	return report
+}
+
+// NormalizeContent runs the enabled steps of rules over content.
+func NormalizeContent(content string, rules NormalizeRules) (string, []NormalizeChange) {
+	var changes []NormalizeChange
+	step := func(rule NormalizeRule, enabled bool, fn func(string) (string, int)) {
+		if !enabled {
+			return
+		}
+		var n int
+		if content, n = fn(content); n > 0 {
+			changes = append(changes, NormalizeChange{Rule: rule, Count: n})
+		}
+	}
+	step(RuleLineEndings, rules.LineEndings, normalizeLineEndings)
+	step(RuleEmoji, rules.Emoji, dropEmoji)
+	step(RuleSpaces, rules.Spaces, normalizeSpaces)
+	step(RulePunctuation, rules.Punctuation, asciiPunctuation)
+	step(RuleTabs, rules.TabWidth > 0, func(s string) (string, int) { return expandTabs(s, rules.TabWidth) })
+	step(RuleTrailingSpace, rules.TrailingSpace, trimTrailingSpace)
+	return content, changes
+}
+
+// normalizeLineEndings converts CRLF and lone CR to LF.
+func normalizeLineEndings(s string) (string, int) {
+	n := strings.Count(s, "\r")
+	if n == 0 {
+		return s, 0
+	}
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	return strings.ReplaceAll(s, "\r", "\n"), n
+}
+
+// isEmoji reports whether r is a pictograph: the emoji and symbol blocks,
+// regional indicators (flags) and skin tone modifiers.
+func isEmoji(r rune) bool {
+	return r >= 0x1F000 && r <= 0x1FAFF || r >= 0x2600 && r <= 0x27BF || r >= 0x2B00 && r <= 0x2BFF
+}
+
+// isEmojiModifier reports whether r only changes how a pictograph is drawn:
+// variation selectors, the keycap mark and tag characters.
+func isEmojiModifier(r rune) bool {
+	return r == 0xFE0E || r == 0xFE0F || r == 0x20E3 || r >= 0xE0020 && r <= 0xE007F
+}
+
+// dropEmoji removes pictographs with their modifiers and the zero-width
+// joiners that combine them. It counts pictographs and stray modifiers.
+func dropEmoji(s string) (string, int) {
+	if !strings.ContainsFunc(s, func(r rune) bool { return isEmoji(r) || isEmojiModifier(r) }) {
+		return s, 0
+	}
+	var b strings.Builder
+	b.Grow(len(s))
+	n := 0
+	joining := false // inside an emoji sequence, where U+200D joins pictographs
+	for _, r := range s {
+		switch {
+		case isEmoji(r):
+			n++
+			joining = true
+		case isEmojiModifier(r), r == 0x200D && joining:
+			if !joining {
+				n++ // a modifier of a character that stays, such as ©️
+			}
+		default:
+			joining = false
+			b.WriteRune(r)
+		}
+	}
+	return b.String(), n
+}
+
+// spaceReplacement maps Unicode spaces to an ASCII space and invisible
+// characters (zero-width spaces, word joiners, byte order marks, soft
+// hyphens) to nothing. Zero-width joiners and non-joiners (U+200C, U+200D)
+// stay: scripts such as Persian and Devanagari need them to spell words
+// correctly. Those inside emoji sequences go with RuleEmoji.
+func spaceReplacement(r rune) (string, bool) {
+	switch {
+	case r == 0x00A0, r >= 0x2000 && r <= 0x200A, r == 0x202F, r == 0x205F, r == 0x3000:
+		return " ", true
+	case r == 0x200B, r == 0x2060, r == 0xFEFF, r == 0x00AD:
+		return "", true
+	}
+	return "", false
+}
+
+// punctuationReplacements maps typographic punctuation to what a keyboard
+// types.
+var punctuationReplacements = map[rune]string{
+	'‘': "'", '’': "'", '‚': "'", '‛': "'", '′': "'",
+	'“': `"`, '”': `"`, '„': `"`, '‟': `"`, '″': `"`,
+	'‐': "-", '‑': "-", '‒': "-", '–': "-", '—': "-", '―': "-", '−': "-",
+	'…': "...",
+}
+
+// normalizeSpaces applies spaceReplacement.
+func normalizeSpaces(s string) (string, int) {
+	return replaceRunes(s, spaceReplacement)
+}
+
+// asciiPunctuation applies punctuationReplacements.
+func asciiPunctuation(s string) (string, int) {
+	return replaceRunes(s, func(r rune) (string, bool) {
+		repl, ok := punctuationReplacements[r]
+		return repl, ok
+	})
+}
+
+// replaceRunes replaces each rune for which repl reports true and counts the
+// replacements.
+func replaceRunes(s string, repl func(r rune) (string, bool)) (string, int) {
+	if !strings.ContainsFunc(s, func(r rune) bool { _, ok := repl(r); return ok }) {
+		return s, 0
+	}
+	var b strings.Builder
+	b.Grow(len(s))
+	n := 0
+	for _, r := range s {
+		if out, ok := repl(r); ok {
+			b.WriteString(out)
+			n++
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String(), n
+}
+
+// expandTabs replaces tabs with spaces up to the next multiple of width
+// (columns count runes) and counts the tabs.
+func expandTabs(s string, width int) (string, int) {
+	n := strings.Count(s, "\t")
+	if n == 0 {
+		return s, 0
+	}
+	var b strings.Builder
+	b.Grow(len(s) + n*(width-1))
+	col := 0
+	for _, r := range s {
+		switch r {
+		case '\t':
+			pad := width - col%width
+			b.WriteString(strings.Repeat(" ", pad))
+			col += pad
+		case '\n':
+			b.WriteRune(r)
+			col = 0
+		default:
+			b.WriteRune(r)
+			col++
+		}
+	}
+	return b.String(), n
+}
+
+// trimTrailingSpace drops spaces and tabs at the end of every line and
+// counts the lines changed.
+func trimTrailingSpace(s string) (string, int) {
+	lines := strings.Split(s, "\n")
+	n := 0
+	for i, line := range lines {
+		if trimmed := strings.TrimRight(line, " \t"); len(trimmed) != len(line) {
+			lines[i] = trimmed
+			n++
+		}
+	}
+	if n == 0 {
+		return s, 0
+	}
+	return strings.Join(lines, "\n"), n
+}
+
+// untypeableChars lists the characters of content that layout cannot type,
+// in order of first appearance.
+func untypeableChars(content, layout string) []UntypeableChar {
+	var out []UntypeableChar
+	index := make(map[rune]int) // rune → position in out
+	line := 1
+	for _, r := range content {
+		if r == '\n' {
+			line++
+			continue
+		}
+		if domain.CanType(layout, r) {
+			continue
+		}
+		if i, seen := index[r]; seen {
+			out[i].Count++
+			continue
+		}
+		if len(out) == maxUntypeableReported {
+			continue
+		}
+		index[r] = len(out)
+		out = append(out, UntypeableChar{Char: string(r), Count: 1, Line: line})
+	}
+	return out
+}
@@ -0,0 +1,124 @@
+// Copyright 2025 Asher Buk
+// SPDX-License-Identifier: Apache-2.0
+// https://github.com/AshBuk/FingerGo
+
+package storage
+
+import (
+	"slices"
+	"testing"
+
+	domain "github.com/AshBuk/FingerGo/internal/domain"
+)
+
+func TestNormalizeContent(t *testing.T) {
+	tests := []struct {
+		name     string
+		language string
+		in, want string
+		rules    []NormalizeRule
+	}{
+		{"clean text is untouched", "text", "a b\n\tc\n", "a b\n\tc\n", nil},
+		{"line endings", "text", "a\r\nb\rc", "a\nb\nc", []NormalizeRule{RuleLineEndings}},
+		{"trailing space", "text", "a  \nb\t\nc", "a\nb\nc", []NormalizeRule{RuleTrailingSpace}},
+		{"unicode spaces", "text", "a\u00a0b\u200bc\ufeff", "a bc", []NormalizeRule{RuleSpaces}},
+		{"joiners outside emoji", "hindi", "क्\u200dष a\u200cb", "क्\u200dष a\u200cb", nil},
+		{"smart punctuation", "english", "“It’s” — fine…", `"It's" - fine...`, []NormalizeRule{RulePunctuation}},
+		{"emoji sequences", "text", "ok 👍🏽 go 👩‍💻!", "ok  go !", []NormalizeRule{RuleEmoji}},
+		{"code keeps emoji and punctuation", "js", "s = \"👍 “x” — y\"", "s = \"👍 “x” — y\"", nil},
+		{"stray variation selector", "text", "©\ufe0f 2025", "© 2025", []NormalizeRule{RuleEmoji}},
+		{"go keeps tabs", "go", "func f() {\n\treturn\n}", "func f() {\n\treturn\n}", nil},
+		{"yaml converts tabs", "yaml", "a:\n\tb: 1\nc:\t2", "a:\n  b: 1\nc:  2", []NormalizeRule{RuleTabs}},
+		{"tabs then trailing space", "py", "x = 1\t\r\n", "x = 1\n", []NormalizeRule{RuleLineEndings, RuleTabs, RuleTrailingSpace}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, changes := NormalizeContent(tc.in, DefaultNormalizeRules(tc.language))
+			if got != tc.want {
+				t.Errorf("content = %q, want %q", got, tc.want)
+			}
+			var rules []NormalizeRule
+			for _, c := range changes {
+				rules = append(rules, c.Rule)
+			}
+			if !slices.Equal(rules, tc.rules) {
+				t.Errorf("changes = %+v, want rules %v", changes, tc.rules)
+			}
+			if again, changes := NormalizeContent(got, DefaultNormalizeRules(tc.language)); again != got || changes != nil {
+				t.Errorf("second pass changed %q to %q (%+v)", got, again, changes)
+			}
+		})
+	}
+}
+
+func TestNormalizer_Normalize(t *testing.T) {
+	text := &domain.Text{Content: "Grüße\t\nпривет «мир»\n", Language: "german"}
+	report := NewNormalizer("en-qwerty", nil).Normalize(text)
+	if text.Content != "Grüße\nпривет «мир»\n" {
+		t.Errorf("content = %q", text.Content)
+	}
+	if len(report.Changes) != 1 || report.Changes[0] != (NormalizeChange{Rule: RuleTrailingSpace, Count: 1}) {
+		t.Errorf("changes = %+v, want one trailing space line", report.Changes)
+	}
+	var chars []string
+	for _, u := range report.Untypeable {
+		chars = append(chars, u.Char)
+	}
+	if want := []string{"ü", "ß", "п", "р", "и", "в", "е", "т", "«", "м", "»"}; !slices.Equal(chars, want) {
+		t.Errorf("untypeable = %v, want %v", chars, want)
+	}
+	if u := report.Untypeable[2]; u.Line != 2 || u.Count != 1 {
+		t.Errorf("untypeable п = %+v, want line 2, count 1", u)
+	}
+
+	// German letters are typeable on the German layout
+	report = NewNormalizer("de-qwertz", nil).Normalize(&domain.Text{Content: "Grüße", Language: "german"})
+	if !report.Empty() || report.Layout != "de-qwertz" {
+		t.Errorf("de-qwertz report = %+v, want empty", report)
+	}
+	// Unknown layouts and the nil Normalizer skip the check
+	for _, n := range []*Normalizer{NewNormalizer("xx-unknown", nil), nil} {
+		if report := n.Normalize(&domain.Text{Content: "привет"}); !report.Empty() {
+			t.Errorf("report without a layout = %+v, want empty", report)
+		}
+	}
+
+	// Per-language overrides replace the defaults
+	n := NewNormalizer("", map[string]NormalizeRules{"go": {TabWidth: 4}})
+	text = &domain.Text{Content: "\tx  ", Language: "go"}
+	n.Normalize(text)
+	if text.Content != "    x  " {
+		t.Errorf("overridden go content = %q, want tabs expanded and trailing space kept", text.Content)
+	}
+}
+
+func TestStores_SaveTextNormalizes(t *testing.T) {
+	for name, open := range backends(t) {
+		t.Run(string(name), func(t *testing.T) {
+			s := open(t).texts
+			text := &domain.Text{ID: "pasted", Title: "Pasted", Content: "key:\r\n\tvalue: “x”  \r\n", Language: "yaml"}
+			if err := s.SaveText(text); err != nil {
+				t.Fatalf("SaveText() error: %v", err)
+			}
+			const want = "key:\n  value: “x”\n"
+			if stored, _ := s.Text("pasted"); stored.Content != want || text.Content != want {
+				t.Errorf("stored content = %q (caller sees %q), want %q", stored.Content, text.Content, want)
+			}
+
+			s.SetNormalizer(NewNormalizer("", map[string]NormalizeRules{"yaml": {}}))
+			text.Content = "key:\r\n\tvalue\r\n"
+			if err := s.UpdateText(text); err != nil {
+				t.Fatalf("UpdateText() error: %v", err)
+			}
+			if stored, _ := s.Text("pasted"); stored.Content != "key:\r\n\tvalue\r\n" {
+				t.Errorf("content with normalization off = %q, want it unchanged", stored.Content)
+			}
+
+			// Content that normalizes to nothing is rejected like empty content
+			s.SetNormalizer(nil)
+			if err := s.SaveText(&domain.Text{ID: "blank", Title: "Blank", Content: "\u00a0\u200b"}); err == nil {
+				t.Error("SaveText(only invisible characters): expected an error")
+			}
+		})
+	}
+}
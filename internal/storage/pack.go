@@ -0,0 +1,604 @@
+// Copyright 2025 Asher Buk
+// SPDX-License-Identifier: Apache-2.0
+// https://github.com/AshBuk/FingerGo
+
+package storage
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	domain "github.com/AshBuk/FingerGo/internal/domain"
+)
+
+// Text pack layout: a zip holding pack.json (category tree, text metadata,
+// checksums) and content/{id}.txt per text. The embedded welcome library uses
+// the same layout as a plain directory.
+const (
+	packManifestFile  = "pack.json"
+	packContentDir    = "content"
+	packFormatVersion = 1
+	welcomePackDir    = "embedded/welcome"
+)
+
+// ErrInvalidPack is returned for pack archives that are malformed or hold invalid entries.
+var ErrInvalidPack = errors.New("storage: invalid text pack")
+
+// ConflictStrategy decides what ImportPack does with entries whose ID (or
+// category name) is already taken in the library.
+type ConflictStrategy string
+
+// Conflict strategies.
+const (
+	ConflictRename    ConflictStrategy = "rename"    // import under a new ID/name (default)
+	ConflictSkip      ConflictStrategy = "skip"      // keep the library entry, drop the pack entry
+	ConflictOverwrite ConflictStrategy = "overwrite" // replace library texts with the pack version
+)
+
+// PackManifest is pack.json: everything but the text content.
+type PackManifest struct {
+	CreatedAt     time.Time         `json:"createdAt"`
+	AppVersion    string            `json:"appVersion,omitempty"`
+	DefaultTextID string            `json:"defaultTextId,omitempty"` // whole-library packs only
+	Categories    []domain.Category `json:"categories"`              // ParentID refers to categories in the pack (or "")
+	Texts         []domain.Text     `json:"texts"`                   // metadata; content in content/{id}.txt
+	Checksums     map[string]string `json:"checksums,omitempty"`     // archive path → SHA-256 (hex)
+	FormatVersion int               `json:"formatVersion"`
+}
+
+// PackReport summarizes an ImportPack run.
+type PackReport struct {
+	TextIDs     map[string]string `json:"textIds"`     // pack text ID → library text ID (differs when renamed)
+	Imported    int               `json:"imported"`    // texts added under their pack ID
+	Renamed     int               `json:"renamed"`     // texts added under a new ID
+	Overwritten int               `json:"overwritten"` // library texts replaced by the pack version
+	Skipped     int               `json:"skipped"`     // pack texts dropped because the ID was taken
+	Categories  int               `json:"categories"`  // categories created
+}
+
+// textPack is a decoded and validated pack.
+type textPack struct {
+	manifest PackManifest
+	content  map[string]string // text ID → content
+}
+
+// ExportPack writes the categories in categoryIDs, their subcategories and
+// all texts in them to dest as a text pack (replaced atomically). An empty
+// categoryIDs exports the whole library, uncategorized texts included.
+// Selected categories whose parent is not exported become pack roots.
+func ExportPack(store TextStore, categoryIDs []string, dest, appVersion string) (PackManifest, error) {
+	manifest := PackManifest{
+		CreatedAt:     time.Now().UTC(),
+		AppVersion:    appVersion,
+		Checksums:     make(map[string]string),
+		FormatVersion: packFormatVersion,
+	}
+	lib, err := store.Library()
+	if err != nil {
+		return manifest, err
+	}
+	whole := len(categoryIDs) == 0
+	selected, err := categorySubtrees(lib.Categories, categoryIDs)
+	if err != nil {
+		return manifest, err
+	}
+	manifest.Categories = packCategories(lib.Categories, selected, whole)
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, meta := range lib.Texts {
+		if !whole && !selected[meta.CategoryID] {
+			continue
+		}
+		text, err := addPackText(zw, store, meta.ID, manifest.Checksums)
+		if err != nil {
+			return manifest, err
+		}
+		manifest.Texts = append(manifest.Texts, text)
+		if whole && text.ID == lib.DefaultTextID {
+			manifest.DefaultTextID = text.ID
+		}
+	}
+	if err := addZipJSON(zw, packManifestFile, &manifest); err != nil {
+		return manifest, fmt.Errorf("storage: write pack manifest: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return manifest, fmt.Errorf("storage: finish pack: %w", err)
+	}
+	return manifest, replaceFileAt(dest, buf.Bytes(), false)
+}
+
+// packCategories returns the exported categories: all of them for a whole
+// library export, else the selected ones with unselected parents cleared.
+func packCategories(categories []domain.Category, selected map[string]bool, whole bool) []domain.Category {
+	var out []domain.Category
+	for _, c := range categories {
+		if !whole && !selected[c.ID] {
+			continue
+		}
+		if !whole && !selected[c.ParentID] {
+			c.ParentID = ""
+		}
+		out = append(out, c)
+	}
+	return out
+}
+
+// addPackText stores the content of text id in the pack, records its
+// checksum and returns the text without content for the manifest.
+func addPackText(zw *zip.Writer, store TextStore, id string, checksums map[string]string) (domain.Text, error) {
+	text, err := store.Text(id)
+	if err != nil {
+		return text, fmt.Errorf("storage: export text %q: %w", id, err)
+	}
+	name := packContentPath(text.ID)
+	w, err := zw.Create(name)
+	if err != nil {
+		return text, fmt.Errorf("storage: add %q to pack: %w", name, err)
+	}
+	if _, err := io.WriteString(w, text.Content); err != nil {
+		return text, fmt.Errorf("storage: add %q to pack: %w", name, err)
+	}
+	sum := sha256.Sum256([]byte(text.Content))
+	checksums[name] = hex.EncodeToString(sum[:])
+	text.Content = ""
+	return text, nil
+}
+
+// ImportPack adds the categories and texts of a pack made by ExportPack to
+// store. The whole pack is validated first (validateCategory, validateText,
+// checksums, category tree), so an invalid pack changes nothing.
+//
+// Collisions with the library are resolved by strategy:
+//   - rename: texts and categories whose ID is taken get a new ID ("id-2"),
+//     categories whose name is taken a new name ("Name (2)")
+//   - skip: texts whose ID is taken are left as they are
+//   - overwrite: texts whose ID is taken are replaced by the pack version
+//
+// With skip and overwrite, a pack category matching a library category by
+// ID, or else by name, is merged into it.
+func ImportPack(store TextStore, archive string, strategy ConflictStrategy) (PackReport, error) {
+	report := PackReport{TextIDs: make(map[string]string)}
+	if strategy == "" {
+		strategy = ConflictRename
+	}
+	switch strategy {
+	case ConflictRename, ConflictSkip, ConflictOverwrite:
+	default:
+		return report, fmt.Errorf("storage: unknown conflict strategy %q", strategy)
+	}
+	zr, err := zip.OpenReader(archive)
+	if err != nil {
+		return report, fmt.Errorf("%w: %w", ErrInvalidPack, err)
+	}
+	defer zr.Close()
+	for _, f := range zr.File {
+		if err := packEntryAllowed(f.Name); err != nil && !f.FileInfo().IsDir() {
+			return report, err
+		}
+	}
+	pack, err := readPack(&zr.Reader)
+	if err != nil {
+		return report, err
+	}
+	lib, err := store.Library()
+	if err != nil {
+		return report, err
+	}
+	imp := newPackImporter(store, lib, pack, strategy, &report)
+	for _, c := range pack.manifest.Categories {
+		if err := imp.importCategory(c); err != nil {
+			return report, err
+		}
+	}
+	for _, t := range pack.manifest.Texts {
+		if err := imp.importText(t, pack.content[t.ID]); err != nil {
+			return report, err
+		}
+	}
+	return report, nil
+}
+
+// packImporter carries the state of one ImportPack run.
+type packImporter struct {
+	store      TextStore
+	report     *PackReport
+	takenCats  map[string]bool   // category IDs in the library or the pack
+	takenTexts map[string]bool   // text IDs in the library or the pack
+	libCats    map[string]bool   // category IDs in the library
+	libTexts   map[string]bool   // text IDs in the library
+	catNames   map[string]string // library category name → ID
+	catIDs     map[string]string // pack category ID → library category ID
+	strategy   ConflictStrategy
+}
+
+func newPackImporter(store TextStore, lib domain.TextLibrary, pack *textPack, strategy ConflictStrategy, report *PackReport) *packImporter {
+	imp := &packImporter{
+		store:      store,
+		report:     report,
+		takenCats:  make(map[string]bool),
+		takenTexts: make(map[string]bool),
+		libCats:    make(map[string]bool, len(lib.Categories)),
+		libTexts:   make(map[string]bool, len(lib.Texts)),
+		catNames:   make(map[string]string, len(lib.Categories)),
+		catIDs:     make(map[string]string, len(pack.manifest.Categories)),
+		strategy:   strategy,
+	}
+	for _, c := range lib.Categories {
+		imp.libCats[c.ID], imp.takenCats[c.ID] = true, true
+		imp.catNames[c.Name] = c.ID
+	}
+	for _, t := range lib.Texts {
+		imp.libTexts[t.ID], imp.takenTexts[t.ID] = true, true
+	}
+	// Pack IDs are off-limits too, so a renamed entry never takes the ID of a later one
+	for _, c := range pack.manifest.Categories {
+		imp.takenCats[c.ID] = true
+	}
+	for _, t := range pack.manifest.Texts {
+		imp.takenTexts[t.ID] = true
+	}
+	return imp
+}
+
+// importCategory creates c (parents come first) or maps it to the library
+// category it is merged into.
+func (imp *packImporter) importCategory(c domain.Category) error {
+	packID := c.ID
+	c.ParentID = imp.catIDs[c.ParentID]
+	existingID, nameTaken := imp.catNames[c.Name]
+	if imp.strategy != ConflictRename {
+		switch {
+		case imp.libCats[c.ID]:
+			imp.catIDs[packID] = c.ID
+			return nil
+		case nameTaken:
+			imp.catIDs[packID] = existingID
+			return nil
+		}
+	}
+	if imp.libCats[c.ID] {
+		c.ID = freeID(c.ID, func(id string) bool { return imp.takenCats[id] })
+	}
+	if nameTaken {
+		c.Name = freeCategoryName(c.Name, imp.catNames)
+	}
+	if err := imp.store.SaveCategory(&c); err != nil {
+		return fmt.Errorf("storage: import category %q: %w", packID, err)
+	}
+	imp.libCats[c.ID], imp.takenCats[c.ID] = true, true
+	imp.catNames[c.Name] = c.ID
+	imp.catIDs[packID] = c.ID
+	imp.report.Categories++
+	return nil
+}
+
+// importText saves t, resolving an ID collision by the strategy.
+func (imp *packImporter) importText(t domain.Text, content string) error {
+	packID := t.ID
+	t.Content = content
+	t.CategoryID = imp.catIDs[t.CategoryID]
+	var err error
+	switch {
+	case !imp.libTexts[t.ID]:
+		err = imp.store.SaveText(&t)
+		imp.report.Imported++
+	case imp.strategy == ConflictSkip:
+		imp.report.Skipped++
+		return nil
+	case imp.strategy == ConflictOverwrite:
+		err = imp.store.UpdateText(&t)
+		imp.report.Overwritten++
+	default:
+		t.ID = freeID(t.ID, func(id string) bool { return imp.takenTexts[id] })
+		err = imp.store.SaveText(&t)
+		imp.report.Renamed++
+	}
+	if err != nil {
+		return fmt.Errorf("storage: import text %q: %w", packID, err)
+	}
+	imp.libTexts[t.ID], imp.takenTexts[t.ID] = true, true
+	imp.report.TextIDs[packID] = t.ID
+	return nil
+}
+
+// categorySubtrees returns the IDs in ids plus all their descendants.
+func categorySubtrees(categories []domain.Category, ids []string) (map[string]bool, error) {
+	children := make(map[string][]string, len(categories))
+	known := make(map[string]bool, len(categories))
+	for _, c := range categories {
+		children[c.ParentID] = append(children[c.ParentID], c.ID)
+		known[c.ID] = true
+	}
+	selected := make(map[string]bool)
+	queue := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if !known[id] {
+			return nil, fmt.Errorf("%w: %s", ErrCategoryNotFound, id)
+		}
+		queue = append(queue, id)
+	}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		if selected[id] {
+			continue
+		}
+		selected[id] = true
+		queue = append(queue, children[id]...)
+	}
+	return selected, nil
+}
+
+// readPack decodes and validates the pack in fsys (a zip or a directory).
+func readPack(fsys fs.FS) (*textPack, error) {
+	pack := &textPack{content: make(map[string]string)}
+	if err := readPackManifest(fsys, &pack.manifest); err != nil {
+		return nil, err
+	}
+	m := &pack.manifest
+	categories, err := checkPackCategories(m)
+	if err != nil {
+		return nil, err
+	}
+	texts := make(map[string]bool, len(m.Texts))
+	for i := range m.Texts {
+		t := &m.Texts[i]
+		if err := checkPackText(t, texts, categories); err != nil {
+			return nil, err
+		}
+		texts[t.ID] = true
+		if pack.content[t.ID], err = readPackText(fsys, t, m.Checksums); err != nil {
+			return nil, err
+		}
+	}
+	if m.DefaultTextID != "" && !texts[m.DefaultTextID] {
+		return nil, fmt.Errorf("%w: default text %q is not in the pack", ErrInvalidPack, m.DefaultTextID)
+	}
+	if err := checkPackFiles(fsys, texts); err != nil {
+		return nil, err
+	}
+	return pack, nil
+}
+
+// readPackManifest decodes the manifest of the pack in fsys into m and
+// checks its format version.
+func readPackManifest(fsys fs.FS, m *PackManifest) error {
+	raw, err := fs.ReadFile(fsys, packManifestFile)
+	if err != nil {
+		return fmt.Errorf("%w: %s missing", ErrInvalidPack, packManifestFile)
+	}
+	if len(raw) > maxManifestSize {
+		return fmt.Errorf("%w: %s is too large", ErrInvalidPack, packManifestFile)
+	}
+	if err := json.Unmarshal(raw, m); err != nil {
+		return fmt.Errorf("%w: %s: %w", ErrInvalidPack, packManifestFile, err)
+	}
+	switch {
+	case m.FormatVersion > packFormatVersion:
+		return fmt.Errorf("%w: text pack format v%d, this build supports v%d", ErrSchemaTooNew, m.FormatVersion, packFormatVersion)
+	case m.FormatVersion < 1:
+		return fmt.Errorf("%w: missing format version", ErrInvalidPack)
+	}
+	return nil
+}
+
+// checkPackText rejects a manifest text listed twice (texts holds the IDs
+// seen so far) or placed in a category the pack does not have.
+func checkPackText(t *domain.Text, texts, categories map[string]bool) error {
+	if texts[t.ID] {
+		return fmt.Errorf("%w: duplicate text %q", ErrInvalidPack, t.ID)
+	}
+	if t.CategoryID != "" && !categories[t.CategoryID] {
+		return fmt.Errorf("%w: text %q: %w: %s", ErrInvalidPack, t.ID, ErrCategoryNotFound, t.CategoryID)
+	}
+	return nil
+}
+
+// checkPackFiles rejects stray files rather than silently dropping them:
+// every entry besides the manifest must be the content of a listed text.
+func checkPackFiles(fsys fs.FS, texts map[string]bool) error {
+	return fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || name == packManifestFile {
+			return err
+		}
+		if err := packEntryAllowed(name); err != nil {
+			return err
+		}
+		if id := strings.TrimSuffix(path.Base(name), ".txt"); !texts[id] {
+			return fmt.Errorf("%w: %q belongs to no text", ErrInvalidPack, name)
+		}
+		return nil
+	})
+}
+
+// checkPackCategories puts the categories of m in parent-first order,
+// validates them and returns their IDs.
+func checkPackCategories(m *PackManifest) (map[string]bool, error) {
+	ordered, err := orderCategories(m.Categories)
+	if err != nil {
+		return nil, err
+	}
+	m.Categories = ordered
+	ids := make(map[string]bool, len(m.Categories))
+	names := make(map[string]bool, len(m.Categories))
+	for i := range m.Categories {
+		c := &m.Categories[i]
+		if err := validateCategory(c); err != nil {
+			return nil, fmt.Errorf("%w: category %q: %w", ErrInvalidPack, c.ID, err)
+		}
+		if names[c.Name] {
+			return nil, fmt.Errorf("%w: duplicate category name %q", ErrInvalidPack, c.Name)
+		}
+		ids[c.ID], names[c.Name] = true, true
+	}
+	return ids, nil
+}
+
+// readPackText reads the content of t and runs t through validateText.
+func readPackText(fsys fs.FS, t *domain.Text, checksums map[string]string) (string, error) {
+	if err := validateTextID(t.ID); err != nil {
+		return "", fmt.Errorf("%w: text %q: %w", ErrInvalidPack, t.ID, err)
+	}
+	content, err := readPackContent(fsys, t.ID, checksums)
+	if err != nil {
+		return "", err
+	}
+	t.Content = content
+	err = validateText(t)
+	t.Content = ""
+	if err != nil {
+		return "", fmt.Errorf("%w: text %q: %w", ErrInvalidPack, t.ID, err)
+	}
+	return content, nil
+}
+
+// readPackContent reads content/{id}.txt and checks it against checksums (if
+// the pack has any).
+func readPackContent(fsys fs.FS, id string, checksums map[string]string) (string, error) {
+	name := packContentPath(id)
+	f, err := fsys.Open(name)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s missing", ErrInvalidPack, name)
+	}
+	defer f.Close()
+	data, err := io.ReadAll(io.LimitReader(f, maxContentLength+1))
+	if err != nil {
+		return "", fmt.Errorf("%w: %s: %w", ErrInvalidPack, name, err)
+	}
+	if len(data) > maxContentLength {
+		return "", fmt.Errorf("%w: text %q: %w", ErrInvalidPack, id, ErrTextContentTooLarge)
+	}
+	if checksums != nil {
+		sum := sha256.Sum256(data)
+		if checksums[name] != hex.EncodeToString(sum[:]) {
+			return "", fmt.Errorf("%w: %s", ErrBackupChecksum, name)
+		}
+	}
+	return string(data), nil
+}
+
+// orderCategories sorts categories so that parents precede their children,
+// rejecting unknown parents, duplicate IDs and cycles.
+func orderCategories(categories []domain.Category) ([]domain.Category, error) {
+	byID := make(map[string]domain.Category, len(categories))
+	for _, c := range categories {
+		if _, dup := byID[c.ID]; dup {
+			return nil, fmt.Errorf("%w: duplicate category %q", ErrInvalidPack, c.ID)
+		}
+		byID[c.ID] = c
+	}
+	const (
+		visiting = 1
+		done     = 2
+	)
+	state := make(map[string]int, len(categories))
+	ordered := make([]domain.Category, 0, len(categories))
+	var visit func(c domain.Category) error
+	visit = func(c domain.Category) error {
+		switch state[c.ID] {
+		case done:
+			return nil
+		case visiting:
+			return fmt.Errorf("%w: category %q is its own ancestor", ErrInvalidPack, c.ID)
+		}
+		state[c.ID] = visiting
+		if c.ParentID != "" {
+			parent, ok := byID[c.ParentID]
+			if !ok {
+				return fmt.Errorf("%w: category %q: parent %w: %s", ErrInvalidPack, c.ID, ErrCategoryNotFound, c.ParentID)
+			}
+			if err := visit(parent); err != nil {
+				return err
+			}
+		}
+		state[c.ID] = done
+		ordered = append(ordered, c)
+		return nil
+	}
+	for _, c := range categories {
+		if err := visit(c); err != nil {
+			return nil, err
+		}
+	}
+	return ordered, nil
+}
+
+// packEntryAllowed accepts pack.json and content/{id}.txt with a valid text ID.
+func packEntryAllowed(name string) error {
+	if name == packManifestFile {
+		return nil
+	}
+	dir, file := path.Split(name)
+	id, ok := strings.CutSuffix(file, ".txt")
+	if dir != packContentDir+"/" || !ok {
+		return fmt.Errorf("%w: unexpected entry %q", ErrInvalidPack, name)
+	}
+	if err := validateTextID(id); err != nil {
+		return fmt.Errorf("%w: %q: %w", ErrInvalidPack, name, err)
+	}
+	return nil
+}
+
+// packContentPath returns the archive path of a text's content.
+func packContentPath(id string) string {
+	return packContentDir + "/" + id + ".txt"
+}
+
+// freeID returns id with the first numeric suffix ("-2", "-3", ...) not taken.
+func freeID(id string, taken func(string) bool) string {
+	for n := 2; ; n++ {
+		if candidate := id + "-" + strconv.Itoa(n); !taken(candidate) {
+			return candidate
+		}
+	}
+}
+
+// freeCategoryName returns name with the first " (n)" suffix not in names.
+func freeCategoryName(name string, names map[string]string) string {
+	for n := 2; ; n++ {
+		suffix := " (" + strconv.Itoa(n) + ")"
+		base := name
+		for len(base)+len(suffix) > maxCategoryName {
+			_, size := utf8.DecodeLastRuneInString(base)
+			base = base[:len(base)-size]
+		}
+		if _, taken := names[base+suffix]; !taken {
+			return base + suffix
+		}
+	}
+}
+
+// welcomePack returns the embedded pack that seeds a new library.
+func welcomePack() (*textPack, error) {
+	fsys, err := fs.Sub(embeddedFiles, welcomePackDir)
+	if err != nil {
+		return nil, fmt.Errorf("storage: open embedded welcome pack: %w", err)
+	}
+	pack, err := readPack(fsys)
+	if err != nil {
+		return nil, fmt.Errorf("storage: embedded welcome pack: %w", err)
+	}
+	return pack, nil
+}
+
+// library returns the pack as a text library (content stripped).
+func (p *textPack) library() domain.TextLibrary {
+	return cloneLibrary(domain.TextLibrary{
+		DefaultTextID: p.manifest.DefaultTextID,
+		Categories:    p.manifest.Categories,
+		Texts:         p.manifest.Texts,
+	})
+}
@@ -0,0 +1,246 @@
+// Copyright 2025 Asher Buk
+// SPDX-License-Identifier: Apache-2.0
+// https://github.com/AshBuk/FingerGo
+
+package storage
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	domain "github.com/AshBuk/FingerGo/internal/domain"
+)
+
+// writePack builds a pack zip from a manifest and archive path → content.
+func writePack(t *testing.T, manifest PackManifest, files map[string]string) string {
+	t.Helper()
+	dest := filepath.Join(t.TempDir(), "test.fgpack")
+	f, err := os.Create(dest)
+	if err != nil {
+		t.Fatalf("Create() error: %v", err)
+	}
+	defer f.Close()
+	zw := zip.NewWriter(f)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatalf("zip Create(%q) error: %v", name, err)
+		}
+		_, _ = w.Write([]byte(content))
+	}
+	w, _ := zw.Create(packManifestFile)
+	_ = json.NewEncoder(w).Encode(&manifest)
+	if err := zw.Close(); err != nil {
+		t.Fatalf("zip Close() error: %v", err)
+	}
+	return dest
+}
+
+// Pack fixtures: the tree A > B with one text each, plus an uncategorized text.
+var (
+	packTreeCategories = []domain.Category{{ID: "a", Name: "A"}, {ID: "b", Name: "B", ParentID: "a"}, {ID: "other", Name: "Other"}}
+	packTreeTexts      = []domain.Text{
+		{ID: "t-a", Title: "In A", Content: "alpha", CategoryID: "a", Language: "text"},
+		{ID: "t-b", Title: "In B", Content: "func b() {}", CategoryID: "b", Language: "go", IsFavorite: true},
+		{ID: "t-other", Title: "Other", Content: "other", CategoryID: "other"},
+		{ID: "t-root", Title: "Root", Content: "root"},
+	}
+)
+
+func TestWelcomePack(t *testing.T) {
+	pack, err := welcomePack()
+	if err != nil {
+		t.Fatalf("welcomePack() error: %v", err)
+	}
+	if pack.manifest.DefaultTextID == "" || pack.content[pack.manifest.DefaultTextID] == "" {
+		t.Fatalf("welcome pack has no default text content: %+v", pack.manifest)
+	}
+
+	mgr := setupManager(t)
+	data, err := os.ReadFile(mgr.join(contentPath(pack.manifest.DefaultTextID)))
+	if err != nil || string(data) != pack.content[pack.manifest.DefaultTextID] {
+		t.Errorf("Init did not seed the default text content: %v", err)
+	}
+}
+
+func TestPack_ExportImport(t *testing.T) {
+	for name, open := range backends(t) {
+		t.Run(string(name), func(t *testing.T) {
+			src := open(t).texts
+			fillLibrary(t, src, packTreeCategories, packTreeTexts)
+			dest := filepath.Join(t.TempDir(), "a.fgpack")
+			manifest, err := ExportPack(src, []string{"a"}, dest, "1.0")
+			if err != nil {
+				t.Fatalf("ExportPack() error: %v", err)
+			}
+			if len(manifest.Categories) != 2 || len(manifest.Texts) != 2 || manifest.DefaultTextID != "" {
+				t.Fatalf("manifest = %+v, want categories a, b and their two texts", manifest)
+			}
+
+			dst := open(t).texts
+			report, err := ImportPack(dst, dest, "")
+			if err != nil {
+				t.Fatalf("ImportPack() error: %v", err)
+			}
+			if report.Imported != 2 || report.Categories != 2 {
+				t.Errorf("report = %+v", report)
+			}
+			got, err := dst.Text("t-b")
+			if err != nil {
+				t.Fatalf("Text(t-b) error: %v", err)
+			}
+			if got.Content != "func b() {}" || got.CategoryID != "b" || !got.IsFavorite || got.Language != "go" {
+				t.Errorf("imported text = %+v", got)
+			}
+		})
+	}
+}
+
+func TestPack_WholeLibrary(t *testing.T) {
+	src := setupTextRepository(t)
+	fillLibrary(t, src, packTreeCategories, packTreeTexts)
+	dest := filepath.Join(t.TempDir(), "all.fgpack")
+	manifest, err := ExportPack(src, nil, dest, "")
+	if err != nil {
+		t.Fatalf("ExportPack() error: %v", err)
+	}
+	lib, _ := src.Library()
+	if len(manifest.Texts) != len(lib.Texts) || len(manifest.Categories) != len(lib.Categories) {
+		t.Errorf("exported %d texts / %d categories, library has %d / %d",
+			len(manifest.Texts), len(manifest.Categories), len(lib.Texts), len(lib.Categories))
+	}
+	if manifest.DefaultTextID != lib.DefaultTextID {
+		t.Errorf("DefaultTextID = %q, want %q", manifest.DefaultTextID, lib.DefaultTextID)
+	}
+	if _, err := ExportPack(src, []string{"missing"}, dest, ""); !errors.Is(err, ErrCategoryNotFound) {
+		t.Errorf("unknown category: error = %v, want ErrCategoryNotFound", err)
+	}
+}
+
+func TestImportPack_Conflicts(t *testing.T) {
+	setup := func(t *testing.T) (*TextRepository, string) {
+		repo := setupTextRepository(t)
+		fillLibrary(t, repo, packTreeCategories, packTreeTexts)
+		dest := filepath.Join(t.TempDir(), "a.fgpack")
+		if _, err := ExportPack(repo, []string{"a"}, dest, ""); err != nil {
+			t.Fatalf("ExportPack() error: %v", err)
+		}
+		edited, _ := repo.Text("t-a")
+		edited.Content = "edited locally"
+		if err := repo.UpdateText(&edited); err != nil {
+			t.Fatalf("UpdateText() error: %v", err)
+		}
+		return repo, dest
+	}
+
+	t.Run("rename", func(t *testing.T) {
+		repo, dest := setup(t)
+		report, err := ImportPack(repo, dest, ConflictRename)
+		if err != nil {
+			t.Fatalf("ImportPack() error: %v", err)
+		}
+		if report.Renamed != 2 || report.Categories != 2 || report.TextIDs["t-b"] != "t-b-2" {
+			t.Fatalf("report = %+v", report)
+		}
+		renamed, _ := repo.Text("t-b-2")
+		lib, _ := repo.Library()
+		var parent domain.Category
+		for _, c := range lib.Categories {
+			if c.ID == renamed.CategoryID {
+				parent = c
+			}
+		}
+		if parent.ID != "b-2" || parent.Name != "B (2)" || parent.ParentID != "a-2" {
+			t.Errorf("renamed text is in %+v, want category b-2 \"B (2)\" under a-2", parent)
+		}
+		if original, _ := repo.Text("t-a"); original.Content != "edited locally" {
+			t.Errorf("rename touched the library text: %q", original.Content)
+		}
+	})
+
+	t.Run("skip", func(t *testing.T) {
+		repo, dest := setup(t)
+		report, err := ImportPack(repo, dest, ConflictSkip)
+		if err != nil {
+			t.Fatalf("ImportPack() error: %v", err)
+		}
+		if report.Skipped != 2 || report.Categories != 0 {
+			t.Errorf("report = %+v", report)
+		}
+		if text, _ := repo.Text("t-a"); text.Content != "edited locally" {
+			t.Errorf("skip replaced the library text: %q", text.Content)
+		}
+	})
+
+	t.Run("overwrite", func(t *testing.T) {
+		repo, dest := setup(t)
+		report, err := ImportPack(repo, dest, ConflictOverwrite)
+		if err != nil {
+			t.Fatalf("ImportPack() error: %v", err)
+		}
+		if report.Overwritten != 2 || report.Categories != 0 {
+			t.Errorf("report = %+v", report)
+		}
+		if text, _ := repo.Text("t-a"); text.Content != "alpha" {
+			t.Errorf("overwrite kept the library text: %q", text.Content)
+		}
+	})
+
+	t.Run("unknown strategy", func(t *testing.T) {
+		repo, dest := setup(t)
+		if _, err := ImportPack(repo, dest, "merge"); err == nil {
+			t.Error("expected error for unknown strategy")
+		}
+	})
+}
+
+func TestImportPack_Rejects(t *testing.T) {
+	valid := func() (PackManifest, map[string]string) {
+		return PackManifest{
+				FormatVersion: packFormatVersion,
+				Categories:    []domain.Category{{ID: "c1", Name: "One"}},
+				Texts:         []domain.Text{{ID: "t1", Title: "T", CategoryID: "c1", Language: "go"}},
+			},
+			map[string]string{"content/t1.txt": "package main"}
+	}
+	tests := []struct {
+		edit func(m *PackManifest, files map[string]string)
+		want error
+		name string
+	}{
+		{name: "newer format", want: ErrSchemaTooNew, edit: func(m *PackManifest, _ map[string]string) { m.FormatVersion = 99 }},
+		{name: "invalid language", want: ErrInvalidLanguage, edit: func(m *PackManifest, _ map[string]string) { m.Texts[0].Language = "klingon" }},
+		{name: "empty category name", want: ErrEmptyCategoryName, edit: func(m *PackManifest, _ map[string]string) { m.Categories[0].Name = "" }},
+		{name: "missing content", want: ErrInvalidPack, edit: func(_ *PackManifest, files map[string]string) { delete(files, "content/t1.txt") }},
+		{name: "unsafe entry", want: ErrInvalidPack, edit: func(_ *PackManifest, files map[string]string) { files["../evil.txt"] = "x" }},
+		{name: "stray content", want: ErrInvalidPack, edit: func(_ *PackManifest, files map[string]string) { files["content/t9.txt"] = "x" }},
+		{name: "unknown parent", want: ErrCategoryNotFound, edit: func(m *PackManifest, _ map[string]string) { m.Categories[0].ParentID = "nope" }},
+		{name: "category cycle", want: ErrInvalidPack, edit: func(m *PackManifest, _ map[string]string) {
+			m.Categories = append(m.Categories, domain.Category{ID: "c2", Name: "Two", ParentID: "c1"})
+			m.Categories[0].ParentID = "c2"
+		}},
+		{name: "checksum mismatch", want: ErrBackupChecksum, edit: func(m *PackManifest, _ map[string]string) {
+			m.Checksums = map[string]string{"content/t1.txt": "00"}
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := setupTextRepository(t)
+			before, _ := repo.Library()
+			manifest, files := valid()
+			tt.edit(&manifest, files)
+			_, err := ImportPack(repo, writePack(t, manifest, files), ConflictRename)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("ImportPack() error = %v, want %v", err, tt.want)
+			}
+			after, _ := repo.Library()
+			if len(after.Texts) != len(before.Texts) || len(after.Categories) != len(before.Categories) {
+				t.Error("rejected pack changed the library")
+			}
+		})
+	}
+}
@@ -5,6 +5,10 @@
 package storage
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -12,6 +16,79 @@ import (
 
 const appName = "FingerGo"
 
+// DataDirEnv overrides the data directory when set (see ResolveRoot).
+const DataDirEnv = "FINGERGO_DATA_DIR"
+
+// Portable mode: when a file named PortableMarker sits next to the executable,
+// data lives in the portableDataDir directory beside it (e.g. on a USB stick).
+const (
+	PortableMarker  = "fingergo.portable"
+	portableDataDir = "data"
+)
+
+// RootSource tells where a data directory path came from.
+type RootSource string
+
+// Data directory sources, in order of precedence.
+const (
+	RootFromFlag     RootSource = "flag"     // --data-dir command-line flag
+	RootFromEnv      RootSource = "env"      // FINGERGO_DATA_DIR
+	RootFromPortable RootSource = "portable" // data/ next to the executable
+	RootFromDefault  RootSource = "default"  // platform default (DefaultRoot)
+)
+
+// DataRoot is a resolved data directory.
+type DataRoot struct {
+	Path   string     `json:"path"`   // absolute path to the data directory
+	Source RootSource `json:"source"` // how Path was chosen
+}
+
+// InstanceID identifies the app instance that owns this data directory, for
+// the single-instance lock: copies running on different data directories
+// (e.g. a portable install next to the default one) do not block each other.
+func (r DataRoot) InstanceID() string {
+	sum := sha256.Sum256([]byte(r.Path))
+	return "io.github.AshBuk.FingerGo." + hex.EncodeToString(sum[:8])
+}
+
+// ResolveRoot picks the data directory: flagValue (from --data-dir) if set,
+// then $FINGERGO_DATA_DIR, then portable mode, then DefaultRoot.
+// Relative paths are made absolute against the working directory.
+func ResolveRoot(flagValue string) (DataRoot, error) {
+	exeDir := ""
+	if exe, err := os.Executable(); err == nil {
+		if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+			exe = resolved
+		}
+		exeDir = filepath.Dir(exe)
+	}
+	return resolveRoot(flagValue, os.Getenv(DataDirEnv), exeDir)
+}
+
+// resolveRoot implements ResolveRoot with the environment passed in for tests.
+func resolveRoot(flagValue, envValue, exeDir string) (DataRoot, error) {
+	root := DataRoot{Path: DefaultRoot(), Source: RootFromDefault}
+	switch {
+	case flagValue != "":
+		root = DataRoot{Path: flagValue, Source: RootFromFlag}
+	case envValue != "":
+		root = DataRoot{Path: envValue, Source: RootFromEnv}
+	case exeDir != "":
+		marker := filepath.Join(exeDir, PortableMarker)
+		if _, err := os.Stat(marker); err == nil {
+			root = DataRoot{Path: filepath.Join(exeDir, portableDataDir), Source: RootFromPortable}
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return DataRoot{}, fmt.Errorf("storage: stat portable marker %q: %w", marker, err)
+		}
+	}
+	abs, err := filepath.Abs(root.Path)
+	if err != nil {
+		return DataRoot{}, fmt.Errorf("storage: resolve data directory %q: %w", root.Path, err)
+	}
+	root.Path = abs
+	return root, nil
+}
+
 // DefaultRoot returns platform-specific application data directory.
 // - Linux:   $XDG_DATA_HOME/FingerGo or ~/.local/share/FingerGo
 // - macOS:   ~/Library/Application Support/FingerGo
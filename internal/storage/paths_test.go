@@ -0,0 +1,78 @@
+// Copyright 2025 Asher Buk
+// SPDX-License-Identifier: Apache-2.0
+// https://github.com/AshBuk/FingerGo
+
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolveRoot(t *testing.T) {
+	portableExe := t.TempDir()
+	if err := os.WriteFile(filepath.Join(portableExe, PortableMarker), nil, 0o600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	plainExe := t.TempDir()
+	flagDir, envDir := t.TempDir(), t.TempDir()
+
+	tests := []struct {
+		name       string
+		flag, env  string
+		exeDir     string
+		wantPath   string
+		wantSource RootSource
+	}{
+		{"flag wins over everything", flagDir, envDir, portableExe, flagDir, RootFromFlag},
+		{"env wins over portable", "", envDir, portableExe, envDir, RootFromEnv},
+		{"portable marker next to binary", "", "", portableExe, filepath.Join(portableExe, portableDataDir), RootFromPortable},
+		{"platform default without marker", "", "", plainExe, DefaultRoot(), RootFromDefault},
+		{"platform default without executable", "", "", "", DefaultRoot(), RootFromDefault},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveRoot(tt.flag, tt.env, tt.exeDir)
+			if err != nil {
+				t.Fatalf("resolveRoot: %v", err)
+			}
+			want, _ := filepath.Abs(tt.wantPath)
+			if got.Path != want || got.Source != tt.wantSource {
+				t.Errorf("got %+v, want {%s %s}", got, want, tt.wantSource)
+			}
+		})
+	}
+
+	t.Run("relative paths become absolute", func(t *testing.T) {
+		got, err := resolveRoot("relative-data", "", "")
+		if err != nil {
+			t.Fatalf("resolveRoot: %v", err)
+		}
+		if !filepath.IsAbs(got.Path) {
+			t.Errorf("path %q is not absolute", got.Path)
+		}
+	})
+}
+
+func TestResolveRoot_Env(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(DataDirEnv, dir)
+	got, err := ResolveRoot("")
+	if err != nil {
+		t.Fatalf("ResolveRoot: %v", err)
+	}
+	if got.Path != dir || got.Source != RootFromEnv {
+		t.Errorf("got %+v, want {%s %s}", got, dir, RootFromEnv)
+	}
+}
+
+func TestDataRoot_InstanceID(t *testing.T) {
+	a := DataRoot{Path: "/data/a"}
+	if a.InstanceID() != (DataRoot{Path: "/data/a", Source: RootFromEnv}).InstanceID() {
+		t.Error("InstanceID should depend only on the path")
+	}
+	if a.InstanceID() == (DataRoot{Path: "/data/b"}).InstanceID() {
+		t.Error("different data directories must not share an InstanceID")
+	}
+}
@@ -0,0 +1,156 @@
+// Copyright 2025 Asher Buk
+// SPDX-License-Identifier: Apache-2.0
+// https://github.com/AshBuk/FingerGo
+
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	domain "github.com/AshBuk/FingerGo/internal/domain"
+)
+
+const (
+	corruptSuffix    = ".corrupt"        // quarantined files: {name}.{timestamp}.corrupt
+	quarantineLayout = "20060102-150405" // UTC timestamp embedded in quarantine names
+)
+
+// errCorruptFile marks a document that failed to parse and had no usable backup.
+// The broken file has already been quarantined when this is returned.
+var errCorruptFile = errors.New("storage: file is corrupt")
+
+// Warnings returns recoverable problems found while loading data
+// (quarantined files, rebuilt indexes), oldest first.
+func (m *Manager) Warnings() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]string(nil), m.warnings...)
+}
+
+// warnf records a recoverable problem for the GUI and logs it.
+func (m *Manager) warnf(format string, args ...any) {
+	msg := fmt.Sprintf(format, args...)
+	log.Printf("WARNING: storage: %s", msg)
+	m.mu.Lock()
+	m.warnings = append(m.warnings, msg)
+	m.mu.Unlock()
+}
+
+// quarantine moves a broken file aside as {relPath}.{timestamp}.corrupt
+// so it can be inspected later, and returns the new relative path.
+func (m *Manager) quarantine(relPath string) (string, error) {
+	if err := m.checkWritable(); err != nil {
+		return "", err
+	}
+	stamp := time.Now().UTC().Format(quarantineLayout)
+	dest := fmt.Sprintf("%s.%s%s", relPath, stamp, corruptSuffix)
+	for i := 1; ; i++ {
+		if _, err := os.Stat(m.join(dest)); errors.Is(err, os.ErrNotExist) {
+			break
+		}
+		dest = fmt.Sprintf("%s.%s-%d%s", relPath, stamp, i, corruptSuffix)
+	}
+	if err := os.Rename(m.join(relPath), m.join(dest)); err != nil {
+		return "", fmt.Errorf("storage: quarantine %q: %w", relPath, err)
+	}
+	return dest, nil
+}
+
+// readFileFallback reads relPath and hands it to decode.
+// A file that fails to decode is quarantined and the .bak generation is
+// restored in its place. Without a usable backup errCorruptFile is returned,
+// letting the repository rebuild or reset the document.
+// os.ErrNotExist is preserved so callers can seed defaults on first run.
+func (m *Manager) readFileFallback(relPath string, decode func([]byte) error) error {
+	path := m.join(relPath)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		if m.restoreBackup(relPath, decode) {
+			m.warnf("%s was missing; restored previous generation from %s", relPath, backupSuffix)
+			return nil
+		}
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("storage: read %q: %w", path, err)
+	}
+	parseErr := decode(data)
+	if parseErr == nil {
+		return nil
+	}
+	if errors.Is(parseErr, ErrSchemaTooNew) {
+		return parseErr // valid data from a newer build, never quarantine it
+	}
+	dest, err := m.quarantine(relPath)
+	if err != nil {
+		return fmt.Errorf("storage: parse %q: %w (%w)", path, parseErr, err)
+	}
+	if m.restoreBackup(relPath, decode) {
+		m.warnf("%s was corrupt and moved to %s; restored previous generation", relPath, dest)
+		return nil
+	}
+	return fmt.Errorf("%w: %s moved to %s: %w", errCorruptFile, relPath, dest, parseErr)
+}
+
+// restoreBackup decodes {relPath}.bak and, if valid, writes it back as relPath.
+func (m *Manager) restoreBackup(relPath string, decode func([]byte) error) bool {
+	data, err := os.ReadFile(m.join(relPath + backupSuffix))
+	if err != nil {
+		return false
+	}
+	if err := decode(data); err != nil {
+		return false
+	}
+	if err := m.writeFile(relPath, data); err != nil {
+		log.Printf("WARNING: storage: failed to write restored %q: %v", relPath, err)
+	}
+	return true
+}
+
+// rebuildLibrary reconstructs the text index after index.json was lost.
+// Starts from the embedded welcome pack and adds one uncategorized entry
+// per texts/content/{id}.txt file; titles fall back to the ID.
+func (m *Manager) rebuildLibrary() (domain.TextLibrary, error) {
+	pack, err := welcomePack()
+	if err != nil {
+		return domain.TextLibrary{}, err
+	}
+	library := pack.library()
+	known := make(map[string]bool, len(library.Texts))
+	for _, text := range library.Texts {
+		known[text.ID] = true
+	}
+	fallback := filepath.Base(fallbackContentFile)
+	entries, err := os.ReadDir(m.join(textsContentDir))
+	if err != nil {
+		return library, fmt.Errorf("storage: list content: %w", err)
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || name == fallback || filepath.Ext(name) != ".txt" {
+			continue
+		}
+		id := strings.TrimSuffix(name, ".txt")
+		if known[id] || validateTextID(id) != nil {
+			continue
+		}
+		created := time.Now().UTC()
+		if info, err := entry.Info(); err == nil {
+			created = info.ModTime().UTC()
+		}
+		library.Texts = append(library.Texts, domain.Text{
+			ID:        id,
+			Title:     id,
+			Language:  defaultLanguage,
+			CreatedAt: created,
+		})
+		known[id] = true
+	}
+	return library, nil
+}
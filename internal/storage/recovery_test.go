@@ -0,0 +1,160 @@
+// Copyright 2025 Asher Buk
+// SPDX-License-Identifier: Apache-2.0
+// https://github.com/AshBuk/FingerGo
+
+package storage
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	domain "github.com/AshBuk/FingerGo/internal/domain"
+)
+
+// corruptFile overwrites a data file with unparsable JSON and removes its backup.
+func corruptFile(t *testing.T, mgr *Manager, relPath string) {
+	t.Helper()
+	path := filepath.Join(mgr.Root(), relPath)
+	if err := os.WriteFile(path, []byte("{not json"), 0o600); err != nil {
+		t.Fatalf("WriteFile() error: %v", err)
+	}
+	_ = os.Remove(path + backupSuffix)
+}
+
+// quarantined lists *.corrupt files next to relPath.
+func quarantined(t *testing.T, mgr *Manager, relPath string) []string {
+	t.Helper()
+	matches, err := filepath.Glob(filepath.Join(mgr.Root(), relPath) + ".*" + corruptSuffix)
+	if err != nil {
+		t.Fatalf("Glob() error: %v", err)
+	}
+	return matches
+}
+
+func TestManager_Quarantine(t *testing.T) {
+	mgr := setupManager(t)
+	corruptFile(t, mgr, sessionsFile)
+
+	first, err := mgr.quarantine(sessionsFile)
+	if err != nil {
+		t.Fatalf("quarantine() error: %v", err)
+	}
+	if !strings.HasSuffix(first, corruptSuffix) {
+		t.Errorf("got %q, want %s suffix", first, corruptSuffix)
+	}
+	// Second quarantine within the same second must not clobber the first
+	corruptFile(t, mgr, sessionsFile)
+	second, err := mgr.quarantine(sessionsFile)
+	if err != nil {
+		t.Fatalf("quarantine() error: %v", err)
+	}
+	if first == second {
+		t.Errorf("quarantine names collide: %q", first)
+	}
+	if got := len(quarantined(t, mgr, sessionsFile)); got != 2 {
+		t.Errorf("got %d quarantined files, want 2", got)
+	}
+}
+
+func TestTextRepository_RebuildsCorruptIndex(t *testing.T) {
+	mgr := setupManager(t)
+	repo, _ := NewTextRepository(mgr)
+	text := &domain.Text{ID: "kept", Title: "Kept", Content: "survives", Language: "text"}
+	if err := repo.SaveText(text); err != nil {
+		t.Fatalf("SaveText() error: %v", err)
+	}
+	corruptFile(t, mgr, textsIndexFile)
+
+	reopened, _ := NewTextRepository(mgr)
+	lib, err := reopened.Library()
+	if err != nil {
+		t.Fatalf("Library() error: %v", err)
+	}
+	ids := make(map[string]bool)
+	for _, entry := range lib.Texts {
+		ids[entry.ID] = true
+	}
+	if !ids["kept"] {
+		t.Error("text with content file should be rebuilt into the index")
+	}
+	if ids[strings.TrimSuffix(filepath.Base(fallbackContentFile), ".txt")] {
+		t.Error("fallback content file should not become a library entry")
+	}
+	got, err := reopened.Text("kept")
+	if err != nil || got.Content != "survives" {
+		t.Errorf("Text() = %q, %v; want rebuilt content", got.Content, err)
+	}
+	if len(quarantined(t, mgr, textsIndexFile)) != 1 {
+		t.Error("corrupt index should be quarantined")
+	}
+	if len(mgr.Warnings()) == 0 {
+		t.Error("expected a recovery warning")
+	}
+}
+
+func TestSessionRepository_ResetsCorruptLegacyHistory(t *testing.T) {
+	mgr := setupManager(t)
+	// Legacy sessions.json not yet converted to the journal
+	_ = os.Remove(filepath.Join(mgr.Root(), sessionsJournalFile))
+	corruptFile(t, mgr, sessionsFile)
+
+	repo, _ := NewSessionRepository(mgr)
+	sessions, err := repo.List(0)
+	if err != nil {
+		t.Fatalf("List() error: %v", err)
+	}
+	if len(sessions) != 0 {
+		t.Errorf("got %d sessions, want empty history", len(sessions))
+	}
+	if len(quarantined(t, mgr, sessionsFile)) != 1 {
+		t.Error("corrupt sessions file should be quarantined")
+	}
+	if _, err := repo.Record(&domain.SessionPayload{WPM: 10}); err != nil {
+		t.Errorf("Record() after recovery error: %v", err)
+	}
+}
+
+func TestSettingsRepository_ResetsCorruptSettings(t *testing.T) {
+	mgr := setupManager(t)
+	corruptFile(t, mgr, configFile)
+
+	repo, _ := NewSettingsRepository(mgr)
+	settings, err := repo.Load()
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if !reflect.DeepEqual(settings, domain.DefaultSettings()) {
+		t.Errorf("got %+v, want defaults", settings)
+	}
+	if len(mgr.Warnings()) != 1 {
+		t.Errorf("got warnings %v, want exactly one", mgr.Warnings())
+	}
+}
+
+func TestManager_ReadFileFallbackQuarantinesBeforeRestore(t *testing.T) {
+	mgr := setupManager(t)
+	_ = mgr.writeFileBackup("doc.json", []byte(`[1]`))
+	_ = mgr.writeFileBackup("doc.json", []byte(`[2]`))
+	_ = os.WriteFile(filepath.Join(mgr.Root(), "doc.json"), []byte(`[`), 0o600)
+
+	err := mgr.readFileFallback("doc.json", func(data []byte) error {
+		if string(data) == "[" {
+			return errors.New("bad")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("readFileFallback() error: %v", err)
+	}
+	data, _ := os.ReadFile(filepath.Join(mgr.Root(), "doc.json"))
+	if string(data) != `[1]` {
+		t.Errorf("primary = %q, want restored backup", data)
+	}
+	if len(quarantined(t, mgr, "doc.json")) != 1 {
+		t.Error("corrupt primary should be quarantined")
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright 2025 Asher Buk
+// SPDX-License-Identifier: Apache-2.0
+// https://github.com/AshBuk/FingerGo
+
+package storage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	domain "github.com/AshBuk/FingerGo/internal/domain"
+)
+
+// TextStore persists the text library (categories, text metadata and content).
+type TextStore interface {
+	Library() (domain.TextLibrary, error)
+	DefaultText() (domain.Text, error)
+	Text(id string) (domain.Text, error)
+	SaveText(text *domain.Text) error
+	SaveTextWithReport(text *domain.Text) (NormalizeReport, error)
+	UpdateText(text *domain.Text) error
+	UpdateTextWithReport(text *domain.Text) (NormalizeReport, error)
+	DeleteText(id string) error
+	Revisions(id string) ([]TextRevision, error)
+	Revision(id string, revision int) (TextRevision, error)
+	Progress(id string) (TextProgress, error)
+	SaveProgress(id string, progress TextProgress) error
+	ApplyBulk(ids []string, change BulkChange) ([]BulkResult, error)
+	SaveCategory(cat *domain.Category) error
+	UpdateCategory(cat *domain.Category) error
+	MoveCategory(id, parentID string, position int) error
+	DeleteCategory(id string) error
+	Search(q SearchQuery) (SearchResult, error)
+	Normalizer() *Normalizer
+	SetNormalizer(n *Normalizer)
+	RefreshMetrics() (int, error)
+}
+
+// SessionStore persists completed typing sessions.
+type SessionStore interface {
+	Record(payload *domain.SessionPayload) (domain.TypingSession, error)
+	List(limit int) ([]domain.TypingSession, error)
+}
+
+// SettingsStore persists user preferences.
+type SettingsStore interface {
+	Load() (domain.Settings, error)
+	Save(s domain.Settings) error
+	Update(key string, value any) error
+}
+
+// Compile-time checks that both backends satisfy the interfaces.
+var (
+	_ TextStore     = (*TextRepository)(nil)
+	_ SessionStore  = (*SessionRepository)(nil)
+	_ SettingsStore = (*SettingsRepository)(nil)
+	_ TextStore     = (*SQLiteTextRepository)(nil)
+	_ SessionStore  = (*SQLiteSessionRepository)(nil)
+	_ SettingsStore = (*SQLiteSettingsRepository)(nil)
+)
+
+// Backend identifies a storage implementation.
+type Backend string
+
+// Available backends.
+const (
+	BackendJSON   Backend = "json"   // JSON documents and content files (default)
+	BackendSQLite Backend = "sqlite" // single fingergo.db database
+)
+
+// BackendEnv overrides backend detection when set to "json" or "sqlite".
+const BackendEnv = "FINGERGO_BACKEND"
+
+// ParseBackend converts a user-supplied name into a Backend.
+func ParseBackend(name string) (Backend, error) {
+	switch b := Backend(strings.ToLower(strings.TrimSpace(name))); b {
+	case BackendJSON, BackendSQLite:
+		return b, nil
+	default:
+		return "", fmt.Errorf("storage: unknown backend %q (want %q or %q)", name, BackendJSON, BackendSQLite)
+	}
+}
+
+// SelectBackend picks the backend for a data directory: FINGERGO_BACKEND if set,
+// otherwise SQLite when {root}/fingergo.db exists, otherwise JSON.
+func SelectBackend(root string) (Backend, error) {
+	if name := os.Getenv(BackendEnv); name != "" {
+		return ParseBackend(name)
+	}
+	if _, err := os.Stat(filepath.Join(root, sqliteFile)); err == nil {
+		return BackendSQLite, nil
+	}
+	return BackendJSON, nil
+}
@@ -0,0 +1,270 @@
+// Copyright 2025 Asher Buk
+// SPDX-License-Identifier: Apache-2.0
+// https://github.com/AshBuk/FingerGo
+
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	domain "github.com/AshBuk/FingerGo/internal/domain"
+)
+
+// Revision history limits.
+const (
+	maxTextRevisions = 20        // revisions kept per text, the current one included
+	maxDiffCells     = 4_000_000 // LCS table size above which diffLines replaces wholesale
+)
+
+// ErrRevisionNotFound is returned for revisions that never existed or were
+// dropped from the bounded history.
+var ErrRevisionNotFound = errors.New("storage: text revision not found")
+
+// TextRevision is one version of a text's content. Both backends record a
+// revision on SaveText and on every UpdateText that changes the content.
+type TextRevision struct {
+	SavedAt  time.Time `json:"savedAt"`           // when this content was saved
+	Content  string    `json:"content,omitempty"` // empty in TextStore.Revisions listings
+	Revision int       `json:"revision"`          // matches Text.Revision while current
+	Length   int       `json:"length"`            // characters
+}
+
+// DiffOp tells how a line of a diff relates the two revisions.
+type DiffOp string
+
+// Diff line operations.
+const (
+	DiffEqual  DiffOp = "equal"  // in both revisions
+	DiffDelete DiffOp = "delete" // only in the older (from) revision
+	DiffInsert DiffOp = "insert" // only in the newer (to) revision
+)
+
+// DiffLine is one line of a line diff.
+type DiffLine struct {
+	Op   DiffOp `json:"op"`
+	Text string `json:"text"`
+}
+
+// newRevision returns the revision entry for content saved now.
+func newRevision(revision int, content string) TextRevision {
+	return TextRevision{
+		SavedAt:  time.Now().UTC(),
+		Content:  content,
+		Revision: revision,
+		Length:   utf8.RuneCountInString(content),
+	}
+}
+
+// firstRevision makes text revision 1 and returns its history entry.
+func firstRevision(text *domain.Text) TextRevision {
+	first := newRevision(1, text.Content)
+	text.Revision, text.RevisedAt = first.Revision, first.SavedAt
+	return first
+}
+
+// revisedAt returns when the current content of text was saved, or its
+// creation time for texts saved before that was recorded.
+func revisedAt(text *domain.Text) time.Time {
+	if text.RevisedAt.IsZero() {
+		return text.CreatedAt
+	}
+	return text.RevisedAt
+}
+
+// nextRevision sets text.Revision and text.RevisedAt for an update of prev
+// (with content) and returns the entries to record: none if the content is
+// unchanged, otherwise the replaced content (for histories that lack it, e.g.
+// texts saved before revisions existed) and the new one.
+func nextRevision(prev, text *domain.Text) (replaced, added *TextRevision) {
+	text.Revision, text.RevisedAt = prev.Revision, prev.RevisedAt
+	if text.Content == prev.Content {
+		return nil, nil
+	}
+	old := TextRevision{
+		SavedAt:  revisedAt(prev),
+		Content:  prev.Content,
+		Revision: prev.Revision,
+		Length:   utf8.RuneCountInString(prev.Content),
+	}
+	next := newRevision(prev.Revision+1, text.Content)
+	text.Revision, text.RevisedAt = next.Revision, next.SavedAt
+	return &old, &next
+}
+
+// appendRevision returns history (oldest first) with next appended and
+// entries outside the retained window dropped. replaced is kept unless the
+// history already has its revision. Entries at or past next, left behind by
+// an update that failed after its history write, are overwritten.
+func appendRevision(history []TextRevision, replaced *TextRevision, next TextRevision) []TextRevision {
+	oldest := next.Revision - maxTextRevisions
+	out := make([]TextRevision, 0, min(len(history)+2, maxTextRevisions))
+	for _, r := range history {
+		if r.Revision > oldest && r.Revision < next.Revision {
+			out = append(out, r)
+		}
+	}
+	if replaced != nil && replaced.Revision > oldest &&
+		!slices.ContainsFunc(out, func(r TextRevision) bool { return r.Revision == replaced.Revision }) {
+		out = append(out, *replaced)
+		slices.SortFunc(out, func(a, b TextRevision) int { return a.Revision - b.Revision })
+	}
+	return append(out, next)
+}
+
+// withCurrent returns history (oldest first) up to the current content of
+// text, adding it for texts saved before revisions existed. Entries past the
+// current revision come from an update that failed and are dropped.
+func withCurrent(history []TextRevision, text *domain.Text) []TextRevision {
+	history = slices.DeleteFunc(slices.Clone(history), func(r TextRevision) bool { return r.Revision > text.Revision })
+	if slices.ContainsFunc(history, func(r TextRevision) bool { return r.Revision == text.Revision }) {
+		return history
+	}
+	current := TextRevision{
+		SavedAt:  revisedAt(text),
+		Content:  text.Content,
+		Revision: text.Revision,
+		Length:   utf8.RuneCountInString(text.Content),
+	}
+	return append(history, current)
+}
+
+// revisionListing returns history newest first without content.
+func revisionListing(history []TextRevision) []TextRevision {
+	out := make([]TextRevision, len(history))
+	for i, r := range history {
+		r.Content = ""
+		out[len(history)-1-i] = r
+	}
+	return out
+}
+
+// findRevision returns revision of text id from history.
+func findRevision(history []TextRevision, id string, revision int) (TextRevision, error) {
+	idx := slices.IndexFunc(history, func(r TextRevision) bool { return r.Revision == revision })
+	if idx < 0 {
+		return TextRevision{}, fmt.Errorf("%w: %s@%d", ErrRevisionNotFound, id, revision)
+	}
+	return history[idx], nil
+}
+
+// DiffRevisions returns the line diff from revision from to revision to of
+// text id.
+func DiffRevisions(store TextStore, id string, from, to int) ([]DiffLine, error) {
+	a, err := store.Revision(id, from)
+	if err != nil {
+		return nil, err
+	}
+	b, err := store.Revision(id, to)
+	if err != nil {
+		return nil, err
+	}
+	return diffLines(a.Content, b.Content), nil
+}
+
+// RestoreRevision makes the content of an earlier revision current again. The
+// restore is saved as a new revision, so the replaced content stays in the
+// history.
+func RestoreRevision(store TextStore, id string, revision int) (domain.Text, error) {
+	rev, err := store.Revision(id, revision)
+	if err != nil {
+		return domain.Text{}, err
+	}
+	text, err := store.Text(id)
+	if err != nil {
+		return domain.Text{}, err
+	}
+	text.Content = rev.Content
+	if err := store.UpdateText(&text); err != nil {
+		return domain.Text{}, err
+	}
+	return text, nil
+}
+
+// diffLines computes a line diff of a and b through their longest common
+// subsequence, after trimming the common prefix and suffix. Inputs too large
+// for the LCS table are shown as a full replacement.
+func diffLines(a, b string) []DiffLine {
+	head, x, y, tail := trimCommonLines(splitLines(a), splitLines(b))
+	if (len(x)+1)*(len(y)+1) > maxDiffCells {
+		return append(replaceLines(head, x, y), tail...)
+	}
+	return append(lcsDiff(head, x, y), tail...)
+}
+
+// trimCommonLines splits the lines shared at the start and at the end of x
+// and y off as equal diff lines and returns what remains in between.
+func trimCommonLines(x, y []string) (head []DiffLine, restX, restY []string, tail []DiffLine) {
+	for len(x) > 0 && len(y) > 0 && x[0] == y[0] {
+		head = append(head, DiffLine{Op: DiffEqual, Text: x[0]})
+		x, y = x[1:], y[1:]
+	}
+	for len(x) > 0 && len(y) > 0 && x[len(x)-1] == y[len(y)-1] {
+		tail = append(tail, DiffLine{Op: DiffEqual, Text: x[len(x)-1]})
+		x, y = x[:len(x)-1], y[:len(y)-1]
+	}
+	slices.Reverse(tail)
+	return head, x, y, tail
+}
+
+// replaceLines appends the deletion of x and the insertion of y to out.
+func replaceLines(out []DiffLine, x, y []string) []DiffLine {
+	for _, line := range x {
+		out = append(out, DiffLine{Op: DiffDelete, Text: line})
+	}
+	for _, line := range y {
+		out = append(out, DiffLine{Op: DiffInsert, Text: line})
+	}
+	return out
+}
+
+// lcsDiff appends the diff of x and y to out, walking their LCS table.
+func lcsDiff(out []DiffLine, x, y []string) []DiffLine {
+	lcs := lcsTable(x, y)
+	i, j := 0, 0
+	for i < len(x) || j < len(y) {
+		switch {
+		case i < len(x) && j < len(y) && x[i] == y[j]:
+			out = append(out, DiffLine{Op: DiffEqual, Text: x[i]})
+			i, j = i+1, j+1
+		case j == len(y) || i < len(x) && lcs[i+1][j] >= lcs[i][j+1]:
+			out = append(out, DiffLine{Op: DiffDelete, Text: x[i]})
+			i++
+		default:
+			out = append(out, DiffLine{Op: DiffInsert, Text: y[j]})
+			j++
+		}
+	}
+	return out
+}
+
+// lcsTable returns lcs where lcs[i][j] is the LCS length of x[i:] and y[j:].
+func lcsTable(x, y []string) [][]int32 {
+	lcs := make([][]int32, len(x)+1)
+	for i := range lcs {
+		lcs[i] = make([]int32, len(y)+1)
+	}
+	for i := len(x) - 1; i >= 0; i-- {
+		for j := len(y) - 1; j >= 0; j-- {
+			if x[i] == y[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+	return lcs
+}
+
+// splitLines splits s into lines without their terminators; a trailing
+// newline does not start an extra empty line.
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
@@ -0,0 +1,200 @@
+// Copyright 2025 Asher Buk
+// SPDX-License-Identifier: Apache-2.0
+// https://github.com/AshBuk/FingerGo
+
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	domain "github.com/AshBuk/FingerGo/internal/domain"
+)
+
+// revisionNumbers returns the revision numbers listed for a text, newest first.
+func revisionNumbers(t *testing.T, s TextStore, id string) []int {
+	t.Helper()
+	revs, err := s.Revisions(id)
+	if err != nil {
+		t.Fatalf("Revisions(%s) error: %v", id, err)
+	}
+	nums := make([]int, len(revs))
+	for i, r := range revs {
+		if r.Content != "" {
+			t.Errorf("Revisions(%s) listed content for revision %d", id, r.Revision)
+		}
+		nums[i] = r.Revision
+	}
+	return nums
+}
+
+func TestStores_Revisions(t *testing.T) {
+	for name, open := range backends(t) {
+		t.Run(string(name), func(t *testing.T) {
+			s := open(t).texts
+			text := &domain.Text{ID: "snip", Title: "Snip", Content: "a\nb\nc\n"}
+			if err := s.SaveText(text); err != nil {
+				t.Fatalf("SaveText() error: %v", err)
+			}
+			update := func(title, content string) {
+				t.Helper()
+				text.Title, text.Content = title, content
+				if err := s.UpdateText(text); err != nil {
+					t.Fatalf("UpdateText() error: %v", err)
+				}
+			}
+			update("Renamed", "a\nb\nc\n") // metadata only: no new revision
+			if got := revisionNumbers(t, s, "snip"); !slices.Equal(got, []int{1}) {
+				t.Errorf("after title change: revisions = %v, want [1]", got)
+			}
+			update("Renamed", "a\nB\nc\n")
+			update("Renamed", "a\nB\nc\nd\n")
+			if got := revisionNumbers(t, s, "snip"); !slices.Equal(got, []int{3, 2, 1}) {
+				t.Errorf("revisions = %v, want [3 2 1]", got)
+			}
+			stored, _ := s.Text("snip")
+			if stored.Revision != 3 {
+				t.Errorf("Text().Revision = %d, want 3", stored.Revision)
+			}
+			if rev, _ := s.Revision("snip", 3); !stored.RevisedAt.Equal(rev.SavedAt) {
+				t.Errorf("Text().RevisedAt = %v, want the save time of revision 3 (%v)", stored.RevisedAt, rev.SavedAt)
+			}
+
+			diff, err := DiffRevisions(s, "snip", 1, 3)
+			if err != nil {
+				t.Fatalf("DiffRevisions() error: %v", err)
+			}
+			want := []DiffLine{{DiffEqual, "a"}, {DiffDelete, "b"}, {DiffInsert, "B"}, {DiffEqual, "c"}, {DiffInsert, "d"}}
+			if !slices.Equal(diff, want) {
+				t.Errorf("DiffRevisions(1, 3) = %v, want %v", diff, want)
+			}
+
+			restored, err := RestoreRevision(s, "snip", 1)
+			if err != nil {
+				t.Fatalf("RestoreRevision() error: %v", err)
+			}
+			if restored.Revision != 4 || restored.Content != "a\nb\nc\n" || restored.Title != "Renamed" {
+				t.Errorf("restored = %+v, want revision 4 with the first content", restored)
+			}
+			if rev, err := s.Revision("snip", 3); err != nil || rev.Content != "a\nB\nc\nd\n" || rev.Length != 8 {
+				t.Errorf("Revision(3) = %+v, %v", rev, err)
+			}
+			if _, err := s.Revision("snip", 9); !errors.Is(err, ErrRevisionNotFound) {
+				t.Errorf("Revision(9): expected ErrRevisionNotFound, got %v", err)
+			}
+
+			if err := s.DeleteText("snip"); err != nil {
+				t.Fatalf("DeleteText() error: %v", err)
+			}
+			if err := s.SaveText(&domain.Text{ID: "snip", Title: "Again", Content: "new"}); err != nil {
+				t.Fatalf("SaveText() error: %v", err)
+			}
+			if got := revisionNumbers(t, s, "snip"); !slices.Equal(got, []int{1}) {
+				t.Errorf("revisions of a reused ID = %v, want a fresh history", got)
+			}
+		})
+	}
+}
+
+func TestStores_RevisionsBounded(t *testing.T) {
+	for name, open := range backends(t) {
+		t.Run(string(name), func(t *testing.T) {
+			s := open(t).texts
+			text := &domain.Text{ID: "busy", Title: "Busy", Content: "v1"}
+			if err := s.SaveText(text); err != nil {
+				t.Fatalf("SaveText() error: %v", err)
+			}
+			last := maxTextRevisions + 5
+			for i := 2; i <= last; i++ {
+				text.Content = fmt.Sprintf("v%d", i)
+				if err := s.UpdateText(text); err != nil {
+					t.Fatalf("UpdateText(%d) error: %v", i, err)
+				}
+			}
+			got := revisionNumbers(t, s, "busy")
+			if len(got) != maxTextRevisions || got[0] != last || got[len(got)-1] != last-maxTextRevisions+1 {
+				t.Errorf("revisions = %v, want the %d newest", got, maxTextRevisions)
+			}
+			if _, err := s.Revision("busy", 1); !errors.Is(err, ErrRevisionNotFound) {
+				t.Errorf("dropped Revision(1): expected ErrRevisionNotFound, got %v", err)
+			}
+		})
+	}
+}
+
+func TestStores_RevisionsOfOlderText(t *testing.T) {
+	for name, open := range backends(t) {
+		t.Run(string(name), func(t *testing.T) {
+			s := open(t).texts
+			// Texts written before revisions existed carry revision 0 and no history
+			text, err := s.Text("quick-sort")
+			if err != nil {
+				t.Fatalf("Text() error: %v", err)
+			}
+			if got := revisionNumbers(t, s, "quick-sort"); !slices.Equal(got, []int{text.Revision}) {
+				t.Errorf("revisions = %v, want only the current %d", got, text.Revision)
+			}
+			original := text.Content
+			text.Content = "edited"
+			if err := s.UpdateText(&text); err != nil {
+				t.Fatalf("UpdateText() error: %v", err)
+			}
+			if rev, err := s.Revision("quick-sort", text.Revision-1); err != nil || rev.Content != original {
+				t.Errorf("Revision(%d) = %+v, %v; want the original content", text.Revision-1, rev, err)
+			}
+		})
+	}
+}
+
+func TestNextRevision(t *testing.T) {
+	created := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	revised := created.Add(48 * time.Hour)
+	prev := &domain.Text{Content: "old", Revision: 4, CreatedAt: created, RevisedAt: revised}
+
+	same := &domain.Text{Content: "old"}
+	if replaced, added := nextRevision(prev, same); replaced != nil || added != nil {
+		t.Errorf("unchanged content recorded %+v, %+v", replaced, added)
+	}
+	if same.Revision != 4 || !same.RevisedAt.Equal(revised) {
+		t.Errorf("unchanged content: revision %d at %v, want 4 at %v", same.Revision, same.RevisedAt, revised)
+	}
+
+	text := &domain.Text{Content: "new"}
+	replaced, added := nextRevision(prev, text)
+	if replaced == nil || added == nil {
+		t.Fatal("changed content recorded no revision")
+	}
+	if replaced.Revision != 4 || !replaced.SavedAt.Equal(revised) {
+		t.Errorf("replaced = revision %d at %v, want 4 at %v", replaced.Revision, replaced.SavedAt, revised)
+	}
+	if text.Revision != 5 || added.Revision != 5 || !text.RevisedAt.Equal(added.SavedAt) {
+		t.Errorf("text = revision %d at %v, added = %+v", text.Revision, text.RevisedAt, added)
+	}
+
+	// Texts saved before RevisedAt was recorded fall back to their creation time
+	prev.RevisedAt = time.Time{}
+	if replaced, _ := nextRevision(prev, &domain.Text{Content: "newer"}); !replaced.SavedAt.Equal(created) {
+		t.Errorf("replaced.SavedAt = %v, want the creation time %v", replaced.SavedAt, created)
+	}
+}
+
+func TestDiffLines(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want []DiffLine
+	}{
+		{"", "", nil},
+		{"x\n", "x", []DiffLine{{DiffEqual, "x"}}},
+		{"", "x\ny", []DiffLine{{DiffInsert, "x"}, {DiffInsert, "y"}}},
+		{"x\ny", "", []DiffLine{{DiffDelete, "x"}, {DiffDelete, "y"}}},
+		{"a\nx\nb\ny\nc", "a\nb\nc", []DiffLine{{DiffEqual, "a"}, {DiffDelete, "x"}, {DiffEqual, "b"}, {DiffDelete, "y"}, {DiffEqual, "c"}}},
+	}
+	for _, tc := range tests {
+		if got := diffLines(tc.a, tc.b); !slices.Equal(got, tc.want) {
+			t.Errorf("diffLines(%q, %q) = %v, want %v", tc.a, tc.b, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+// Copyright 2025 Asher Buk
+// SPDX-License-Identifier: Apache-2.0
+// https://github.com/AshBuk/FingerGo
+
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ErrSchemaTooNew is returned for documents written by a newer FingerGo.
+// Such files are left untouched: loading them would silently drop unknown fields.
+var ErrSchemaTooNew = errors.New("storage: data file was written by a newer version")
+
+// envelope wraps every JSON document on disk with its schema version.
+//
+//	{"schemaVersion": 1, "data": <payload>}
+//
+// Files written before versioning carry the bare payload and count as version 0.
+type envelope struct {
+	SchemaVersion int             `json:"schemaVersion"`
+	Data          json.RawMessage `json:"data"`
+}
+
+// readEnvelope splits a raw document into version and payload.
+func readEnvelope(raw []byte) (envelope, error) {
+	clean := bytes.TrimSpace(raw)
+	if len(clean) > 0 && clean[0] == '{' {
+		var probe struct {
+			SchemaVersion *int            `json:"schemaVersion"`
+			Data          json.RawMessage `json:"data"`
+		}
+		if err := json.Unmarshal(clean, &probe); err != nil {
+			return envelope{}, err
+		}
+		if probe.SchemaVersion != nil {
+			return envelope{SchemaVersion: *probe.SchemaVersion, Data: probe.Data}, nil
+		}
+	}
+	return envelope{SchemaVersion: 0, Data: clean}, nil // legacy, unversioned
+}
+
+// decodeDocument unwraps relPath's envelope, upgrades the payload in memory
+// through any pending migrations and unmarshals it into v.
+func decodeDocument(relPath string, raw []byte, v any) error {
+	env, err := readEnvelope(raw)
+	if err != nil {
+		return err
+	}
+	data, _, err := upgradeDocument(relPath, env, nil)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// encodeDocument wraps v in an envelope stamped with relPath's current version.
+func encodeDocument(relPath string, v any) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return marshalEnvelope(envelope{SchemaVersion: schemaVersion(relPath), Data: data})
+}
+
+// marshalEnvelope renders an envelope in the indented on-disk format.
+func marshalEnvelope(env envelope) ([]byte, error) {
+	out, err := json.MarshalIndent(env, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("storage: marshal envelope: %w", err)
+	}
+	return out, nil
+}
@@ -0,0 +1,362 @@
+// Copyright 2025 Asher Buk
+// SPDX-License-Identifier: Apache-2.0
+// https://github.com/AshBuk/FingerGo
+
+package storage
+
+import (
+	"math"
+	"slices"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	domain "github.com/AshBuk/FingerGo/internal/domain"
+)
+
+// Search defaults.
+const (
+	defaultSearchLimit = 50
+	maxSearchLimit     = 500
+	titleWeight        = 3   // a title occurrence counts like this many content occurrences
+	prefixWeight       = 0.5 // "sor" finding "sort" scores half an exact match
+	snippetBefore      = 40  // runes of context before the first match
+	snippetLength      = 160 // runes per snippet
+)
+
+// SearchQuery selects texts for TextStore.Search. All set filters must match.
+type SearchQuery struct {
+	Query      string `json:"query"`                // words matched against titles and content; empty matches all texts
+	Language   string `json:"language,omitempty"`   // exact language key
+	CategoryID string `json:"categoryId,omitempty"` // category and all its subcategories
+	MinLength  int    `json:"minLength,omitempty"`  // content length in characters
+	MaxLength  int    `json:"maxLength,omitempty"`  // content length in characters (0 = no limit)
+	Limit      int    `json:"limit,omitempty"`      // hits returned (default 50, at most 500)
+	Favorites  bool   `json:"favorites,omitempty"`  // favourites only
+}
+
+// SearchHit is one matching text.
+type SearchHit struct {
+	Text    domain.Text `json:"text"`    // metadata, content stripped
+	Snippet string      `json:"snippet"` // content around the first match, whitespace collapsed
+	Score   float64     `json:"score"`   // relevance; 0 for queries without words
+	Length  int         `json:"length"`  // content length in characters
+}
+
+// SearchResult is the ranked outcome of a search.
+type SearchResult struct {
+	Hits  []SearchHit `json:"hits"`
+	Total int         `json:"total"` // matching texts before Limit
+}
+
+// termFreq counts the occurrences of a term in one text.
+type termFreq struct {
+	title   int
+	content int
+}
+
+// searchDoc is what the index keeps per text.
+type searchDoc struct {
+	text   domain.Text // metadata, content stripped
+	terms  []string    // distinct terms, for removal
+	length int         // content length in runes
+}
+
+// searchIndex is an in-memory inverted index over text titles and content.
+// It is built on the first search and then kept current by the repository's
+// mutations; reset drops it when the library changes wholesale (external
+// edits, restores). Terms are lowercase words; camelCase words are also
+// indexed by their parts, so "sort" finds quickSort.
+type searchIndex struct {
+	docs     map[string]*searchDoc          // text ID → doc; nil until built
+	postings map[string]map[string]termFreq // term → text ID → frequencies
+	mu       sync.Mutex
+}
+
+// ensure builds the index from load (texts with content) unless it is built.
+// Caller must hold ix.mu.
+func (ix *searchIndex) ensure(load func() ([]domain.Text, error)) error {
+	if ix.docs != nil {
+		return nil
+	}
+	texts, err := load()
+	if err != nil {
+		return err
+	}
+	ix.docs = make(map[string]*searchDoc, len(texts))
+	ix.postings = make(map[string]map[string]termFreq)
+	for i := range texts {
+		ix.add(&texts[i])
+	}
+	return nil
+}
+
+// put indexes text (with content), replacing an earlier version. A no-op
+// while the index is not built. Caller must hold ix.mu.
+func (ix *searchIndex) put(text *domain.Text) {
+	if ix.docs == nil {
+		return
+	}
+	ix.remove(text.ID)
+	ix.add(text)
+}
+
+// remove drops a text from the index. Caller must hold ix.mu.
+func (ix *searchIndex) remove(id string) {
+	doc, ok := ix.docs[id]
+	if !ok {
+		return
+	}
+	for _, term := range doc.terms {
+		delete(ix.postings[term], id)
+		if len(ix.postings[term]) == 0 {
+			delete(ix.postings, term)
+		}
+	}
+	delete(ix.docs, id)
+}
+
+// removeCategory drops the texts of a deleted category. Caller must hold ix.mu.
+func (ix *searchIndex) removeCategory(id string) {
+	for textID, doc := range ix.docs {
+		if doc.text.CategoryID == id {
+			ix.remove(textID)
+		}
+	}
+}
+
+// reset discards the index; the next search rebuilds it.
+func (ix *searchIndex) reset() {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	ix.docs, ix.postings = nil, nil
+}
+
+func (ix *searchIndex) add(text *domain.Text) {
+	freqs := make(map[string]termFreq)
+	tokenize(text.Title, true, func(term string) {
+		f := freqs[term]
+		f.title++
+		freqs[term] = f
+	})
+	tokenize(text.Content, true, func(term string) {
+		f := freqs[term]
+		f.content++
+		freqs[term] = f
+	})
+	doc := &searchDoc{text: *text, terms: make([]string, 0, len(freqs)), length: utf8.RuneCountInString(text.Content)}
+	doc.text.Content = ""
+	for term, f := range freqs {
+		if ix.postings[term] == nil {
+			ix.postings[term] = make(map[string]termFreq)
+		}
+		ix.postings[term][text.ID] = f
+		doc.terms = append(doc.terms, term)
+	}
+	ix.docs[text.ID] = doc
+}
+
+// search ranks the indexed texts for q. Every query word must occur in a
+// text, as a whole term or as a term prefix; scores are TF-IDF with title
+// matches weighted up. Texts without query words are ordered by title.
+// content supplies snippets for the returned hits. Caller must hold ix.mu.
+func (ix *searchIndex) search(q SearchQuery, categories []domain.Category, content func(id string) (string, error)) (SearchResult, error) {
+	var result SearchResult
+	var inCategory map[string]bool
+	if q.CategoryID != "" {
+		var err error
+		if inCategory, err = categorySubtrees(categories, []string{q.CategoryID}); err != nil {
+			return result, err
+		}
+	}
+	words := queryWords(q.Query)
+	scores := ix.score(words)
+	for id, doc := range ix.docs {
+		if words != nil && scores[id] == 0 || !q.matches(doc, inCategory) {
+			continue
+		}
+		result.Hits = append(result.Hits, SearchHit{Text: doc.text, Score: scores[id], Length: doc.length})
+	}
+	slices.SortFunc(result.Hits, func(a, b SearchHit) int {
+		if a.Score != b.Score {
+			return cmpFloatDesc(a.Score, b.Score)
+		}
+		if c := strings.Compare(strings.ToLower(a.Text.Title), strings.ToLower(b.Text.Title)); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Text.ID, b.Text.ID)
+	})
+	result.Total = len(result.Hits)
+	limit := q.Limit
+	if limit <= 0 {
+		limit = defaultSearchLimit
+	}
+	result.Hits = result.Hits[:min(len(result.Hits), limit, maxSearchLimit)]
+	for i := range result.Hits {
+		body, err := content(result.Hits[i].Text.ID)
+		if err != nil {
+			return result, err
+		}
+		result.Hits[i].Snippet = snippet(body, words)
+	}
+	return result, nil
+}
+
+// score returns the relevance of every text containing all words.
+func (ix *searchIndex) score(words []string) map[string]float64 {
+	if len(words) == 0 {
+		return nil
+	}
+	n := float64(len(ix.docs))
+	var scores map[string]float64
+	for _, word := range words {
+		wordScores := make(map[string]float64)
+		for term, docs := range ix.postings {
+			weight := 1.0
+			if term != word {
+				if !strings.HasPrefix(term, word) {
+					continue
+				}
+				weight = prefixWeight
+			}
+			idf := math.Log(1 + n/float64(len(docs)))
+			for id, f := range docs {
+				tf := float64(titleWeight * f.title)
+				if f.content > 0 {
+					tf += 1 + math.Log(float64(f.content))
+				}
+				wordScores[id] += weight * tf * idf
+			}
+		}
+		// Intersect: a text must match every word
+		if scores == nil {
+			scores = wordScores
+			continue
+		}
+		for id := range scores {
+			if s, ok := wordScores[id]; ok {
+				scores[id] += s
+			} else {
+				delete(scores, id)
+			}
+		}
+	}
+	return scores
+}
+
+// matches applies the non-text filters of q to doc.
+func (q *SearchQuery) matches(doc *searchDoc, inCategory map[string]bool) bool {
+	switch {
+	case q.Language != "" && doc.text.Language != q.Language,
+		inCategory != nil && !inCategory[doc.text.CategoryID],
+		q.Favorites && !doc.text.IsFavorite,
+		doc.length < q.MinLength,
+		q.MaxLength > 0 && doc.length > q.MaxLength:
+		return false
+	}
+	return true
+}
+
+// tokenize calls fn with every lowercase word of s. With parts, camelCase
+// and digit-separated words are also reported by their parts.
+func tokenize(s string, parts bool, fn func(term string)) {
+	isSep := func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) }
+	for _, word := range strings.FieldsFunc(s, isSep) {
+		fn(strings.ToLower(word))
+		if !parts {
+			continue
+		}
+		if split := splitCamel(word); len(split) > 1 {
+			for _, part := range split {
+				fn(strings.ToLower(part))
+			}
+		}
+	}
+}
+
+// splitCamel splits "parseHTTPRequest2" into "parse", "HTTP", "Request", "2".
+func splitCamel(word string) []string {
+	runes := []rune(word)
+	var parts []string
+	start := 0
+	for i := 1; i < len(runes); i++ {
+		prev, cur := runes[i-1], runes[i]
+		boundary := unicode.IsLower(prev) && unicode.IsUpper(cur) ||
+			unicode.IsDigit(prev) != unicode.IsDigit(cur) ||
+			i+1 < len(runes) && unicode.IsUpper(prev) && unicode.IsUpper(cur) && unicode.IsLower(runes[i+1])
+		if boundary {
+			parts = append(parts, string(runes[start:i]))
+			start = i
+		}
+	}
+	return append(parts, string(runes[start:]))
+}
+
+// queryWords returns the distinct lowercase words of a query, nil if none.
+func queryWords(query string) []string {
+	var words []string
+	tokenize(query, false, func(term string) {
+		if !slices.Contains(words, term) {
+			words = append(words, term)
+		}
+	})
+	return words
+}
+
+// snippet cuts about snippetLength runes of content around the first
+// occurrence of any word (the start of content without words).
+func snippet(content string, words []string) string {
+	runes := []rune(content)
+	start := 0
+	if pos := firstMatch(runes, words); pos > snippetBefore {
+		start = pos - snippetBefore
+		// Begin at a word boundary
+		for i := start; i < pos; i++ {
+			if unicode.IsSpace(runes[i]) {
+				start = i + 1
+				break
+			}
+		}
+	}
+	end := min(len(runes), start+snippetLength)
+	out := strings.Join(strings.Fields(string(runes[start:end])), " ")
+	if start > 0 {
+		out = "…" + out
+	}
+	if end < len(runes) {
+		out += "…"
+	}
+	return out
+}
+
+// firstMatch returns the rune offset of the earliest case-insensitive
+// occurrence of any word in runes, or -1.
+func firstMatch(runes []rune, words []string) int {
+	best := -1
+	for _, word := range words {
+		needle := []rune(word)
+		limit := len(runes) - len(needle)
+		if best >= 0 {
+			limit = min(limit, best-1)
+		}
+	scan:
+		for i := 0; i <= limit; i++ {
+			for j, r := range needle {
+				if unicode.ToLower(runes[i+j]) != r {
+					continue scan
+				}
+			}
+			best = i
+			break
+		}
+	}
+	return best
+}
+
+func cmpFloatDesc(a, b float64) int {
+	if a > b {
+		return -1
+	}
+	return 1
+}
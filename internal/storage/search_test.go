@@ -0,0 +1,179 @@
+// Copyright 2025 Asher Buk
+// SPDX-License-Identifier: Apache-2.0
+// https://github.com/AshBuk/FingerGo
+
+package storage
+
+import (
+	"errors"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+
+	domain "github.com/AshBuk/FingerGo/internal/domain"
+)
+
+// Search fixtures: Algorithms > Sorting with a text each, plus an
+// uncategorized essay. The welcome library contributes quick-sort.
+var (
+	searchCategories = []domain.Category{{ID: "algo", Name: "Algorithms"}, {ID: "sorting", Name: "Sorting", ParentID: "algo"}}
+	searchTexts      = []domain.Text{
+		{ID: "bubble", Title: "Bubble Sort", Content: "func bubbleSort(a []int) {\n\tswap(a, 0, 1)\n}", CategoryID: "algo", Language: "go"},
+		{ID: "merge", Title: "Merge Sort", Content: "def merge_sort(items):\n    return merged(items)", CategoryID: "sorting", Language: "py", IsFavorite: true},
+		{ID: "essay", Title: "On Hats", Content: strings.Repeat("The hat is red and the hat is old. ", 5) + "A hat of this sort is rare.", Language: "english"},
+	}
+)
+
+func hitIDs(res SearchResult) []string {
+	ids := make([]string, len(res.Hits))
+	for i, h := range res.Hits {
+		ids[i] = h.Text.ID
+	}
+	return ids
+}
+
+func TestStores_Search(t *testing.T) {
+	for name, open := range backends(t) {
+		t.Run(string(name), func(t *testing.T) {
+			s := open(t)
+			fillLibrary(t, s.texts, searchCategories, searchTexts)
+			search := func(q SearchQuery) SearchResult {
+				t.Helper()
+				res, err := s.texts.Search(q)
+				if err != nil {
+					t.Fatalf("Search(%+v) error: %v", q, err)
+				}
+				return res
+			}
+
+			res := search(SearchQuery{Query: "sort"})
+			ids := hitIDs(res)
+			if res.Total != 4 || len(ids) != 4 {
+				t.Fatalf("Search(sort) = %v (total %d), want 4 hits", ids, res.Total)
+			}
+			// Title matches outrank the essay, which mentions "sort" once in passing
+			if ids[3] != "essay" {
+				t.Errorf("ranking = %v, want essay last", ids)
+			}
+			for _, h := range res.Hits {
+				if h.Text.Content != "" {
+					t.Errorf("hit %s carries content", h.Text.ID)
+				}
+				if !strings.Contains(strings.ToLower(h.Snippet), "sort") {
+					t.Errorf("snippet of %s = %q, want the match", h.Text.ID, h.Snippet)
+				}
+			}
+			essay := res.Hits[3]
+			if !strings.HasPrefix(essay.Snippet, "…") || essay.Length != len(strings.Repeat("The hat is red and the hat is old. ", 5))+len("A hat of this sort is rare.") {
+				t.Errorf("essay hit = %+v", essay)
+			}
+
+			cases := []struct {
+				name string
+				q    SearchQuery
+				want []string
+			}{
+				{"all words required", SearchQuery{Query: "merge SORT"}, []string{"merge"}},
+				{"prefix", SearchQuery{Query: "bubb"}, []string{"bubble"}},
+				{"camel case part", SearchQuery{Query: "quick"}, []string{"quick-sort"}},
+				{"language", SearchQuery{Query: "sort", Language: "go"}, []string{"bubble", "quick-sort"}},
+				{"category subtree", SearchQuery{Query: "sort", CategoryID: "algo"}, []string{"bubble", "merge"}},
+				{"subcategory", SearchQuery{CategoryID: "sorting"}, []string{"merge"}},
+				{"favorites", SearchQuery{Favorites: true}, []string{"merge"}},
+				{"length range", SearchQuery{MinLength: 40, MaxLength: 100}, []string{"bubble", "merge"}},
+				{"no match", SearchQuery{Query: "haskell"}, nil},
+			}
+			for _, tc := range cases {
+				got := hitIDs(search(tc.q))
+				slices.Sort(got)
+				if !slices.Equal(got, tc.want) && !(len(got) == 0 && len(tc.want) == 0) {
+					t.Errorf("%s: hits = %v, want %v", tc.name, got, tc.want)
+				}
+			}
+			if res := search(SearchQuery{Limit: 2}); len(res.Hits) != 2 || res.Total != 4 {
+				t.Errorf("Limit 2: %d hits, total %d", len(res.Hits), res.Total)
+			}
+			if _, err := s.texts.Search(SearchQuery{CategoryID: "nope"}); !errors.Is(err, ErrCategoryNotFound) {
+				t.Errorf("unknown category: expected ErrCategoryNotFound, got %v", err)
+			}
+
+			// The built index follows mutations
+			if err := s.texts.UpdateText(&domain.Text{ID: "bubble", Title: "Insertion", Content: "insert(a)", CategoryID: "algo", Language: "go"}); err != nil {
+				t.Fatalf("UpdateText() error: %v", err)
+			}
+			if err := s.texts.DeleteText("merge"); err != nil {
+				t.Fatalf("DeleteText() error: %v", err)
+			}
+			if err := s.texts.SaveText(&domain.Text{ID: "heap", Title: "Heap Sort", Content: "heapify", Language: "go"}); err != nil {
+				t.Fatalf("SaveText() error: %v", err)
+			}
+			got := hitIDs(search(SearchQuery{Query: "sort"}))
+			slices.Sort(got)
+			if want := []string{"essay", "heap", "quick-sort"}; !slices.Equal(got, want) {
+				t.Errorf("after mutations: hits = %v, want %v", got, want)
+			}
+			if err := s.texts.DeleteCategory("algo"); err != nil {
+				t.Fatalf("DeleteCategory() error: %v", err)
+			}
+			if got := hitIDs(search(SearchQuery{Query: "insertion"})); len(got) != 0 {
+				t.Errorf("text of deleted category still found: %v", got)
+			}
+		})
+	}
+}
+
+func TestTextRepository_SearchExternalEdit(t *testing.T) {
+	repo := setupTextRepository(t)
+	if err := repo.SaveText(&domain.Text{ID: "t1", Title: "T1", Content: "before"}); err != nil {
+		t.Fatalf("SaveText: %v", err)
+	}
+	if res, _ := repo.Search(SearchQuery{Query: "before"}); res.Total != 1 {
+		t.Fatalf("Search(before) total = %d, want 1", res.Total)
+	}
+	prev := repo.scanFiles()
+	if err := os.WriteFile(repo.storage.join(contentPath("t1")), []byte("after edit"), 0o600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	if _, err := repo.reloadExternal(prev, repo.scanFiles()); err != nil {
+		t.Fatalf("reloadExternal: %v", err)
+	}
+	if res, _ := repo.Search(SearchQuery{Query: "before"}); res.Total != 0 {
+		t.Errorf("Search(before) total = %d after external edit, want 0", res.Total)
+	}
+	if res, _ := repo.Search(SearchQuery{Query: "edit"}); res.Total != 1 || res.Hits[0].Snippet != "after edit" {
+		t.Errorf("Search(edit) = %+v", res)
+	}
+}
+
+func TestSplitCamel(t *testing.T) {
+	tests := map[string][]string{
+		"quickSort":         {"quick", "Sort"},
+		"parseHTTPRequest2": {"parse", "HTTP", "Request", "2"},
+		"plain":             {"plain"},
+		"ID":                {"ID"},
+	}
+	for word, want := range tests {
+		if got := splitCamel(word); !slices.Equal(got, want) {
+			t.Errorf("splitCamel(%q) = %q, want %q", word, got, want)
+		}
+	}
+}
+
+func TestSnippet(t *testing.T) {
+	long := strings.Repeat("lorem ipsum ", 20)
+	tests := []struct {
+		name, content, want string
+		words               []string
+	}{
+		{"short content", "fmt.Println(x)", "fmt.Println(x)", []string{"println"}},
+		{"whitespace collapsed", "a\n\t b", "a b", nil},
+		{"match far in", long + "needle here", "…lorem ipsum lorem ipsum lorem ipsum needle here", []string{"needle"}},
+		{"no words cuts the start", long, strings.TrimSpace(long[:snippetLength]) + "…", nil},
+	}
+	for _, tc := range tests {
+		if got := snippet(tc.content, tc.words); got != tc.want {
+			t.Errorf("%s: snippet() = %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
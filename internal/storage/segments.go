@@ -0,0 +1,378 @@
+// Copyright 2025 Asher Buk
+// SPDX-License-Identifier: Apache-2.0
+// https://github.com/AshBuk/FingerGo
+
+package storage
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	domain "github.com/AshBuk/FingerGo/internal/domain"
+)
+
+// Segmentation defaults and limits (Text.SegmentSize).
+const (
+	defaultSegmentParagraphs = 1
+	defaultSegmentLines      = 20
+	defaultSegmentChars      = 1000
+	minSegmentChars          = 20      // smaller budgets would split most words
+	maxSegmentSize           = 100_000 // paragraphs, lines or characters
+)
+
+// Segmentation errors.
+var (
+	ErrInvalidSegmentation = errors.New("storage: invalid text segmentation")
+	ErrSegmentNotFound     = errors.New("storage: text segment not found")
+)
+
+// Segment is one part of a chaptered text, typed as a session of its own.
+// Texts without a SegmentMode consist of a single segment.
+type Segment struct {
+	ID      string `json:"id"`                // hash of the content: survives edits elsewhere in the text
+	Content string `json:"content,omitempty"` // empty in TextSegments listings
+	Index   int    `json:"index"`             // position in the text (0-based)
+	Start   int    `json:"start"`             // byte offset in Text.Content
+	End     int    `json:"end"`               // byte offset just past the segment
+	Length  int    `json:"length"`            // characters
+}
+
+// TextProgress is the persisted cursor of a chaptered text: the segment the
+// next session starts with.
+type TextProgress struct {
+	UpdatedAt    time.Time `json:"updatedAt"`
+	SegmentID    string    `json:"segmentId"`    // empty before the first session
+	SegmentIndex int       `json:"segmentIndex"` // position of SegmentID when saved; used once the ID is gone
+	Finished     bool      `json:"finished"`     // the last segment was typed and the cursor went back to the first
+}
+
+// TextPosition is where the progress cursor of a text stands.
+type TextPosition struct {
+	Segment  Segment `json:"segment"`  // the segment to type next, with content
+	Total    int     `json:"total"`    // segments in the text
+	Finished bool    `json:"finished"` // see TextProgress.Finished
+}
+
+// span is a byte range of a text's content.
+type span struct{ start, end int }
+
+// validateSegmentation checks the segment mode and size of text.
+func validateSegmentation(text *domain.Text) error {
+	switch text.SegmentMode {
+	case "":
+		if text.SegmentSize != 0 {
+			return fmt.Errorf("%w: segment size without a segment mode", ErrInvalidSegmentation)
+		}
+		return nil
+	case domain.SegmentParagraphs, domain.SegmentLines, domain.SegmentChars:
+	default:
+		return fmt.Errorf("%w: unknown segment mode %q", ErrInvalidSegmentation, text.SegmentMode)
+	}
+	if text.SegmentSize < 0 || text.SegmentSize > maxSegmentSize {
+		return fmt.Errorf("%w: segment size out of range [0, %d]: %d", ErrInvalidSegmentation, maxSegmentSize, text.SegmentSize)
+	}
+	if text.SegmentMode == domain.SegmentChars && text.SegmentSize != 0 && text.SegmentSize < minSegmentChars {
+		return fmt.Errorf("%w: character budget below %d", ErrInvalidSegmentation, minSegmentChars)
+	}
+	return nil
+}
+
+// keepSegmentation gives text, an update of prev, the segmentation of prev
+// when it has none of its own: editors that do not know about segments
+// must not turn a chaptered text back into a single one.
+func keepSegmentation(prev, text *domain.Text) {
+	if text.SegmentMode == "" && text.SegmentSize == 0 {
+		text.SegmentMode, text.SegmentSize = prev.SegmentMode, prev.SegmentSize
+	}
+}
+
+// SplitSegments splits the content of text into its ordered segments. The
+// split only depends on the content and the segmentation, so it is the same
+// every time; content between segments (blank lines, line breaks) is not
+// typed.
+func SplitSegments(text *domain.Text) []Segment {
+	content := text.Content
+	var spans []span
+	switch text.SegmentMode {
+	case domain.SegmentParagraphs:
+		paragraphs := paragraphSpans(content, span{0, len(content)})
+		spans = groupSpans(paragraphs, segmentSize(text.SegmentSize, defaultSegmentParagraphs))
+	case domain.SegmentLines:
+		spans = lineGroups(content, segmentSize(text.SegmentSize, defaultSegmentLines))
+	case domain.SegmentChars:
+		spans = budgetSpans(content, segmentSize(text.SegmentSize, defaultSegmentChars))
+	}
+	if len(spans) == 0 {
+		spans = []span{{0, len(content)}}
+	}
+	segments := make([]Segment, len(spans))
+	seen := make(map[string]int, len(spans))
+	for i, s := range spans {
+		part := content[s.start:s.end]
+		segments[i] = Segment{
+			ID:      segmentID(part, seen),
+			Content: part,
+			Index:   i,
+			Start:   s.start,
+			End:     s.end,
+			Length:  utf8.RuneCountInString(part),
+		}
+	}
+	return segments
+}
+
+// segmentSize returns size, or def when size is unset.
+func segmentSize(size, def int) int {
+	if size <= 0 {
+		return def
+	}
+	return size
+}
+
+// segmentID hashes the content of a segment. Repeated content gets a
+// numbered suffix, counted in seen.
+func segmentID(content string, seen map[string]int) string {
+	sum := sha256.Sum256([]byte(content))
+	id := hex.EncodeToString(sum[:6])
+	seen[id]++
+	if n := seen[id]; n > 1 {
+		id += "-" + strconv.Itoa(n)
+	}
+	return id
+}
+
+// lineSpans returns the lines of content within s, without terminators.
+func lineSpans(content string, s span) []span {
+	var lines []span
+	for start := s.start; start < s.end; {
+		end := strings.IndexByte(content[start:s.end], '\n')
+		if end < 0 {
+			lines = append(lines, span{start, s.end})
+			break
+		}
+		lines = append(lines, span{start, start + end})
+		start += end + 1
+	}
+	return lines
+}
+
+// isBlank reports whether s holds only whitespace.
+func isBlank(content string, s span) bool {
+	return strings.TrimSpace(content[s.start:s.end]) == ""
+}
+
+// paragraphSpans returns the runs of non-blank lines of content within s.
+func paragraphSpans(content string, s span) []span {
+	var paragraphs []span
+	open := false
+	for _, line := range lineSpans(content, s) {
+		switch {
+		case isBlank(content, line):
+			open = false
+		case open:
+			paragraphs[len(paragraphs)-1].end = line.end
+		default:
+			paragraphs = append(paragraphs, line)
+			open = true
+		}
+	}
+	return paragraphs
+}
+
+// groupSpans joins every n consecutive spans into one.
+func groupSpans(spans []span, n int) []span {
+	var out []span
+	for i := 0; i < len(spans); i += n {
+		last := min(i+n, len(spans)) - 1
+		out = append(out, span{spans[i].start, spans[last].end})
+	}
+	return out
+}
+
+// lineGroups splits content into groups of n lines, leaving out blank lines
+// at the edges of a group and groups with no text at all.
+func lineGroups(content string, n int) []span {
+	lines := lineSpans(content, span{0, len(content)})
+	var out []span
+	for i := 0; i < len(lines); i += n {
+		group := lines[i:min(i+n, len(lines))]
+		for len(group) > 0 && isBlank(content, group[0]) {
+			group = group[1:]
+		}
+		for len(group) > 0 && isBlank(content, group[len(group)-1]) {
+			group = group[:len(group)-1]
+		}
+		if len(group) > 0 {
+			out = append(out, span{group[0].start, group[len(group)-1].end})
+		}
+	}
+	return out
+}
+
+// budgetSpans packs content into segments of at most budget characters.
+// Whole paragraphs are kept together where they fit; longer ones are split
+// at line breaks, and longer lines at spaces (or anywhere, for a word that
+// exceeds the budget on its own).
+func budgetSpans(content string, budget int) []span {
+	runes := func(s span) int { return utf8.RuneCountInString(content[s.start:s.end]) }
+	var pieces []span
+	for _, p := range paragraphSpans(content, span{0, len(content)}) {
+		if runes(p) <= budget {
+			pieces = append(pieces, p)
+			continue
+		}
+		for _, line := range lineSpans(content, p) {
+			switch {
+			case isBlank(content, line):
+			case runes(line) <= budget:
+				pieces = append(pieces, line)
+			default:
+				pieces = append(pieces, wordSpans(content, line, budget)...)
+			}
+		}
+	}
+	var out []span
+	length := 0 // characters of the last span in out
+	for _, p := range pieces {
+		if len(out) > 0 {
+			last := &out[len(out)-1]
+			if joined := length + runes(span{last.end, p.start}) + runes(p); joined <= budget {
+				last.end, length = p.end, joined
+				continue
+			}
+		}
+		out = append(out, p)
+		length = runes(p)
+	}
+	return out
+}
+
+// wordSpans splits the line s into chunks of at most budget characters,
+// breaking after the last space that fits. Spaces at chunk edges are left
+// out.
+func wordSpans(content string, s span, budget int) []span {
+	var out []span
+	start := s.start
+	for start < s.end {
+		for start < s.end && content[start] == ' ' {
+			start++
+		}
+		if start == s.end {
+			break
+		}
+		end, lastSpace, n := start, -1, 0
+		for end < s.end && n < budget {
+			_, size := utf8.DecodeRuneInString(content[end:s.end])
+			if content[end] == ' ' {
+				lastSpace = end
+			}
+			end += size
+			n++
+		}
+		if end < s.end && content[end] != ' ' && lastSpace > start {
+			end = lastSpace // break at the last space instead of inside a word
+		}
+		chunk := span{start, end}
+		for chunk.end > chunk.start && content[chunk.end-1] == ' ' {
+			chunk.end--
+		}
+		out = append(out, chunk)
+		start = end
+	}
+	return out
+}
+
+// cursorIndex returns the segment index progress points to: the segment
+// with its ID, or the saved position (clamped) when an edit replaced it.
+func cursorIndex(segments []Segment, progress TextProgress) int {
+	for _, s := range segments {
+		if progress.SegmentID != "" && s.ID == progress.SegmentID {
+			return s.Index
+		}
+	}
+	return max(0, min(progress.SegmentIndex, len(segments)-1))
+}
+
+// TextSegments returns the segments of text id without content.
+func TextSegments(store TextStore, id string) ([]Segment, error) {
+	text, err := store.Text(id)
+	if err != nil {
+		return nil, err
+	}
+	segments := SplitSegments(&text)
+	for i := range segments {
+		segments[i].Content = ""
+	}
+	return segments, nil
+}
+
+// CurrentSegment returns the segment the next session of text id starts
+// with, per its persisted progress.
+func CurrentSegment(store TextStore, id string) (TextPosition, error) {
+	text, err := store.Text(id)
+	if err != nil {
+		return TextPosition{}, err
+	}
+	progress, err := store.Progress(id)
+	if err != nil {
+		return TextPosition{}, err
+	}
+	segments := SplitSegments(&text)
+	return TextPosition{
+		Segment:  segments[cursorIndex(segments, progress)],
+		Total:    len(segments),
+		Finished: progress.Finished,
+	}, nil
+}
+
+// SeekSegment moves the progress cursor of text id to segmentID.
+func SeekSegment(store TextStore, id, segmentID string) (TextPosition, error) {
+	return moveProgress(store, id, segmentID, 0)
+}
+
+// AdvanceProgress moves the progress cursor of text id past segmentID, the
+// segment just typed. After the last segment it returns to the first one
+// and marks the text finished.
+func AdvanceProgress(store TextStore, id, segmentID string) (TextPosition, error) {
+	return moveProgress(store, id, segmentID, 1)
+}
+
+// moveProgress saves the cursor of text id at offset segments after segmentID.
+func moveProgress(store TextStore, id, segmentID string, offset int) (TextPosition, error) {
+	text, err := store.Text(id)
+	if err != nil {
+		return TextPosition{}, err
+	}
+	segments := SplitSegments(&text)
+	idx := -1
+	for _, s := range segments {
+		if s.ID == segmentID {
+			idx = s.Index
+			break
+		}
+	}
+	if idx < 0 {
+		return TextPosition{}, fmt.Errorf("%w: %s#%s", ErrSegmentNotFound, id, segmentID)
+	}
+	next := idx + offset
+	finished := next == len(segments)
+	if finished {
+		next = 0
+	}
+	progress := TextProgress{
+		UpdatedAt:    time.Now().UTC(),
+		SegmentID:    segments[next].ID,
+		SegmentIndex: next,
+		Finished:     finished,
+	}
+	if err := store.SaveProgress(id, progress); err != nil {
+		return TextPosition{}, err
+	}
+	return TextPosition{Segment: segments[next], Total: len(segments), Finished: finished}, nil
+}
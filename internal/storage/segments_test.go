@@ -0,0 +1,191 @@
+// Copyright 2025 Asher Buk
+// SPDX-License-Identifier: Apache-2.0
+// https://github.com/AshBuk/FingerGo
+
+package storage
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	domain "github.com/AshBuk/FingerGo/internal/domain"
+)
+
+// segmentContents returns the content of each segment of text.
+func segmentContents(text *domain.Text) []string {
+	var out []string
+	for _, s := range SplitSegments(text) {
+		out = append(out, s.Content)
+	}
+	return out
+}
+
+func TestSplitSegments(t *testing.T) {
+	const chapters = "One a.\nOne b.\n\n\nTwo.\n\nThree.\n"
+	tests := []struct {
+		name    string
+		content string
+		mode    string
+		size    int
+		want    []string
+	}{
+		{"no mode is one segment", chapters, "", 0, []string{chapters}},
+		{"paragraphs", chapters, domain.SegmentParagraphs, 0, []string{"One a.\nOne b.", "Two.", "Three."}},
+		{"paragraph pairs", chapters, domain.SegmentParagraphs, 2, []string{"One a.\nOne b.\n\n\nTwo.", "Three."}},
+		{"lines skip blank edges", "a\nb\n\nc\n\n\nd", domain.SegmentLines, 2, []string{"a\nb", "c", "d"}},
+		{
+			"chars pack paragraphs", "aaaa bbbb\n\ncccc\n\ndddd eeee ffff gggg hhhh iiii", domain.SegmentChars, 20,
+			[]string{"aaaa bbbb\n\ncccc", "dddd eeee ffff gggg", "hhhh iiii"},
+		},
+		{"blank content is one segment", "\n\n", domain.SegmentParagraphs, 0, []string{"\n\n"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			text := &domain.Text{Content: tc.content, SegmentMode: tc.mode, SegmentSize: tc.size}
+			if got := segmentContents(text); !slices.Equal(got, tc.want) {
+				t.Errorf("segments = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSplitSegments_StableIDs(t *testing.T) {
+	text := &domain.Text{Content: "Alpha.\n\nBeta.\n\nGamma.", SegmentMode: domain.SegmentParagraphs}
+	before := SplitSegments(text)
+	text.Content = "Intro.\n\nAlpha.\n\nBeta, edited.\n\nGamma."
+	after := SplitSegments(text)
+	if after[1].ID != before[0].ID || after[3].ID != before[2].ID {
+		t.Errorf("IDs of unchanged paragraphs moved: before %+v, after %+v", before, after)
+	}
+	if after[2].ID == before[1].ID {
+		t.Error("edited paragraph kept its ID")
+	}
+
+	// Repeated content gets distinct IDs
+	text.Content = "Same.\n\nSame."
+	if s := SplitSegments(text); s[0].ID == s[1].ID || !strings.HasPrefix(s[1].ID, s[0].ID+"-") {
+		t.Errorf("duplicate IDs = %q, %q", s[0].ID, s[1].ID)
+	}
+}
+
+func TestValidateSegmentation(t *testing.T) {
+	tests := []struct {
+		mode string
+		size int
+		ok   bool
+	}{
+		{"", 0, true},
+		{"", 3, false},
+		{domain.SegmentLines, 0, true},
+		{domain.SegmentLines, -1, false},
+		{domain.SegmentChars, 5, false},
+		{domain.SegmentChars, maxSegmentSize + 1, false},
+		{"pages", 1, false},
+	}
+	for _, tc := range tests {
+		err := validateSegmentation(&domain.Text{SegmentMode: tc.mode, SegmentSize: tc.size})
+		if tc.ok != (err == nil) || err != nil && !errors.Is(err, ErrInvalidSegmentation) {
+			t.Errorf("validateSegmentation(%q, %d) = %v, want ok=%v", tc.mode, tc.size, err, tc.ok)
+		}
+	}
+}
+
+func TestStores_UpdateKeepsSegmentation(t *testing.T) {
+	for name, open := range backends(t) {
+		t.Run(string(name), func(t *testing.T) {
+			s := open(t).texts
+			text := &domain.Text{ID: "book", Title: "Book", Content: "One.\n\nTwo.", SegmentMode: domain.SegmentLines, SegmentSize: 5}
+			if err := s.SaveText(text); err != nil {
+				t.Fatalf("SaveText() error: %v", err)
+			}
+			// An editor that only knows title and content sends no segmentation
+			for _, update := range []domain.Text{
+				{ID: "book", Title: "Renamed", Content: "One.\n\nTwo."},
+				{ID: "book", Title: "Renamed", Content: "One.\n\nTwo.\n\nThree."},
+			} {
+				if err := s.UpdateText(&update); err != nil {
+					t.Fatalf("UpdateText() error: %v", err)
+				}
+				got, err := s.Text("book")
+				if err != nil || got.SegmentMode != domain.SegmentLines || got.SegmentSize != 5 {
+					t.Errorf("after updating to %q: segmentation = %q/%d, %v; want lines/5", update.Content, got.SegmentMode, got.SegmentSize, err)
+				}
+			}
+			// A new segmentation replaces the old one
+			update := domain.Text{ID: "book", Title: "Renamed", Content: "One.", SegmentMode: domain.SegmentParagraphs}
+			if err := s.UpdateText(&update); err != nil {
+				t.Fatalf("UpdateText() error: %v", err)
+			}
+			if got, _ := s.Text("book"); got.SegmentMode != domain.SegmentParagraphs || got.SegmentSize != 0 {
+				t.Errorf("segmentation = %q/%d, want paragraphs/0", got.SegmentMode, got.SegmentSize)
+			}
+		})
+	}
+}
+
+func TestStores_Progress(t *testing.T) {
+	for name, open := range backends(t) {
+		t.Run(string(name), func(t *testing.T) {
+			s := open(t).texts
+			text := &domain.Text{ID: "book", Title: "Book", Content: "One.\n\nTwo.\n\nThree.", SegmentMode: domain.SegmentParagraphs}
+			if err := s.SaveText(text); err != nil {
+				t.Fatalf("SaveText() error: %v", err)
+			}
+			pos, err := CurrentSegment(s, "book")
+			if err != nil || pos.Segment.Content != "One." || pos.Total != 3 {
+				t.Fatalf("CurrentSegment() = %+v, %v; want the first of 3", pos, err)
+			}
+			segments, err := TextSegments(s, "book")
+			if err != nil || len(segments) != 3 || segments[0].Content != "" {
+				t.Fatalf("TextSegments() = %+v, %v", segments, err)
+			}
+
+			for _, want := range []string{"Two.", "Three.", "One."} {
+				if pos, err = AdvanceProgress(s, "book", pos.Segment.ID); err != nil {
+					t.Fatalf("AdvanceProgress() error: %v", err)
+				}
+				if pos.Segment.Content != want {
+					t.Errorf("advanced to %q, want %q", pos.Segment.Content, want)
+				}
+			}
+			if !pos.Finished {
+				t.Error("wrapping past the last segment: expected Finished")
+			}
+			if _, err := AdvanceProgress(s, "book", "gone"); !errors.Is(err, ErrSegmentNotFound) {
+				t.Errorf("AdvanceProgress(unknown segment): expected ErrSegmentNotFound, got %v", err)
+			}
+
+			// The cursor survives edits, by ID or else by position
+			edit := func(seek, content, want string) {
+				t.Helper()
+				if _, err := SeekSegment(s, "book", seek); err != nil {
+					t.Fatalf("SeekSegment() error: %v", err)
+				}
+				text.Content = content
+				if err := s.UpdateText(text); err != nil {
+					t.Fatalf("UpdateText() error: %v", err)
+				}
+				if pos, _ := CurrentSegment(s, "book"); pos.Segment.Content != want {
+					t.Errorf("after edit: current = %q, want %q", pos.Segment.Content, want)
+				}
+			}
+			edit(segments[1].ID, "Zero.\n\nOne.\n\nTwo.\n\nThree.", "Two.")
+			edit(segments[2].ID, "One.\n\nTwo.\n\nThree!", "Three!")
+
+			if err := s.DeleteText("book"); err != nil {
+				t.Fatalf("DeleteText() error: %v", err)
+			}
+			if _, err := s.Progress("book"); !errors.Is(err, ErrTextNotFound) {
+				t.Errorf("Progress(deleted): expected ErrTextNotFound, got %v", err)
+			}
+			if err := s.SaveText(&domain.Text{ID: "book", Title: "Again", Content: "A.\n\nB.", SegmentMode: domain.SegmentParagraphs}); err != nil {
+				t.Fatalf("SaveText() error: %v", err)
+			}
+			if p, err := s.Progress("book"); err != nil || p != (TextProgress{}) {
+				t.Errorf("progress of a reused ID = %+v, %v; want none", p, err)
+			}
+		})
+	}
+}
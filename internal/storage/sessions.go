@@ -5,11 +5,10 @@
 package storage
 
 import (
-	"bytes"
 	"encoding/json"
-	"errors"
 	"fmt"
-	"os"
+	"maps"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
@@ -17,16 +16,14 @@ import (
 	domain "github.com/AshBuk/FingerGo/internal/domain"
 )
 
-const (
-	// maxStoredSessions limits session history to prevent unbounded disk growth.
-	// At ~1KB per session JSON, 500 sessions ≈ 500KB disk space.
-	maxStoredSessions = 500
-)
-
-// SessionRepository persists typing sessions in sessions.json.
+// SessionRepository persists typing sessions in an append-only journal
+// (sessions.jsonl, one session per line). History is unbounded; old sessions
+// can be moved to yearly archive segments with Archive.
+// Safe for concurrent use.
 type SessionRepository struct {
 	storage  *Manager
-	sessions []domain.TypingSession
+	sessions []domain.TypingSession // live journal, oldest first
+	mu       sync.RWMutex           // guards sessions and loaded
 	loaded   bool
 }
 
@@ -42,6 +39,8 @@ func NewSessionRepository(mgr *Manager) (*SessionRepository, error) {
 
 // Record persists a session payload and returns the stored session.
 func (r *SessionRepository) Record(payload *domain.SessionPayload) (domain.TypingSession, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	if err := r.ensureLoaded(); err != nil {
 		return domain.TypingSession{}, err
 	}
@@ -50,22 +49,24 @@ func (r *SessionRepository) Record(payload *domain.SessionPayload) (domain.Typin
 	if session.ID == "" {
 		session.ID = uuid.NewString()
 	}
-	candidate := append(append([]domain.TypingSession(nil), r.sessions...), session)
-	if len(candidate) > maxStoredSessions {
-		candidate = candidate[len(candidate)-maxStoredSessions:]
+	line, err := json.Marshal(&session)
+	if err != nil {
+		return domain.TypingSession{}, fmt.Errorf("storage: marshal session: %w", err)
 	}
-	if err := r.persist(candidate); err != nil {
+	if err := r.storage.appendLine(sessionsJournalFile, line); err != nil {
 		return domain.TypingSession{}, err
 	}
-	r.sessions = candidate
+	r.sessions = append(r.sessions, session)
 	return session, nil
 }
 
 // List returns recent sessions (newest first). limit <= 0 returns all.
 func (r *SessionRepository) List(limit int) ([]domain.TypingSession, error) {
-	if err := r.ensureLoaded(); err != nil {
+	if err := r.load(); err != nil {
 		return nil, err
 	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
 	total := len(r.sessions)
 	if total == 0 {
 		return nil, nil
@@ -82,46 +83,97 @@ func (r *SessionRepository) List(limit int) ([]domain.TypingSession, error) {
 	return result, nil
 }
 
-func (r *SessionRepository) ensureLoaded() error {
-	if r.loaded {
+// load runs ensureLoaded under the write lock unless sessions are already loaded.
+func (r *SessionRepository) load() error {
+	r.mu.RLock()
+	loaded := r.loaded
+	r.mu.RUnlock()
+	if loaded {
 		return nil
 	}
-	path := r.storage.join(sessionsFile)
-	data, err := os.ReadFile(path)
-	if err != nil {
-		if errors.Is(err, os.ErrNotExist) {
-			r.sessions = nil
-			r.loaded = true
-			return nil
-		}
-		return fmt.Errorf("storage: read sessions %q: %w", path, err)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.ensureLoaded()
+}
+
+// Compact rewrites the journal from memory, dropping torn or duplicate lines.
+func (r *SessionRepository) Compact() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.ensureLoaded(); err != nil {
+		return err
 	}
+	return r.storage.writeJournal(sessionsJournalFile, r.sessions)
+}
 
-	clean := bytes.TrimSpace(data)
-	if len(clean) == 0 {
-		r.sessions = nil
-	} else {
-		if err := json.Unmarshal(clean, &r.sessions); err != nil {
-			return fmt.Errorf("storage: parse sessions %q: %w", path, err)
+// Archive moves sessions completed before cutoff out of the live journal into
+// yearly segments under sessions-archive/. Returns the number of sessions moved.
+func (r *SessionRepository) Archive(cutoff time.Time) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.ensureLoaded(); err != nil {
+		return 0, err
+	}
+	byYear := make(map[int][]domain.TypingSession)
+	var kept []domain.TypingSession
+	for i := range r.sessions {
+		if r.sessions[i].CompletedAt.Before(cutoff) {
+			year := r.sessions[i].CompletedAt.Year()
+			byYear[year] = append(byYear[year], r.sessions[i])
+			continue
 		}
+		kept = append(kept, r.sessions[i])
 	}
-
-	if len(r.sessions) > maxStoredSessions {
-		r.sessions = append([]domain.TypingSession(nil), r.sessions[len(r.sessions)-maxStoredSessions:]...)
+	if len(byYear) == 0 {
+		return 0, nil
 	}
-	r.loaded = true
-	return nil
+	if err := r.storage.ensureDir(r.storage.join(sessionsArchiveDir)); err != nil {
+		return 0, err
+	}
+	moved := 0
+	// Archive first: a crash before the live rewrite leaves duplicates, never losses
+	for year, sessions := range byYear {
+		segment, _, err := r.storage.readJournal(archivePath(year))
+		if err != nil {
+			return 0, err
+		}
+		if err := r.storage.writeJournal(archivePath(year), append(segment, sessions...)); err != nil {
+			return 0, err
+		}
+		moved += len(sessions)
+	}
+	if err := r.storage.writeJournal(sessionsJournalFile, kept); err != nil {
+		return 0, err
+	}
+	r.sessions = kept
+	return moved, nil
 }
 
-func (r *SessionRepository) persist(items []domain.TypingSession) error {
-	path := r.storage.join(sessionsFile)
-	data, err := json.MarshalIndent(items, "", "  ")
+// ensureLoaded reads the journal on first use, migrating a legacy
+// sessions.json and compacting damaged journals. Caller must hold r.mu.
+func (r *SessionRepository) ensureLoaded() error {
+	if r.loaded {
+		return nil
+	}
+	if !r.storage.readOnly {
+		if err := r.storage.migrateSessionsJournal(); err != nil {
+			return fmt.Errorf("storage: migrate sessions: %w", err)
+		}
+	}
+	sessions, scan, err := r.storage.readJournal(sessionsJournalFile)
 	if err != nil {
-		return fmt.Errorf("storage: marshal sessions: %w", err)
+		return fmt.Errorf("storage: load sessions: %w", err)
 	}
-	if err := os.WriteFile(path, data, 0o600); err != nil {
-		return fmt.Errorf("storage: write sessions %q: %w", path, err)
+	if scan.needsCompaction() && !r.storage.readOnly {
+		if err := r.storage.writeJournal(sessionsJournalFile, sessions); err != nil {
+			return fmt.Errorf("storage: compact sessions: %w", err)
+		}
+		if scan.badLines > 0 {
+			r.storage.warnf("session history had %d unreadable entries; they were dropped", scan.badLines)
+		}
 	}
+	r.sessions = sessions
+	r.loaded = true
 	return nil
 }
 
@@ -133,5 +185,8 @@ func cloneSession(src *domain.TypingSession) domain.TypingSession {
 			out.Mistakes[k] = v
 		}
 	}
+	if len(src.BigramMistakes) > 0 {
+		out.BigramMistakes = maps.Clone(src.BigramMistakes)
+	}
 	return out
 }
@@ -0,0 +1,199 @@
+// Copyright 2025 Asher Buk
+// SPDX-License-Identifier: Apache-2.0
+// https://github.com/AshBuk/FingerGo
+
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	domain "github.com/AshBuk/FingerGo/internal/domain"
+)
+
+// Session journal layout.
+//
+//	{root}/sessions.jsonl              # live journal, one TypingSession per line
+//	{root}/sessions-archive/{year}.jsonl # archived segments (same format)
+//
+// Lines are only ever appended; rewrites happen in compaction and archiving,
+// both through writeFileBackup.
+const (
+	sessionsJournalFile = "sessions.jsonl"
+	sessionsArchiveDir  = "sessions-archive"
+)
+
+// journalScan describes the health of a journal read by readJournal.
+type journalScan struct {
+	badLines   int  // unparsable lines (torn writes, manual edits)
+	duplicates int  // repeated session IDs (last occurrence wins)
+	tornTail   bool // file does not end with a newline
+}
+
+// needsCompaction reports whether the journal should be rewritten.
+func (s journalScan) needsCompaction() bool {
+	return s.badLines > 0 || s.duplicates > 0 || s.tornTail
+}
+
+// appendLine appends one newline-terminated record with a single write and fsyncs it.
+// A torn tail left by an earlier crash is terminated first, so the record
+// starts on a line of its own; a failed write is truncated away.
+func (m *Manager) appendLine(relPath string, line []byte) error {
+	if err := m.checkWritable(); err != nil {
+		return err
+	}
+	path := m.join(relPath)
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("storage: open journal %q: %w", path, err)
+	}
+	size, torn, err := journalTail(f)
+	if err != nil {
+		_ = f.Close()
+		return fmt.Errorf("storage: read journal %q: %w", path, err)
+	}
+	record := make([]byte, 0, len(line)+2)
+	if torn {
+		record = append(record, '\n')
+	}
+	record = append(append(record, line...), '\n')
+	if _, err := f.Write(record); err != nil {
+		if truncErr := f.Truncate(size); truncErr != nil {
+			log.Printf("WARNING: failed to truncate torn append to %q: %v", path, truncErr)
+		}
+		_ = f.Close()
+		return fmt.Errorf("storage: append journal %q: %w", path, err)
+	}
+	if err := f.Sync(); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("storage: sync journal %q: %w", path, err)
+	}
+	return f.Close()
+}
+
+// journalTail returns the size of an open journal and whether its last line
+// lacks a newline.
+func journalTail(f *os.File) (size int64, torn bool, err error) {
+	info, err := f.Stat()
+	if err != nil {
+		return 0, false, err
+	}
+	size = info.Size()
+	if size == 0 {
+		return 0, false, nil
+	}
+	last := make([]byte, 1)
+	if _, err := f.ReadAt(last, size-1); err != nil {
+		return 0, false, err
+	}
+	return size, last[0] != '\n', nil
+}
+
+// readJournal parses a session journal, skipping lines it cannot decode.
+// A missing file yields an empty journal.
+func (m *Manager) readJournal(relPath string) ([]domain.TypingSession, journalScan, error) {
+	var scan journalScan
+	data, err := os.ReadFile(m.join(relPath))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, scan, nil
+	}
+	if err != nil {
+		return nil, scan, fmt.Errorf("storage: read journal %q: %w", relPath, err)
+	}
+	scan.tornTail = len(data) > 0 && data[len(data)-1] != '\n'
+
+	var sessions []domain.TypingSession
+	position := make(map[string]int)
+	lines := bufio.NewScanner(bytes.NewReader(data))
+	lines.Buffer(make([]byte, 0, 64*1024), maxContentLength)
+	for lines.Scan() {
+		line := bytes.TrimSpace(lines.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var session domain.TypingSession
+		if err := json.Unmarshal(line, &session); err != nil || session.ID == "" {
+			scan.badLines++
+			continue
+		}
+		if i, seen := position[session.ID]; seen {
+			sessions[i] = session
+			scan.duplicates++
+			continue
+		}
+		position[session.ID] = len(sessions)
+		sessions = append(sessions, session)
+	}
+	if err := lines.Err(); err != nil {
+		return nil, scan, fmt.Errorf("storage: scan journal %q: %w", relPath, err)
+	}
+	return sessions, scan, nil
+}
+
+// writeJournal atomically rewrites a journal with the given sessions.
+func (m *Manager) writeJournal(relPath string, sessions []domain.TypingSession) error {
+	var buf bytes.Buffer
+	for i := range sessions {
+		line, err := json.Marshal(&sessions[i])
+		if err != nil {
+			return fmt.Errorf("storage: marshal session %q: %w", sessions[i].ID, err)
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+	return m.writeFileBackup(relPath, buf.Bytes())
+}
+
+// archivePath returns the relative path of the archive segment for a year.
+func archivePath(year int) string {
+	return filepath.Join(sessionsArchiveDir, strconv.Itoa(year)+".jsonl")
+}
+
+// migrateSessionsJournal converts the legacy whole-file sessions.json into the
+// journal on first run. The old file is kept as sessions.json.v1.bak.
+func (m *Manager) migrateSessionsJournal() error {
+	if _, err := os.Stat(m.join(sessionsJournalFile)); err == nil {
+		return nil
+	}
+	var sessions []domain.TypingSession
+	err := m.readFileFallback(sessionsFile, func(data []byte) error {
+		sessions = nil
+		clean := bytes.TrimSpace(data)
+		if len(clean) == 0 {
+			return nil
+		}
+		return decodeDocument(sessionsFile, clean, &sessions)
+	})
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		sessions = nil
+	case errors.Is(err, errCorruptFile):
+		m.warnf("session history could not be read and was reset (%v)", err)
+		sessions = nil
+	case err != nil:
+		return err
+	}
+	for i := range sessions {
+		if sessions[i].ID == "" {
+			sessions[i].ID = "legacy-" + strconv.Itoa(i)
+		}
+	}
+	if err := m.writeJournal(sessionsJournalFile, sessions); err != nil {
+		return err
+	}
+	legacy := m.join(sessionsFile)
+	if _, err := os.Stat(legacy); err == nil {
+		backup := fmt.Sprintf("%s.v%d%s", sessionsFile, schemaVersion(sessionsFile), backupSuffix)
+		if err := os.Rename(legacy, m.join(backup)); err != nil {
+			return fmt.Errorf("storage: retire %q: %w", sessionsFile, err)
+		}
+	}
+	return nil
+}
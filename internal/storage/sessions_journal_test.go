@@ -0,0 +1,127 @@
+// Copyright 2025 Asher Buk
+// SPDX-License-Identifier: Apache-2.0
+// https://github.com/AshBuk/FingerGo
+
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	domain "github.com/AshBuk/FingerGo/internal/domain"
+)
+
+func TestSessionRepository_RecordAppendsToJournal(t *testing.T) {
+	repo := setupSessionRepository(t)
+	for i := 0; i < 3; i++ {
+		if _, err := repo.Record(&domain.SessionPayload{WPM: float64(i)}); err != nil {
+			t.Fatalf("Record() error: %v", err)
+		}
+	}
+	data, err := os.ReadFile(repo.storage.join(sessionsJournalFile))
+	if err != nil {
+		t.Fatalf("ReadFile() error: %v", err)
+	}
+	if got := strings.Count(string(data), "\n"); got != 3 {
+		t.Errorf("got %d journal lines, want 3", got)
+	}
+	if _, err := os.Stat(repo.storage.join(sessionsJournalFile + backupSuffix)); !os.IsNotExist(err) {
+		t.Error("appending should not rewrite the journal")
+	}
+}
+
+func TestSessionRepository_RepairsDamagedJournal(t *testing.T) {
+	mgr := setupManager(t)
+	journal := `{"id":"a","wpm":10}` + "\n" +
+		"garbage\n" +
+		`{"id":"b","wpm":20}` + "\n" +
+		`{"id":"a","wpm":30}` + "\n" +
+		`{"id":"c","wp` // torn final write
+	writeRaw(t, mgr, sessionsJournalFile, journal)
+
+	repo, _ := NewSessionRepository(mgr)
+	sessions, err := repo.List(0)
+	if err != nil {
+		t.Fatalf("List() error: %v", err)
+	}
+	if len(sessions) != 2 {
+		t.Fatalf("got %d sessions, want 2: %+v", len(sessions), sessions)
+	}
+	if sessions[1].ID != "a" || sessions[1].WPM != 30 {
+		t.Errorf("duplicate ID should keep the last record, got %+v", sessions[1])
+	}
+	_, scan, err := mgr.readJournal(sessionsJournalFile)
+	if err != nil || scan.needsCompaction() {
+		t.Errorf("journal should be compacted on load: %+v, %v", scan, err)
+	}
+	if len(mgr.Warnings()) != 1 {
+		t.Errorf("got warnings %v, want exactly one", mgr.Warnings())
+	}
+	if _, err := repo.Record(&domain.SessionPayload{WPM: 40}); err != nil {
+		t.Fatalf("Record() after repair error: %v", err)
+	}
+	reopened, _ := NewSessionRepository(mgr)
+	if got, _ := reopened.List(0); len(got) != 3 {
+		t.Errorf("got %d sessions after reopen, want 3", len(got))
+	}
+}
+
+func TestSessionRepository_RecordAfterTornTail(t *testing.T) {
+	repo := setupSessionRepository(t)
+	if _, err := repo.Record(&domain.SessionPayload{WPM: 10}); err != nil {
+		t.Fatalf("Record() error: %v", err)
+	}
+	// A write cut short after the journal was loaded
+	f, err := os.OpenFile(repo.storage.join(sessionsJournalFile), os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		t.Fatalf("OpenFile() error: %v", err)
+	}
+	_, _ = f.WriteString(`{"id":"torn","wp`)
+	_ = f.Close()
+
+	recorded, err := repo.Record(&domain.SessionPayload{WPM: 20})
+	if err != nil {
+		t.Fatalf("Record() after torn tail error: %v", err)
+	}
+	reopened, _ := NewSessionRepository(repo.storage)
+	sessions, err := reopened.List(0)
+	if err != nil || len(sessions) != 2 || sessions[0].ID != recorded.ID {
+		t.Errorf("List() = %+v, %v; want both acknowledged sessions", sessions, err)
+	}
+}
+
+func TestSessionRepository_Archive(t *testing.T) {
+	mgr := setupManager(t)
+	journal := `{"id":"old1","completedAt":"2023-03-01T10:00:00Z"}` + "\n" +
+		`{"id":"old2","completedAt":"2024-06-01T10:00:00Z"}` + "\n" +
+		`{"id":"new","completedAt":"2025-02-01T10:00:00Z"}` + "\n"
+	writeRaw(t, mgr, sessionsJournalFile, journal)
+	repo, _ := NewSessionRepository(mgr)
+
+	moved, err := repo.Archive(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("Archive() error: %v", err)
+	}
+	if moved != 2 {
+		t.Errorf("got %d moved, want 2", moved)
+	}
+	live, _ := repo.List(0)
+	if len(live) != 1 || live[0].ID != "new" {
+		t.Errorf("live journal = %+v, want only the recent session", live)
+	}
+	for year, id := range map[int]string{2023: "old1", 2024: "old2"} {
+		segment, _, err := mgr.readJournal(archivePath(year))
+		if err != nil || len(segment) != 1 || segment[0].ID != id {
+			t.Errorf("%s = %+v, %v; want [%s]", archivePath(year), segment, err, id)
+		}
+	}
+	if moved, _ := repo.Archive(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)); moved != 0 {
+		t.Errorf("second Archive() moved %d, want 0", moved)
+	}
+	if _, err := os.Stat(filepath.Join(mgr.Root(), sessionsArchiveDir)); err != nil {
+		t.Errorf("archive dir missing: %v", err)
+	}
+}
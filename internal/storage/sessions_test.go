@@ -77,11 +77,12 @@ func TestSessionRepository_Record(t *testing.T) {
 		}
 	})
 
-	t.Run("enforces max session limit", func(t *testing.T) {
+	t.Run("keeps unlimited history", func(t *testing.T) {
 		repo := setupSessionRepository(t)
 
-		// Record more than max sessions
-		for i := 0; i < maxStoredSessions+10; i++ {
+		// Record more than the former 500-session cap
+		const total = 510
+		for i := 0; i < total; i++ {
 			payload := &domain.SessionPayload{
 				SessionTextMeta: &domain.SessionTextMeta{Text: "test"},
 				WPM:             float64(i),
@@ -96,8 +97,8 @@ func TestSessionRepository_Record(t *testing.T) {
 		if err != nil {
 			t.Fatalf("List() error: %v", err)
 		}
-		if len(sessions) > maxStoredSessions {
-			t.Errorf("got %d sessions, want max %d", len(sessions), maxStoredSessions)
+		if len(sessions) != total {
+			t.Errorf("got %d sessions, want %d", len(sessions), total)
 		}
 	})
 }
@@ -6,10 +6,11 @@ package storage
 
 import (
 	"bytes"
-	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
 	"os"
+	"sync"
 
 	domain "github.com/AshBuk/FingerGo/internal/domain"
 )
@@ -18,12 +19,17 @@ const (
 	configFile = "settings.json"
 	themeDark  = "dark"
 	themeLight = "light"
+
+	maxTrashRetentionDays = 3650
+	maxSessionArchiveDays = 3650
 )
 
 // SettingsRepository persists user settings in settings.json.
+// Safe for concurrent use.
 type SettingsRepository struct {
 	storage  *Manager
 	settings domain.Settings
+	mu       sync.RWMutex // guards settings and loaded
 	loaded   bool
 }
 
@@ -37,14 +43,18 @@ func NewSettingsRepository(mgr *Manager) (*SettingsRepository, error) {
 
 // Load returns current settings, loading from disk on first access.
 func (r *SettingsRepository) Load() (domain.Settings, error) {
-	if err := r.ensureLoaded(); err != nil {
+	if err := r.load(); err != nil {
 		return domain.Settings{}, err
 	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
 	return r.settings, nil
 }
 
 // Save persists the entire settings object.
 func (r *SettingsRepository) Save(s domain.Settings) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	if err := r.persist(s); err != nil {
 		return err
 	}
@@ -54,120 +64,166 @@ func (r *SettingsRepository) Save(s domain.Settings) error {
 }
 
 // Update modifies a single setting by key and persists the change.
-// Supported keys: "theme", "showKeyboard", "showStatsBar", "zenMode", "strictMode", "lastTextId", "keyboardLayout", "textZoom".
-//
-//nolint:gocyclo // switch-based dispatch, linear and readable
+// Supported keys are listed on applySetting.
 func (r *SettingsRepository) Update(key string, value any) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	if err := r.ensureLoaded(); err != nil {
 		return err
 	}
-	updated := r.settings
+	updated, err := applySetting(r.settings, key, value)
+	if err != nil {
+		return err
+	}
+	if err := r.persist(updated); err != nil {
+		return err
+	}
+	r.settings = updated
+	return nil
+}
+
+// load runs ensureLoaded under the write lock unless settings are already loaded.
+func (r *SettingsRepository) load() error {
+	r.mu.RLock()
+	loaded := r.loaded
+	r.mu.RUnlock()
+	if loaded {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.ensureLoaded()
+}
+
+// ensureLoaded reads settings.json on first use. Caller must hold r.mu.
+func (r *SettingsRepository) ensureLoaded() error {
+	if r.loaded {
+		return nil
+	}
+	settings := domain.DefaultSettings()
+	err := r.storage.readFileFallback(configFile, func(data []byte) error {
+		clean := bytes.TrimSpace(data)
+		if len(clean) == 0 {
+			settings = domain.DefaultSettings()
+			return nil
+		}
+		var s domain.Settings
+		if err := decodeDocument(configFile, clean, &s); err != nil {
+			return err
+		}
+		settings = s
+		return nil
+	})
+	switch {
+	case errors.Is(err, errCorruptFile):
+		r.storage.warnf("settings could not be read and were reset to defaults (%v)", err)
+		settings = domain.DefaultSettings()
+	case err != nil && !errors.Is(err, os.ErrNotExist):
+		return fmt.Errorf("storage: load config: %w", err)
+	}
+	r.settings = settings
+	r.loaded = true
+	return nil
+}
+
+func (r *SettingsRepository) persist(s domain.Settings) error {
+	data, err := encodeDocument(configFile, s)
+	if err != nil {
+		return fmt.Errorf("storage: marshal config: %w", err)
+	}
+	return r.storage.writeFileBackup(configFile, data)
+}
+
+// applySetting returns s with a single key changed, validating the value.
+// Supported keys: "theme", "showKeyboard", "showStatsBar", "zenMode", "strictMode", "lastTextId", "keyboardLayout", "textZoom", "trashRetentionDays", "sessionArchiveDays".
+//
+//nolint:gocyclo // switch-based dispatch, linear and readable
+func applySetting(s domain.Settings, key string, value any) (domain.Settings, error) {
+	updated := s
 	switch key {
 	case "theme":
 		v, ok := value.(string)
 		if !ok {
-			return fmt.Errorf("settings: theme expects string, got %T", value)
+			return s, fmt.Errorf("settings: theme expects string, got %T", value)
 		}
 		if v != themeDark && v != themeLight {
-			return fmt.Errorf("settings: invalid theme %q", v)
+			return s, fmt.Errorf("settings: invalid theme %q", v)
 		}
 		updated.Theme = v
 	case "showKeyboard":
 		v, ok := value.(bool)
 		if !ok {
-			return fmt.Errorf("settings: showKeyboard expects bool, got %T", value)
+			return s, fmt.Errorf("settings: showKeyboard expects bool, got %T", value)
 		}
 		updated.ShowKeyboard = v
 	case "showStatsBar":
 		v, ok := value.(bool)
 		if !ok {
-			return fmt.Errorf("settings: showStatsBar expects bool, got %T", value)
+			return s, fmt.Errorf("settings: showStatsBar expects bool, got %T", value)
 		}
 		updated.ShowStatsBar = v
 	case "zenMode":
 		v, ok := value.(bool)
 		if !ok {
-			return fmt.Errorf("settings: zenMode expects bool, got %T", value)
+			return s, fmt.Errorf("settings: zenMode expects bool, got %T", value)
 		}
 		updated.ZenMode = v
 	case "strictMode":
 		v, ok := value.(bool)
 		if !ok {
-			return fmt.Errorf("settings: strictMode expects bool, got %T", value)
+			return s, fmt.Errorf("settings: strictMode expects bool, got %T", value)
 		}
 		updated.StrictMode = v
 	case "lastTextId":
 		v, ok := value.(string)
 		if !ok {
-			return fmt.Errorf("settings: lastTextId expects string, got %T", value)
+			return s, fmt.Errorf("settings: lastTextId expects string, got %T", value)
 		}
 		updated.LastTextID = v
 	case "keyboardLayout":
 		v, ok := value.(string)
 		if !ok {
-			return fmt.Errorf("settings: keyboardLayout expects string, got %T", value)
+			return s, fmt.Errorf("settings: keyboardLayout expects string, got %T", value)
 		}
 		updated.KeyboardLayout = v
 	case "textZoom":
 		v, ok := value.(float64)
 		if !ok {
-			return fmt.Errorf("settings: textZoom expects float64, got %T", value)
+			return s, fmt.Errorf("settings: textZoom expects float64, got %T", value)
 		}
 		if v < 0.5 || v > 2.0 {
-			return fmt.Errorf("settings: textZoom out of range [0.5, 2.0]: %v", v)
+			return s, fmt.Errorf("settings: textZoom out of range [0.5, 2.0]: %v", v)
 		}
 		updated.TextZoom = v
-	default:
-		return fmt.Errorf("settings: unknown key %q", key)
-	}
-
-	if err := r.persist(updated); err != nil {
-		return err
-	}
-	r.settings = updated
-	return nil
-}
-
-func (r *SettingsRepository) ensureLoaded() error {
-	if r.loaded {
-		return nil
-	}
-	path := r.storage.join(configFile)
-	data, err := os.ReadFile(path)
-	if err != nil {
-		if errors.Is(err, os.ErrNotExist) {
-			r.settings = domain.DefaultSettings()
-			r.loaded = true
-			return nil
-		}
-		return fmt.Errorf("storage: read config %q: %w", path, err)
-	}
-
-	clean := bytes.TrimSpace(data)
-	if len(clean) == 0 {
-		r.settings = domain.DefaultSettings()
-	} else {
-		var s domain.Settings
-		if err := json.Unmarshal(clean, &s); err != nil {
-			return fmt.Errorf("storage: parse config %q: %w", path, err)
+	case "trashRetentionDays":
+		days, err := wholeDays(key, value, maxTrashRetentionDays)
+		if err != nil {
+			return s, err
 		}
-		if s.TextZoom == 0 {
-			s.TextZoom = 1.0
+		updated.TrashRetentionDays = days
+	case "sessionArchiveDays":
+		days, err := wholeDays(key, value, maxSessionArchiveDays)
+		if err != nil {
+			return s, err
 		}
-		r.settings = s
+		updated.SessionArchiveDays = days
+	default:
+		return s, fmt.Errorf("settings: unknown key %q", key)
 	}
-	r.loaded = true
-	return nil
+	return updated, nil
 }
 
-func (r *SettingsRepository) persist(s domain.Settings) error {
-	path := r.storage.join(configFile)
-	data, err := json.MarshalIndent(s, "", "  ")
-	if err != nil {
-		return fmt.Errorf("storage: marshal config: %w", err)
+// wholeDays validates a day count setting in [0, maxDays].
+func wholeDays(key string, value any, maxDays int) (int, error) {
+	v, ok := value.(float64) // JSON numbers arrive as float64
+	if iv, isInt := value.(int); isInt {
+		v, ok = float64(iv), true
 	}
-	if err := os.WriteFile(path, data, 0o600); err != nil {
-		return fmt.Errorf("storage: write config %q: %w", path, err)
+	if !ok {
+		return 0, fmt.Errorf("settings: %s expects number, got %T", key, value)
 	}
-	return nil
+	if v != math.Trunc(v) || v < 0 || v > float64(maxDays) {
+		return 0, fmt.Errorf("settings: %s must be a whole number in [0, %d]: %v", key, maxDays, v)
+	}
+	return int(v), nil
 }
@@ -210,4 +210,43 @@ func TestSettingsRepository_Update(t *testing.T) {
 			t.Error("expected error for wrong type")
 		}
 	})
+
+	t.Run("updates trashRetentionDays", func(t *testing.T) {
+		repo := setupSettingsRepository(t)
+
+		if settings, _ := repo.Load(); settings.TrashRetentionDays != 30 {
+			t.Errorf("got default TrashRetentionDays %d, want 30", settings.TrashRetentionDays)
+		}
+		if err := repo.Update("trashRetentionDays", float64(0)); err != nil {
+			t.Fatalf("Update() error: %v", err)
+		}
+		settings, _ := repo.Load()
+		if settings.TrashRetentionDays != 0 {
+			t.Errorf("got TrashRetentionDays %d, want 0", settings.TrashRetentionDays)
+		}
+		for _, bad := range []any{-1.0, 7.5, 5000.0, "7"} {
+			if err := repo.Update("trashRetentionDays", bad); err == nil {
+				t.Errorf("Update(trashRetentionDays, %v) should fail", bad)
+			}
+		}
+	})
+
+	t.Run("updates sessionArchiveDays", func(t *testing.T) {
+		repo := setupSettingsRepository(t)
+
+		if settings, _ := repo.Load(); settings.SessionArchiveDays != 0 {
+			t.Errorf("got default SessionArchiveDays %d, want 0", settings.SessionArchiveDays)
+		}
+		if err := repo.Update("sessionArchiveDays", 365); err != nil {
+			t.Fatalf("Update() error: %v", err)
+		}
+		if settings, _ := repo.Load(); settings.SessionArchiveDays != 365 {
+			t.Errorf("got SessionArchiveDays %d, want 365", settings.SessionArchiveDays)
+		}
+		for _, bad := range []any{-1.0, 0.5, 5000.0, true} {
+			if err := repo.Update("sessionArchiveDays", bad); err == nil {
+				t.Errorf("Update(sessionArchiveDays, %v) should fail", bad)
+			}
+		}
+	})
 }
@@ -0,0 +1,402 @@
+// Copyright 2025 Asher Buk
+// SPDX-License-Identifier: Apache-2.0
+// https://github.com/AshBuk/FingerGo
+
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+
+	_ "modernc.org/sqlite" // registers the pure-Go "sqlite" database/sql driver
+
+	domain "github.com/AshBuk/FingerGo/internal/domain"
+)
+
+// SQLite database layout.
+//
+//	{root}/fingergo.db   # texts, revisions, progress, categories, sessions, settings
+//
+// The schema version is kept in PRAGMA user_version.
+const (
+	sqliteFile          = "fingergo.db"
+	sqliteSchemaVersion = 7
+)
+
+// sqlitePragmas are applied to every connection opened by database/sql.
+const sqlitePragmas = "?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)"
+
+// ErrDatabaseExists is returned by MigrateToSQLite when fingergo.db is already present.
+var ErrDatabaseExists = errors.New("storage: sqlite database already exists")
+
+var errNilDatabase = errors.New("storage: database is nil")
+
+// sqliteSchema creates the v1 tables. Row order (rowid) preserves insertion
+// order, matching the slice order of the JSON backend.
+var sqliteSchema = []string{
+	`CREATE TABLE meta (
+		key   TEXT PRIMARY KEY,
+		value TEXT NOT NULL
+	)`,
+	`CREATE TABLE categories (
+		id        TEXT PRIMARY KEY,
+		name      TEXT NOT NULL UNIQUE,
+		parent_id TEXT NOT NULL DEFAULT '',
+		icon      TEXT NOT NULL DEFAULT ''
+	)`,
+	`CREATE TABLE texts (
+		id          TEXT PRIMARY KEY,
+		title       TEXT NOT NULL,
+		content     TEXT NOT NULL,
+		category_id TEXT NOT NULL DEFAULT '',
+		language    TEXT NOT NULL,
+		is_favorite INTEGER NOT NULL DEFAULT 0,
+		created_at  TEXT NOT NULL
+	)`,
+	`CREATE INDEX texts_category_id ON texts (category_id)`,
+	`CREATE TABLE sessions (
+		id               TEXT PRIMARY KEY,
+		started_at       TEXT NOT NULL,
+		completed_at     TEXT NOT NULL,
+		text_id          TEXT NOT NULL DEFAULT '',
+		text_title       TEXT NOT NULL DEFAULT '',
+		text_preview     TEXT NOT NULL DEFAULT '',
+		category_id      TEXT NOT NULL DEFAULT '',
+		wpm              REAL NOT NULL,
+		cpm              REAL NOT NULL,
+		accuracy         REAL NOT NULL,
+		duration_seconds INTEGER NOT NULL,
+		total_keystrokes INTEGER NOT NULL,
+		total_errors     INTEGER NOT NULL,
+		character_count  INTEGER NOT NULL,
+		mistakes         TEXT NOT NULL DEFAULT ''
+	)`,
+	`CREATE INDEX sessions_completed_at ON sessions (completed_at)`,
+	`CREATE INDEX sessions_text_id ON sessions (text_id)`,
+	`CREATE TABLE settings (
+		id   INTEGER PRIMARY KEY CHECK (id = 1),
+		data TEXT NOT NULL
+	)`,
+}
+
+// sqliteUpgrades[i] upgrades a database from version i+1 to i+2. A new
+// database gets the v1 schema and then every upgrade. Append new steps at
+// the end; never edit or reorder released ones.
+var sqliteUpgrades = [][]string{
+	{`ALTER TABLE categories ADD COLUMN sort_order INTEGER NOT NULL DEFAULT 0`}, // v2: category display order
+	{ // v3: text revision history
+		`ALTER TABLE texts ADD COLUMN revision INTEGER NOT NULL DEFAULT 0`,
+		`ALTER TABLE sessions ADD COLUMN text_revision INTEGER NOT NULL DEFAULT 0`,
+		`CREATE TABLE text_revisions (
+			text_id  TEXT NOT NULL,
+			revision INTEGER NOT NULL,
+			saved_at TEXT NOT NULL,
+			content  TEXT NOT NULL,
+			PRIMARY KEY (text_id, revision)
+		)`,
+	},
+	{ // v4: chaptered texts
+		`ALTER TABLE texts ADD COLUMN segment_mode TEXT NOT NULL DEFAULT ''`,
+		`ALTER TABLE texts ADD COLUMN segment_size INTEGER NOT NULL DEFAULT 0`,
+		`ALTER TABLE sessions ADD COLUMN segment_id TEXT NOT NULL DEFAULT ''`,
+		`ALTER TABLE sessions ADD COLUMN segment_index INTEGER NOT NULL DEFAULT 0`,
+		`CREATE TABLE text_progress (
+			text_id       TEXT PRIMARY KEY,
+			segment_id    TEXT NOT NULL,
+			segment_index INTEGER NOT NULL,
+			finished      INTEGER NOT NULL DEFAULT 0,
+			updated_at    TEXT NOT NULL
+		)`,
+	},
+	{ // v5: bigram mistakes
+		`ALTER TABLE sessions ADD COLUMN bigram_mistakes TEXT NOT NULL DEFAULT ''`,
+	},
+	{ // v6: text difficulty metrics (JSON)
+		`ALTER TABLE texts ADD COLUMN metrics TEXT NOT NULL DEFAULT ''`,
+	},
+	{ // v7: save time of the current revision
+		`ALTER TABLE texts ADD COLUMN revised_at TEXT NOT NULL DEFAULT ''`,
+	},
+}
+
+// sqlConn is the subset of *sql.DB and *sql.Tx used by the repositories.
+type sqlConn interface {
+	Exec(query string, args ...any) (sql.Result, error)
+	Query(query string, args ...any) (*sql.Rows, error)
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+// SQLiteDB is the SQLite storage backend: one database file in the Manager's
+// root holding texts, sessions and settings. The Manager still owns the data
+// directory and its lock. Safe for concurrent use.
+type SQLiteDB struct {
+	db      *sql.DB
+	storage *Manager
+}
+
+// MigrationReport summarizes data copied from the JSON layout into SQLite.
+type MigrationReport struct {
+	Skipped    []string // text IDs whose content could not be read
+	Categories int
+	Texts      int
+	Sessions   int
+}
+
+// OpenSQLite opens {root}/fingergo.db. A new database is created with the
+// current schema and filled from the JSON data already in the directory
+// (the embedded defaults on a fresh install). Read-only managers require an
+// existing database.
+func OpenSQLite(mgr *Manager) (*SQLiteDB, error) {
+	d, _, err := openSQLite(mgr)
+	return d, err
+}
+
+// MigrateToSQLite creates {root}/fingergo.db from the JSON data in mgr's
+// directory. The JSON files are left untouched, so switching back to the JSON
+// backend is always possible. Returns ErrDatabaseExists if the database is
+// already present.
+func MigrateToSQLite(mgr *Manager) (MigrationReport, error) {
+	if mgr == nil {
+		return MigrationReport{}, errNilManager
+	}
+	if err := mgr.checkWritable(); err != nil {
+		return MigrationReport{}, err
+	}
+	path := mgr.join(sqliteFile)
+	if _, err := os.Stat(path); err == nil {
+		return MigrationReport{}, fmt.Errorf("%w: %s", ErrDatabaseExists, path)
+	}
+	d, report, err := openSQLite(mgr)
+	if err != nil {
+		// Leave no half-created database behind, so the next run starts clean
+		for _, suffix := range []string{"", "-wal", "-shm"} {
+			_ = os.Remove(path + suffix)
+		}
+		return MigrationReport{}, err
+	}
+	if err := d.Close(); err != nil {
+		return MigrationReport{}, err
+	}
+	return *report, nil
+}
+
+// Close closes the database. The Manager lock is released separately.
+func (d *SQLiteDB) Close() error {
+	if err := d.db.Close(); err != nil {
+		return fmt.Errorf("storage: close database: %w", err)
+	}
+	return nil
+}
+
+func openSQLite(mgr *Manager) (*SQLiteDB, *MigrationReport, error) {
+	if mgr == nil {
+		return nil, nil, errNilManager
+	}
+	path := mgr.join(sqliteFile)
+	dsn := path + sqlitePragmas
+	if mgr.readOnly {
+		if _, err := os.Stat(path); err != nil {
+			return nil, nil, fmt.Errorf("storage: open database %q: %w", path, err)
+		}
+		dsn += "&_pragma=query_only(1)"
+	}
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, nil, fmt.Errorf("storage: open database %q: %w", path, err)
+	}
+	// A single connection serializes writers; SQLite allows only one anyway
+	db.SetMaxOpenConns(1)
+	d := &SQLiteDB{db: db, storage: mgr}
+	report, err := d.migrate()
+	if err != nil {
+		_ = db.Close()
+		return nil, nil, err
+	}
+	return d, report, nil
+}
+
+// migrate creates the schema on a new database and imports the JSON layout
+// in the same transaction, or upgrades an older database through
+// sqliteUpgrades. Returns a report only when the database was created.
+func (d *SQLiteDB) migrate() (*MigrationReport, error) {
+	var version int
+	if err := d.db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		return nil, fmt.Errorf("storage: read database version: %w", err)
+	}
+	switch {
+	case version > sqliteSchemaVersion:
+		return nil, fmt.Errorf("%w: %s is v%d, this build supports v%d", ErrSchemaTooNew, sqliteFile, version, sqliteSchemaVersion)
+	case version == sqliteSchemaVersion:
+		return nil, nil
+	}
+	if err := d.storage.checkWritable(); err != nil {
+		return nil, err
+	}
+	tx, err := d.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("storage: begin schema setup: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }() // no-op after Commit
+	if err := upgradeSchema(tx, version); err != nil {
+		return nil, err
+	}
+	var report *MigrationReport
+	if version == 0 {
+		imported, err := importJSON(tx, d.storage)
+		if err != nil {
+			return nil, fmt.Errorf("storage: import JSON data: %w", err)
+		}
+		report = &imported
+	}
+	if _, err := tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", sqliteSchemaVersion)); err != nil {
+		return nil, fmt.Errorf("storage: set database version: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("storage: commit schema setup: %w", err)
+	}
+	return report, nil
+}
+
+// upgradeSchema brings a database at version (0 for a new one) to
+// sqliteSchemaVersion.
+func upgradeSchema(conn sqlConn, version int) error {
+	if version == 0 {
+		for _, stmt := range sqliteSchema {
+			if _, err := conn.Exec(stmt); err != nil {
+				return fmt.Errorf("storage: create schema: %w", err)
+			}
+		}
+		version = 1
+	}
+	for ; version < sqliteSchemaVersion; version++ {
+		for _, stmt := range sqliteUpgrades[version-1] {
+			if _, err := conn.Exec(stmt); err != nil {
+				return fmt.Errorf("storage: upgrade database to v%d: %w", version+1, err)
+			}
+		}
+	}
+	return nil
+}
+
+// importJSON copies the library, session history and settings from the JSON
+// repositories of mgr into the database.
+func importJSON(conn sqlConn, mgr *Manager) (MigrationReport, error) {
+	var report MigrationReport
+	if err := importLibrary(conn, mgr, &report); err != nil {
+		return report, err
+	}
+	if err := importSessions(conn, mgr, &report); err != nil {
+		return report, err
+	}
+	return report, importSettings(conn, mgr)
+}
+
+// importLibrary copies the categories and texts (with their revisions and
+// progress) of the JSON library. Texts whose content cannot be read are
+// skipped and reported.
+func importLibrary(conn sqlConn, mgr *Manager, report *MigrationReport) error {
+	texts, err := NewTextRepository(mgr)
+	if err != nil {
+		return err
+	}
+	lib, err := texts.Library()
+	if err != nil {
+		return err
+	}
+	if err := setMeta(conn, metaDefaultTextID, lib.DefaultTextID); err != nil {
+		return err
+	}
+	for i := range lib.Categories {
+		if err := insertCategory(conn, &lib.Categories[i]); err != nil {
+			return err
+		}
+		report.Categories++
+	}
+	for _, entry := range lib.Texts {
+		text, err := texts.Text(entry.ID)
+		if err != nil {
+			report.Skipped = append(report.Skipped, entry.ID)
+			mgr.warnf("text %q was not migrated: %v", entry.ID, err)
+			continue
+		}
+		if err := importText(conn, texts, &text); err != nil {
+			return err
+		}
+		report.Texts++
+	}
+	return nil
+}
+
+// importText copies one JSON text with its revision history and progress.
+func importText(conn sqlConn, texts *TextRepository, text *domain.Text) error {
+	if err := insertText(conn, text); err != nil {
+		return err
+	}
+	history, err := texts.readRevisions(text.ID)
+	if err != nil {
+		return err
+	}
+	for i := range history {
+		if err := insertRevision(conn, text.ID, &history[i]); err != nil {
+			return err
+		}
+	}
+	progress, err := texts.Progress(text.ID)
+	if err != nil {
+		return err
+	}
+	if progress == (TextProgress{}) {
+		return nil
+	}
+	return upsertProgress(conn, text.ID, &progress)
+}
+
+// importSessions copies the JSON session journal, oldest first.
+func importSessions(conn sqlConn, mgr *Manager, report *MigrationReport) error {
+	sessions, err := NewSessionRepository(mgr)
+	if err != nil {
+		return err
+	}
+	history, err := sessions.List(0)
+	if err != nil {
+		return err
+	}
+	for i := len(history) - 1; i >= 0; i-- { // List is newest first
+		if err := insertSession(conn, &history[i]); err != nil {
+			return err
+		}
+		report.Sessions++
+	}
+	return nil
+}
+
+// importSettings copies the JSON settings.
+func importSettings(conn sqlConn, mgr *Manager) error {
+	settings, err := NewSettingsRepository(mgr)
+	if err != nil {
+		return err
+	}
+	current, err := settings.Load()
+	if err != nil {
+		return err
+	}
+	return writeSettings(conn, current)
+}
+
+// inTx runs fn in a transaction, committing only if fn succeeds.
+func (d *SQLiteDB) inTx(fn func(tx *sql.Tx) error) error {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return fmt.Errorf("storage: begin transaction: %w", err)
+	}
+	if err := fn(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("storage: commit transaction: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,309 @@
+// Copyright 2025 Asher Buk
+// SPDX-License-Identifier: Apache-2.0
+// https://github.com/AshBuk/FingerGo
+
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	domain "github.com/AshBuk/FingerGo/internal/domain"
+)
+
+// Row-level reads and writes of the SQLite text tables, shared by
+// SQLiteTextRepository and the JSON import.
+
+// queryTexts returns the texts with content matching the SQL condition where
+// ("" for all), in insertion order.
+func queryTexts(conn sqlConn, where string, args ...any) ([]domain.Text, error) {
+	query := `SELECT id, title, content, category_id, language, is_favorite, created_at, revision, revised_at, segment_mode, segment_size, metrics
+		FROM texts`
+	if where != "" {
+		query += " WHERE " + where
+	}
+	rows, err := conn.Query(query+" ORDER BY rowid", args...)
+	if err != nil {
+		return nil, fmt.Errorf("storage: query texts: %w", err)
+	}
+	var texts []domain.Text
+	for rows.Next() {
+		var text domain.Text
+		var createdAt, revisedAt, metrics string
+		if err := rows.Scan(&text.ID, &text.Title, &text.Content, &text.CategoryID, &text.Language, &text.IsFavorite, &createdAt, &text.Revision,
+			&revisedAt, &text.SegmentMode, &text.SegmentSize, &metrics); err != nil {
+			_ = rows.Close()
+			return nil, fmt.Errorf("storage: scan text: %w", err)
+		}
+		if err := decodeTextColumns(&text, createdAt, revisedAt, metrics); err != nil {
+			_ = rows.Close()
+			return nil, err
+		}
+		texts = append(texts, text)
+	}
+	return texts, closeRows(rows)
+}
+
+// decodeTextColumns parses the columns of a text row stored as strings.
+func decodeTextColumns(text *domain.Text, createdAt, revisedAt, metrics string) error {
+	var err error
+	if text.CreatedAt, err = parseTime(createdAt); err != nil {
+		return err
+	}
+	if revisedAt != "" {
+		if text.RevisedAt, err = parseTime(revisedAt); err != nil {
+			return err
+		}
+	}
+	if metrics != "" {
+		text.Metrics = new(domain.TextMetrics)
+		if err := json.Unmarshal([]byte(metrics), text.Metrics); err != nil {
+			return fmt.Errorf("storage: decode metrics of %q: %w", text.ID, err)
+		}
+	}
+	return nil
+}
+
+// encodeMetrics stores difficulty metrics as JSON; nil as "".
+func encodeMetrics(m *domain.TextMetrics) (string, error) {
+	if m == nil {
+		return "", nil
+	}
+	data, err := json.Marshal(m)
+	return string(data), err
+}
+
+func insertText(conn sqlConn, text *domain.Text) error {
+	metrics, err := encodeMetrics(text.Metrics)
+	if err != nil {
+		return fmt.Errorf("storage: encode metrics of %q: %w", text.ID, err)
+	}
+	_, err = conn.Exec(
+		`INSERT INTO texts (id, title, content, category_id, language, is_favorite, created_at, revision, revised_at,
+			segment_mode, segment_size, metrics) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		text.ID, text.Title, text.Content, text.CategoryID, text.Language, text.IsFavorite, formatTime(text.CreatedAt), text.Revision,
+		formatOptionalTime(text.RevisedAt), text.SegmentMode, text.SegmentSize, metrics,
+	)
+	if err != nil {
+		return fmt.Errorf("storage: insert text %q: %w", text.ID, err)
+	}
+	return nil
+}
+
+// updateMetricsRow replaces the cached metrics of a text.
+func updateMetricsRow(conn sqlConn, id string, m *domain.TextMetrics) error {
+	metrics, err := encodeMetrics(m)
+	if err != nil {
+		return fmt.Errorf("storage: encode metrics of %q: %w", id, err)
+	}
+	if _, err := conn.Exec(`UPDATE texts SET metrics = ? WHERE id = ?`, metrics, id); err != nil {
+		return fmt.Errorf("storage: update metrics of %q: %w", id, err)
+	}
+	return nil
+}
+
+// updateBulkRow writes text back with change applied.
+func updateBulkRow(conn sqlConn, text *domain.Text, change *BulkChange) error {
+	change.apply(text)
+	_, err := conn.Exec(
+		`UPDATE texts SET category_id = ?, language = ?, is_favorite = ? WHERE id = ?`,
+		text.CategoryID, text.Language, text.IsFavorite, text.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("storage: update text %q: %w", text.ID, err)
+	}
+	return nil
+}
+
+// deleteTextRow deletes a text with its revision history and progress.
+func deleteTextRow(conn sqlConn, id string) error {
+	if _, err := conn.Exec(`DELETE FROM text_revisions WHERE text_id = ?`, id); err != nil {
+		return fmt.Errorf("storage: delete revisions of %q: %w", id, err)
+	}
+	if _, err := conn.Exec(`DELETE FROM text_progress WHERE text_id = ?`, id); err != nil {
+		return fmt.Errorf("storage: delete progress of %q: %w", id, err)
+	}
+	if _, err := conn.Exec(`DELETE FROM texts WHERE id = ?`, id); err != nil {
+		return fmt.Errorf("storage: delete text %q: %w", id, err)
+	}
+	return nil
+}
+
+// deleteCategoryRows deletes the given categories and their texts.
+func deleteCategoryRows(conn sqlConn, categories []domain.Category) error {
+	for _, c := range categories {
+		if _, err := conn.Exec(`DELETE FROM text_revisions WHERE text_id IN (SELECT id FROM texts WHERE category_id = ?)`, c.ID); err != nil {
+			return fmt.Errorf("storage: delete revisions of category %q: %w", c.ID, err)
+		}
+		if _, err := conn.Exec(`DELETE FROM text_progress WHERE text_id IN (SELECT id FROM texts WHERE category_id = ?)`, c.ID); err != nil {
+			return fmt.Errorf("storage: delete progress of category %q: %w", c.ID, err)
+		}
+		if _, err := conn.Exec(`DELETE FROM texts WHERE category_id = ?`, c.ID); err != nil {
+			return fmt.Errorf("storage: delete texts of category %q: %w", c.ID, err)
+		}
+		if _, err := conn.Exec(`DELETE FROM categories WHERE id = ?`, c.ID); err != nil {
+			return fmt.Errorf("storage: delete category %q: %w", c.ID, err)
+		}
+	}
+	return nil
+}
+
+// upsertProgress stores the progress cursor of a text.
+func upsertProgress(conn sqlConn, id string, p *TextProgress) error {
+	_, err := conn.Exec(
+		`INSERT INTO text_progress (text_id, segment_id, segment_index, finished, updated_at) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (text_id) DO UPDATE SET segment_id = excluded.segment_id, segment_index = excluded.segment_index,
+			finished = excluded.finished, updated_at = excluded.updated_at`,
+		id, p.SegmentID, p.SegmentIndex, p.Finished, formatTime(p.UpdatedAt),
+	)
+	if err != nil {
+		return fmt.Errorf("storage: save progress of %q: %w", id, err)
+	}
+	return nil
+}
+
+// recordRevision adds next (and replaced, if missing) to the history of a
+// text and drops the entries outside the window kept by appendRevision.
+func recordRevision(conn sqlConn, id string, replaced, next *TextRevision) error {
+	oldest := next.Revision - maxTextRevisions
+	_, err := conn.Exec(`DELETE FROM text_revisions WHERE text_id = ? AND (revision >= ? OR revision <= ?)`,
+		id, next.Revision, oldest)
+	if err != nil {
+		return fmt.Errorf("storage: trim revisions of %q: %w", id, err)
+	}
+	if replaced != nil && replaced.Revision > oldest {
+		if err := insertRevision(conn, id, replaced); err != nil {
+			return err
+		}
+	}
+	return insertRevision(conn, id, next)
+}
+
+// insertRevision stores rev unless the history already has its number.
+func insertRevision(conn sqlConn, id string, rev *TextRevision) error {
+	_, err := conn.Exec(
+		`INSERT INTO text_revisions (text_id, revision, saved_at, content) VALUES (?, ?, ?, ?) ON CONFLICT DO NOTHING`,
+		id, rev.Revision, formatTime(rev.SavedAt), rev.Content,
+	)
+	if err != nil {
+		return fmt.Errorf("storage: insert revision %d of %q: %w", rev.Revision, id, err)
+	}
+	return nil
+}
+
+func insertCategory(conn sqlConn, cat *domain.Category) error {
+	_, err := conn.Exec(
+		`INSERT INTO categories (id, name, parent_id, icon, sort_order) VALUES (?, ?, ?, ?, ?)`,
+		cat.ID, cat.Name, cat.ParentID, cat.Icon, cat.SortOrder,
+	)
+	if err != nil {
+		return fmt.Errorf("storage: insert category %q: %w", cat.ID, err)
+	}
+	return nil
+}
+
+func updateCategoryRow(conn sqlConn, cat *domain.Category) error {
+	_, err := conn.Exec(
+		`UPDATE categories SET name = ?, parent_id = ?, icon = ?, sort_order = ? WHERE id = ?`,
+		cat.Name, cat.ParentID, cat.Icon, cat.SortOrder, cat.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("storage: update category %q: %w", cat.ID, err)
+	}
+	return nil
+}
+
+// queryCategories returns all categories in display order (see sortCategories).
+func queryCategories(conn sqlConn) ([]domain.Category, error) {
+	rows, err := conn.Query(`SELECT id, name, parent_id, icon, sort_order FROM categories ORDER BY sort_order, rowid`)
+	if err != nil {
+		return nil, fmt.Errorf("storage: query categories: %w", err)
+	}
+	var categories []domain.Category
+	for rows.Next() {
+		var cat domain.Category
+		if err := rows.Scan(&cat.ID, &cat.Name, &cat.ParentID, &cat.Icon, &cat.SortOrder); err != nil {
+			_ = rows.Close()
+			return nil, fmt.Errorf("storage: scan category: %w", err)
+		}
+		categories = append(categories, cat)
+	}
+	return categories, closeRows(rows)
+}
+
+func getMeta(conn sqlConn, key string) (string, error) {
+	var value string
+	err := conn.QueryRow(`SELECT value FROM meta WHERE key = ?`, key).Scan(&value)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return "", fmt.Errorf("storage: read meta %q: %w", key, err)
+	}
+	return value, nil
+}
+
+func setMeta(conn sqlConn, key, value string) error {
+	_, err := conn.Exec(
+		`INSERT INTO meta (key, value) VALUES (?, ?) ON CONFLICT (key) DO UPDATE SET value = excluded.value`,
+		key, value,
+	)
+	if err != nil {
+		return fmt.Errorf("storage: write meta %q: %w", key, err)
+	}
+	return nil
+}
+
+func rowExists(conn sqlConn, query string, args ...any) (bool, error) {
+	var one int
+	err := conn.QueryRow(query, args...).Scan(&one)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("storage: query: %w", err)
+	}
+	return true, nil
+}
+
+// requireAffected maps "no rows changed" to notFound.
+func requireAffected(res sql.Result, notFound error, id string) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("storage: rows affected: %w", err)
+	}
+	if n == 0 {
+		return fmt.Errorf("%w: %s", notFound, id)
+	}
+	return nil
+}
+
+func closeRows(rows *sql.Rows) error {
+	if err := rows.Err(); err != nil {
+		_ = rows.Close()
+		return fmt.Errorf("storage: iterate rows: %w", err)
+	}
+	return rows.Close()
+}
+
+// formatTime and parseTime keep timestamps as RFC 3339 text, the same
+// representation the JSON backend writes.
+func formatTime(t time.Time) string {
+	return t.Format(time.RFC3339Nano)
+}
+
+// formatOptionalTime stores a zero time as "".
+func formatOptionalTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return formatTime(t)
+}
+
+func parseTime(s string) (time.Time, error) {
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("storage: parse timestamp %q: %w", s, err)
+	}
+	return t, nil
+}
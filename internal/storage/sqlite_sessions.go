@@ -0,0 +1,127 @@
+// Copyright 2025 Asher Buk
+// SPDX-License-Identifier: Apache-2.0
+// https://github.com/AshBuk/FingerGo
+
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	domain "github.com/AshBuk/FingerGo/internal/domain"
+)
+
+// sessionColumns lists the sessions table columns in scanSession order.
+const sessionColumns = `id, started_at, completed_at, text_id, text_title, text_preview, category_id,
+	wpm, cpm, accuracy, duration_seconds, total_keystrokes, total_errors, character_count, mistakes, text_revision,
+	segment_id, segment_index, bigram_mistakes`
+
+// SQLiteSessionRepository is the SessionStore of the SQLite backend.
+// History is unbounded; query the sessions table directly for analytics.
+type SQLiteSessionRepository struct {
+	db *SQLiteDB
+}
+
+// NewSQLiteSessionRepository wires the repository to an open database.
+func NewSQLiteSessionRepository(db *SQLiteDB) (*SQLiteSessionRepository, error) {
+	if db == nil {
+		return nil, errNilDatabase
+	}
+	return &SQLiteSessionRepository{db: db}, nil
+}
+
+// Record persists a session payload and returns the stored session.
+func (r *SQLiteSessionRepository) Record(payload *domain.SessionPayload) (domain.TypingSession, error) {
+	if err := r.db.storage.checkWritable(); err != nil {
+		return domain.TypingSession{}, err
+	}
+	session := payload.ToTypingSession(time.Now())
+	if session.ID == "" {
+		session.ID = uuid.NewString()
+	}
+	if err := insertSession(r.db.db, &session); err != nil {
+		return domain.TypingSession{}, err
+	}
+	return session, nil
+}
+
+// List returns recent sessions (newest first). limit <= 0 returns all.
+func (r *SQLiteSessionRepository) List(limit int) ([]domain.TypingSession, error) {
+	if limit <= 0 {
+		limit = -1 // SQLite: no limit
+	}
+	rows, err := r.db.db.Query(`SELECT `+sessionColumns+` FROM sessions ORDER BY rowid DESC LIMIT ?`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("storage: query sessions: %w", err)
+	}
+	var result []domain.TypingSession
+	for rows.Next() {
+		var s domain.TypingSession
+		var startedAt, completedAt, mistakes, bigrams string
+		if err := rows.Scan(&s.ID, &startedAt, &completedAt, &s.TextID, &s.TextTitle, &s.TextPreview, &s.CategoryID,
+			&s.WPM, &s.CPM, &s.Accuracy, &s.DurationSeconds, &s.TotalKeystrokes, &s.TotalErrors, &s.CharacterCount, &mistakes, &s.TextRevision,
+			&s.SegmentID, &s.SegmentIndex, &bigrams); err != nil {
+			_ = rows.Close()
+			return nil, fmt.Errorf("storage: scan session: %w", err)
+		}
+		if err := decodeSessionColumns(&s, startedAt, completedAt, mistakes, bigrams); err != nil {
+			_ = rows.Close()
+			return nil, err
+		}
+		result = append(result, s)
+	}
+	return result, closeRows(rows)
+}
+
+func insertSession(conn sqlConn, s *domain.TypingSession) error {
+	mistakes, err := encodeCounts(s.Mistakes)
+	if err != nil {
+		return fmt.Errorf("storage: marshal mistakes for session %q: %w", s.ID, err)
+	}
+	bigrams, err := encodeCounts(s.BigramMistakes)
+	if err != nil {
+		return fmt.Errorf("storage: marshal bigram mistakes for session %q: %w", s.ID, err)
+	}
+	_, err = conn.Exec(`INSERT INTO sessions (`+sessionColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		s.ID, formatTime(s.StartedAt), formatTime(s.CompletedAt), s.TextID, s.TextTitle, s.TextPreview, s.CategoryID,
+		s.WPM, s.CPM, s.Accuracy, s.DurationSeconds, s.TotalKeystrokes, s.TotalErrors, s.CharacterCount, mistakes, s.TextRevision,
+		s.SegmentID, s.SegmentIndex, bigrams,
+	)
+	if err != nil {
+		return fmt.Errorf("storage: insert session %q: %w", s.ID, err)
+	}
+	return nil
+}
+
+// encodeCounts stores a mistake map as JSON; empty maps as "".
+func encodeCounts(counts map[string]int) (string, error) {
+	if len(counts) == 0 {
+		return "", nil
+	}
+	data, err := json.Marshal(counts)
+	return string(data), err
+}
+
+func decodeSessionColumns(s *domain.TypingSession, startedAt, completedAt, mistakes, bigrams string) error {
+	var err error
+	if s.StartedAt, err = parseTime(startedAt); err != nil {
+		return err
+	}
+	if s.CompletedAt, err = parseTime(completedAt); err != nil {
+		return err
+	}
+	if mistakes != "" {
+		if err := json.Unmarshal([]byte(mistakes), &s.Mistakes); err != nil {
+			return fmt.Errorf("storage: decode mistakes for session %q: %w", s.ID, err)
+		}
+	}
+	if bigrams != "" {
+		if err := json.Unmarshal([]byte(bigrams), &s.BigramMistakes); err != nil {
+			return fmt.Errorf("storage: decode bigram mistakes for session %q: %w", s.ID, err)
+		}
+	}
+	return nil
+}
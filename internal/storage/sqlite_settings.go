@@ -0,0 +1,93 @@
+// Copyright 2025 Asher Buk
+// SPDX-License-Identifier: Apache-2.0
+// https://github.com/AshBuk/FingerGo
+
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	domain "github.com/AshBuk/FingerGo/internal/domain"
+)
+
+// SQLiteSettingsRepository is the SettingsStore of the SQLite backend.
+// Settings are kept as a single JSON row so new fields need no schema change.
+type SQLiteSettingsRepository struct {
+	db *SQLiteDB
+}
+
+// NewSQLiteSettingsRepository wires the repository to an open database.
+func NewSQLiteSettingsRepository(db *SQLiteDB) (*SQLiteSettingsRepository, error) {
+	if db == nil {
+		return nil, errNilDatabase
+	}
+	return &SQLiteSettingsRepository{db: db}, nil
+}
+
+// Load returns current settings, or defaults if none were saved.
+func (r *SQLiteSettingsRepository) Load() (domain.Settings, error) {
+	return readSettings(r.db.db)
+}
+
+// Save persists the entire settings object.
+func (r *SQLiteSettingsRepository) Save(s domain.Settings) error {
+	if err := r.db.storage.checkWritable(); err != nil {
+		return err
+	}
+	return writeSettings(r.db.db, s)
+}
+
+// Update modifies a single setting by key and persists the change.
+// Supported keys are listed on applySetting.
+func (r *SQLiteSettingsRepository) Update(key string, value any) error {
+	if err := r.db.storage.checkWritable(); err != nil {
+		return err
+	}
+	return r.db.inTx(func(tx *sql.Tx) error {
+		current, err := readSettings(tx)
+		if err != nil {
+			return err
+		}
+		updated, err := applySetting(current, key, value)
+		if err != nil {
+			return err
+		}
+		return writeSettings(tx, updated)
+	})
+}
+
+// readSettings decodes the stored row over the defaults, so fields added in
+// later versions start with their default value.
+func readSettings(conn sqlConn) (domain.Settings, error) {
+	settings := domain.DefaultSettings()
+	var data string
+	err := conn.QueryRow(`SELECT data FROM settings WHERE id = 1`).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return settings, nil
+	}
+	if err != nil {
+		return settings, fmt.Errorf("storage: read settings: %w", err)
+	}
+	if err := json.Unmarshal([]byte(data), &settings); err != nil {
+		return domain.DefaultSettings(), fmt.Errorf("storage: decode settings: %w", err)
+	}
+	return settings, nil
+}
+
+func writeSettings(conn sqlConn, s domain.Settings) error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return fmt.Errorf("storage: marshal settings: %w", err)
+	}
+	_, err = conn.Exec(
+		`INSERT INTO settings (id, data) VALUES (1, ?) ON CONFLICT (id) DO UPDATE SET data = excluded.data`,
+		string(data),
+	)
+	if err != nil {
+		return fmt.Errorf("storage: write settings: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,298 @@
+// Copyright 2025 Asher Buk
+// SPDX-License-Identifier: Apache-2.0
+// https://github.com/AshBuk/FingerGo
+
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	domain "github.com/AshBuk/FingerGo/internal/domain"
+)
+
+// stores bundles the three repositories of one backend.
+type stores struct {
+	texts    TextStore
+	sessions SessionStore
+	settings SettingsStore
+	mgr      *Manager
+}
+
+// openSQLiteDB opens the SQLite backend on an initialized manager.
+func openSQLiteDB(t *testing.T, mgr *Manager) *SQLiteDB {
+	t.Helper()
+	db, err := OpenSQLite(mgr)
+	if err != nil {
+		t.Fatalf("OpenSQLite() error: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	return db
+}
+
+// backends returns fresh stores for every backend, keyed by name.
+func backends(t *testing.T) map[Backend]func(t *testing.T) stores {
+	t.Helper()
+	return map[Backend]func(t *testing.T) stores{
+		BackendJSON: func(t *testing.T) stores {
+			mgr := setupManager(t)
+			texts, _ := NewTextRepository(mgr)
+			sessions, _ := NewSessionRepository(mgr)
+			settings, _ := NewSettingsRepository(mgr)
+			return stores{texts, sessions, settings, mgr}
+		},
+		BackendSQLite: func(t *testing.T) stores {
+			mgr := setupManager(t)
+			db := openSQLiteDB(t, mgr)
+			texts, _ := NewSQLiteTextRepository(db)
+			sessions, _ := NewSQLiteSessionRepository(db)
+			settings, _ := NewSQLiteSettingsRepository(db)
+			return stores{texts, sessions, settings, mgr}
+		},
+	}
+}
+
+func TestStores_TextContract(t *testing.T) {
+	for name, open := range backends(t) {
+		t.Run(string(name), func(t *testing.T) {
+			s := open(t)
+			text := &domain.Text{ID: "t1", Title: "One", Content: "fmt.Println()", Language: "go", CategoryID: "welcome"}
+			if err := s.texts.SaveText(text); err != nil {
+				t.Fatalf("SaveText() error: %v", err)
+			}
+			if err := s.texts.SaveText(text); !errors.Is(err, ErrTextExists) {
+				t.Errorf("duplicate SaveText(): expected ErrTextExists, got %v", err)
+			}
+			text.Title, text.Content = "One v2", "updated"
+			if err := s.texts.UpdateText(text); err != nil {
+				t.Fatalf("UpdateText() error: %v", err)
+			}
+			got, err := s.texts.Text("t1")
+			if err != nil || got.Title != "One v2" || got.Content != "updated" {
+				t.Errorf("Text() = %+v, %v; want updated text", got, err)
+			}
+			missing := &domain.Text{ID: "nope", Title: "x", Content: "x", Language: "text"}
+			if err := s.texts.UpdateText(missing); !errors.Is(err, ErrTextNotFound) {
+				t.Errorf("UpdateText() missing: expected ErrTextNotFound, got %v", err)
+			}
+			if _, err := s.texts.Text("../etc/passwd"); !errors.Is(err, ErrTextNotFound) {
+				t.Errorf("Text() traversal: expected ErrTextNotFound, got %v", err)
+			}
+			if _, err := s.texts.DefaultText(); err != nil {
+				t.Errorf("DefaultText() error: %v", err)
+			}
+
+			if err := s.texts.SaveCategory(&domain.Category{ID: "welcome", Name: "Other"}); !errors.Is(err, ErrCategoryExists) {
+				t.Errorf("duplicate category ID: expected ErrCategoryExists, got %v", err)
+			}
+			if err := s.texts.SaveCategory(&domain.Category{ID: "other", Name: "Welcome"}); !errors.Is(err, ErrCategoryExists) {
+				t.Errorf("duplicate category name: expected ErrCategoryExists, got %v", err)
+			}
+			if err := s.texts.DeleteCategory("welcome"); err != nil {
+				t.Fatalf("DeleteCategory() error: %v", err)
+			}
+			if _, err := s.texts.Text("t1"); !errors.Is(err, ErrTextNotFound) {
+				t.Errorf("texts of a deleted category should be removed, got %v", err)
+			}
+			if err := s.texts.DeleteCategory("welcome"); !errors.Is(err, ErrCategoryNotFound) {
+				t.Errorf("second DeleteCategory(): expected ErrCategoryNotFound, got %v", err)
+			}
+			if err := s.texts.DeleteText("t1"); !errors.Is(err, ErrTextNotFound) {
+				t.Errorf("DeleteText() missing: expected ErrTextNotFound, got %v", err)
+			}
+			lib, err := s.texts.Library()
+			if err != nil || len(lib.Texts) != 0 || len(lib.Categories) != 0 {
+				t.Errorf("Library() = %+v, %v; want empty library", lib, err)
+			}
+		})
+	}
+}
+
+func TestStores_SessionAndSettingsContract(t *testing.T) {
+	for name, open := range backends(t) {
+		t.Run(string(name), func(t *testing.T) {
+			s := open(t)
+			for i := 1; i <= 3; i++ {
+				payload := &domain.SessionPayload{WPM: float64(i * 10), Mistakes: map[string]int{"a": i}, BigramMistakes: map[string]int{"ta": i}}
+				if _, err := s.sessions.Record(payload); err != nil {
+					t.Fatalf("Record() error: %v", err)
+				}
+			}
+			recent, err := s.sessions.List(2)
+			if err != nil {
+				t.Fatalf("List() error: %v", err)
+			}
+			if len(recent) != 2 || recent[0].WPM != 30 || recent[0].Mistakes["a"] != 3 || recent[0].BigramMistakes["ta"] != 3 {
+				t.Errorf("List(2) = %+v; want two newest sessions with mistakes", recent)
+			}
+			if all, _ := s.sessions.List(0); len(all) != 3 {
+				t.Errorf("List(0) returned %d sessions, want 3", len(all))
+			}
+
+			if err := s.settings.Update("theme", "light"); err != nil {
+				t.Fatalf("Update() error: %v", err)
+			}
+			if err := s.settings.Update("textZoom", 5.0); err == nil {
+				t.Error("Update() should reject out-of-range textZoom")
+			}
+			if err := s.settings.Update("bogus", true); err == nil {
+				t.Error("Update() should reject unknown keys")
+			}
+			got, err := s.settings.Load()
+			if err != nil || got.Theme != "light" || got.TextZoom != domain.DefaultSettings().TextZoom {
+				t.Errorf("Load() = %+v, %v; want theme light with default zoom", got, err)
+			}
+			got.NormalizeRules, err = WithNormalizeRules(got.NormalizeRules, "yaml", &NormalizeRules{TabWidth: 4})
+			if err != nil {
+				t.Fatalf("WithNormalizeRules() error: %v", err)
+			}
+			if err := s.settings.Save(got); err != nil {
+				t.Fatalf("Save() error: %v", err)
+			}
+			if got, _ := s.settings.Load(); got.NormalizeRules["yaml"] != (NormalizeRules{TabWidth: 4}) || got.Theme != "light" {
+				t.Errorf("Load() = %+v, want the yaml rules override", got)
+			}
+		})
+	}
+}
+
+func TestOpenSQLite_ImportsJSONData(t *testing.T) {
+	mgr := setupManager(t)
+	texts, _ := NewTextRepository(mgr)
+	if err := texts.SaveText(&domain.Text{ID: "mine", Title: "Mine", Content: "hello", Language: "text"}); err != nil {
+		t.Fatalf("SaveText() error: %v", err)
+	}
+	sessions, _ := NewSessionRepository(mgr)
+	for i := 0; i < 2; i++ {
+		if _, err := sessions.Record(&domain.SessionPayload{WPM: float64(i)}); err != nil {
+			t.Fatalf("Record() error: %v", err)
+		}
+	}
+	settings, _ := NewSettingsRepository(mgr)
+	_ = settings.Update("keyboardLayout", "en-dvorak")
+
+	report, err := MigrateToSQLite(mgr)
+	if err != nil {
+		t.Fatalf("MigrateToSQLite() error: %v", err)
+	}
+	if report.Texts != 2 || report.Categories != 1 || report.Sessions != 2 || len(report.Skipped) != 0 {
+		t.Errorf("got report %+v", report)
+	}
+	if _, err := MigrateToSQLite(mgr); !errors.Is(err, ErrDatabaseExists) {
+		t.Errorf("second MigrateToSQLite(): expected ErrDatabaseExists, got %v", err)
+	}
+
+	db := openSQLiteDB(t, mgr)
+	sqlTexts, _ := NewSQLiteTextRepository(db)
+	if got, err := sqlTexts.Text("mine"); err != nil || got.Content != "hello" {
+		t.Errorf("Text() = %+v, %v; want migrated content", got, err)
+	}
+	if got, err := sqlTexts.DefaultText(); err != nil || got.Content == "" {
+		t.Errorf("DefaultText() = %+v, %v; want embedded default", got, err)
+	}
+	sqlSessions, _ := NewSQLiteSessionRepository(db)
+	jsonHistory, _ := sessions.List(0)
+	sqlHistory, _ := sqlSessions.List(0)
+	if len(sqlHistory) != 2 || sqlHistory[0].ID != jsonHistory[0].ID {
+		t.Errorf("session order not preserved: %+v", sqlHistory)
+	}
+	sqlSettings, _ := NewSQLiteSettingsRepository(db)
+	if got, _ := sqlSettings.Load(); got.KeyboardLayout != "en-dvorak" {
+		t.Errorf("got layout %q, want migrated en-dvorak", got.KeyboardLayout)
+	}
+}
+
+func TestOpenSQLite_RejectsNewerSchema(t *testing.T) {
+	mgr := setupManager(t)
+	raw, err := sql.Open("sqlite", mgr.join(sqliteFile))
+	if err != nil {
+		t.Fatalf("sql.Open() error: %v", err)
+	}
+	if _, err := raw.Exec("PRAGMA user_version = 99"); err != nil {
+		t.Fatalf("set user_version: %v", err)
+	}
+	_ = raw.Close()
+	if _, err := OpenSQLite(mgr); !errors.Is(err, ErrSchemaTooNew) {
+		t.Errorf("expected ErrSchemaTooNew, got %v", err)
+	}
+}
+
+func TestOpenSQLite_UpgradesOlderSchema(t *testing.T) {
+	mgr := setupManager(t)
+	raw, err := sql.Open("sqlite", mgr.join(sqliteFile))
+	if err != nil {
+		t.Fatalf("sql.Open() error: %v", err)
+	}
+	for _, stmt := range sqliteSchema {
+		if _, err := raw.Exec(stmt); err != nil {
+			t.Fatalf("create v1 schema: %v", err)
+		}
+	}
+	if _, err := raw.Exec(`INSERT INTO categories (id, name) VALUES ('old', 'Old')`); err != nil {
+		t.Fatalf("insert category: %v", err)
+	}
+	if _, err := raw.Exec("PRAGMA user_version = 1"); err != nil {
+		t.Fatalf("set user_version: %v", err)
+	}
+	_ = raw.Close()
+
+	db := openSQLiteDB(t, mgr)
+	var version int
+	if err := db.db.QueryRow("PRAGMA user_version").Scan(&version); err != nil || version != sqliteSchemaVersion {
+		t.Errorf("user_version = %d, %v; want %d", version, err, sqliteSchemaVersion)
+	}
+	texts, _ := NewSQLiteTextRepository(db)
+	lib, err := texts.Library()
+	if err != nil || len(lib.Categories) != 1 || lib.Categories[0].ID != "old" {
+		t.Errorf("Library() = %+v, %v; want the v1 category kept", lib.Categories, err)
+	}
+}
+
+func TestOpenSQLite_ReadOnly(t *testing.T) {
+	owner := setupManager(t)
+	defer owner.Close()
+	viewer, _ := NewReadOnly(owner.Root())
+	if _, err := OpenSQLite(viewer); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("read-only open without database: expected ErrNotExist, got %v", err)
+	}
+
+	openSQLiteDB(t, owner)
+	db := openSQLiteDB(t, viewer)
+	texts, _ := NewSQLiteTextRepository(db)
+	if _, err := texts.DefaultText(); err != nil {
+		t.Errorf("DefaultText() on read-only view: %v", err)
+	}
+	err := texts.SaveText(&domain.Text{ID: "nope", Title: "Nope", Content: "x", Language: "text"})
+	if !errors.Is(err, ErrReadOnly) {
+		t.Errorf("SaveText() on read-only view: expected ErrReadOnly, got %v", err)
+	}
+	sessions, _ := NewSQLiteSessionRepository(db)
+	if _, err := sessions.Record(&domain.SessionPayload{WPM: 1}); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("Record() on read-only view: expected ErrReadOnly, got %v", err)
+	}
+}
+
+func TestSelectBackend(t *testing.T) {
+	root := t.TempDir()
+	t.Setenv(BackendEnv, "")
+	if got, _ := SelectBackend(root); got != BackendJSON {
+		t.Errorf("empty dir: got %q, want json", got)
+	}
+	if err := os.WriteFile(filepath.Join(root, sqliteFile), nil, 0o600); err != nil {
+		t.Fatalf("WriteFile() error: %v", err)
+	}
+	if got, _ := SelectBackend(root); got != BackendSQLite {
+		t.Errorf("with %s: got %q, want sqlite", sqliteFile, got)
+	}
+	t.Setenv(BackendEnv, "JSON")
+	if got, _ := SelectBackend(root); got != BackendJSON {
+		t.Errorf("env override: got %q, want json", got)
+	}
+	t.Setenv(BackendEnv, "postgres")
+	if _, err := SelectBackend(root); err == nil {
+		t.Error("expected error for unknown backend")
+	}
+}
@@ -0,0 +1,634 @@
+// Copyright 2025 Asher Buk
+// SPDX-License-Identifier: Apache-2.0
+// https://github.com/AshBuk/FingerGo
+
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"unicode/utf8"
+
+	domain "github.com/AshBuk/FingerGo/internal/domain"
+)
+
+// metaDefaultTextID is the meta key holding TextLibrary.DefaultTextID.
+const metaDefaultTextID = "defaultTextId"
+
+// SQLiteTextRepository is the TextStore of the SQLite backend.
+// Validation and error values match TextRepository. Writes hold search.mu
+// until the search index is updated, so it sees them in commit order.
+type SQLiteTextRepository struct {
+	db         *SQLiteDB
+	search     *searchIndex
+	normalizer atomic.Pointer[Normalizer] // applied by SaveText and UpdateText; nil for defaults
+}
+
+// NewSQLiteTextRepository wires the repository to an open database.
+func NewSQLiteTextRepository(db *SQLiteDB) (*SQLiteTextRepository, error) {
+	if db == nil {
+		return nil, errNilDatabase
+	}
+	return &SQLiteTextRepository{db: db, search: &searchIndex{}}, nil
+}
+
+// Library returns metadata for texts and categories (content stripped).
+func (r *SQLiteTextRepository) Library() (domain.TextLibrary, error) {
+	var lib domain.TextLibrary
+	defaultID, err := getMeta(r.db.db, metaDefaultTextID)
+	if err != nil {
+		return lib, err
+	}
+	lib.DefaultTextID = defaultID
+
+	if lib.Categories, err = queryCategories(r.db.db); err != nil {
+		return lib, err
+	}
+
+	rows, err := r.db.db.Query(`SELECT id, title, category_id, language, is_favorite, created_at, revision, revised_at, segment_mode, segment_size, metrics
+		FROM texts ORDER BY rowid`)
+	if err != nil {
+		return lib, fmt.Errorf("storage: query texts: %w", err)
+	}
+	for rows.Next() {
+		var text domain.Text
+		var createdAt, revisedAt, metrics string
+		if err := rows.Scan(&text.ID, &text.Title, &text.CategoryID, &text.Language, &text.IsFavorite, &createdAt, &text.Revision,
+			&revisedAt, &text.SegmentMode, &text.SegmentSize, &metrics); err != nil {
+			_ = rows.Close()
+			return lib, fmt.Errorf("storage: scan text: %w", err)
+		}
+		if err := decodeTextColumns(&text, createdAt, revisedAt, metrics); err != nil {
+			_ = rows.Close()
+			return lib, err
+		}
+		lib.Texts = append(lib.Texts, text)
+	}
+	return lib, closeRows(rows)
+}
+
+// DefaultText resolves and returns the configured default text with content.
+func (r *SQLiteTextRepository) DefaultText() (domain.Text, error) {
+	id, err := getMeta(r.db.db, metaDefaultTextID)
+	if err != nil {
+		return domain.Text{}, err
+	}
+	if id == "" {
+		return domain.Text{}, errDefaultTextUnset
+	}
+	return r.Text(id)
+}
+
+// Text returns a text (metadata + content) by identifier.
+func (r *SQLiteTextRepository) Text(id string) (domain.Text, error) {
+	if err := validateTextID(id); err != nil {
+		return domain.Text{}, fmt.Errorf("%w: %s", ErrTextNotFound, id)
+	}
+	texts, err := queryTexts(r.db.db, "id = ?", id)
+	if err != nil {
+		return domain.Text{}, err
+	}
+	if len(texts) == 0 {
+		return domain.Text{}, fmt.Errorf("%w: %s", ErrTextNotFound, id)
+	}
+	return texts[0], nil
+}
+
+// Progress returns the progress cursor of a text; the zero TextProgress
+// before the first recorded session.
+func (r *SQLiteTextRepository) Progress(id string) (TextProgress, error) {
+	if err := validateTextID(id); err != nil {
+		return TextProgress{}, fmt.Errorf("%w: %s", ErrTextNotFound, id)
+	}
+	var progress TextProgress
+	var updatedAt string
+	err := r.db.db.QueryRow(
+		`SELECT segment_id, segment_index, finished, updated_at FROM text_progress WHERE text_id = ?`, id,
+	).Scan(&progress.SegmentID, &progress.SegmentIndex, &progress.Finished, &updatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		exists, err := rowExists(r.db.db, `SELECT 1 FROM texts WHERE id = ?`, id)
+		if err != nil {
+			return TextProgress{}, err
+		}
+		if !exists {
+			return TextProgress{}, fmt.Errorf("%w: %s", ErrTextNotFound, id)
+		}
+		return TextProgress{}, nil
+	}
+	if err != nil {
+		return TextProgress{}, fmt.Errorf("storage: query progress of %q: %w", id, err)
+	}
+	if progress.UpdatedAt, err = parseTime(updatedAt); err != nil {
+		return TextProgress{}, err
+	}
+	return progress, nil
+}
+
+// SaveProgress replaces the progress cursor of a text.
+func (r *SQLiteTextRepository) SaveProgress(id string, progress TextProgress) error {
+	if err := validateTextID(id); err != nil {
+		return err
+	}
+	if err := r.db.storage.checkWritable(); err != nil {
+		return err
+	}
+	return r.db.inTx(func(tx *sql.Tx) error {
+		exists, err := rowExists(tx, `SELECT 1 FROM texts WHERE id = ?`, id)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return fmt.Errorf("%w: %s", ErrTextNotFound, id)
+		}
+		return upsertProgress(tx, id, &progress)
+	})
+}
+
+// Normalizer returns the content normalization applied by SaveText and
+// UpdateText.
+func (r *SQLiteTextRepository) Normalizer() *Normalizer {
+	return r.normalizer.Load()
+}
+
+// SetNormalizer replaces the content normalization; nil applies the
+// per-language defaults.
+func (r *SQLiteTextRepository) SetNormalizer(n *Normalizer) {
+	r.normalizer.Store(n)
+}
+
+// RefreshMetrics recomputes the difficulty metrics of texts analyzed on
+// another layout or by older formulas and returns how many it updated.
+func (r *SQLiteTextRepository) RefreshMetrics() (int, error) {
+	if err := r.db.storage.checkWritable(); err != nil {
+		return 0, err
+	}
+	layout := r.Normalizer().Layout()
+	updated := 0
+	err := r.db.inTx(func(tx *sql.Tx) error {
+		texts, err := queryTexts(tx, "")
+		if err != nil {
+			return err
+		}
+		stale, metrics, _ := staleMetrics(texts, layout, func(i int) (string, error) {
+			return texts[i].Content, nil
+		})
+		for j, i := range stale {
+			if err := updateMetricsRow(tx, texts[i].ID, &metrics[j]); err != nil {
+				return err
+			}
+		}
+		updated = len(stale)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return updated, nil
+}
+
+// SaveText creates a new text entry with content.
+// Returns ErrTextExists if a text with the same ID already exists.
+func (r *SQLiteTextRepository) SaveText(text *domain.Text) error {
+	_, err := r.SaveTextWithReport(text)
+	return err
+}
+
+// SaveTextWithReport is SaveText, also reporting what normalization changed
+// in the content and which characters the keyboard layout cannot type.
+func (r *SQLiteTextRepository) SaveTextWithReport(text *domain.Text) (NormalizeReport, error) {
+	report, err := prepareText(r.Normalizer(), text)
+	if err != nil {
+		return NormalizeReport{}, err
+	}
+	if err := r.saveText(text); err != nil {
+		return NormalizeReport{}, err
+	}
+	return report, nil
+}
+
+// saveText stores a text prepared by SaveTextWithReport.
+func (r *SQLiteTextRepository) saveText(text *domain.Text) error {
+	if err := r.db.storage.checkWritable(); err != nil {
+		return err
+	}
+	r.search.mu.Lock()
+	defer r.search.mu.Unlock()
+	err := r.db.inTx(func(tx *sql.Tx) error {
+		exists, err := rowExists(tx, `SELECT 1 FROM texts WHERE id = ?`, text.ID)
+		if err != nil {
+			return err
+		}
+		if exists {
+			return fmt.Errorf("%w: %s", ErrTextExists, text.ID)
+		}
+		first := firstRevision(text)
+		if err := insertText(tx, text); err != nil {
+			return err
+		}
+		return insertRevision(tx, text.ID, &first)
+	})
+	if err != nil {
+		return err
+	}
+	r.search.put(text)
+	return nil
+}
+
+// UpdateText modifies an existing text entry.
+func (r *SQLiteTextRepository) UpdateText(text *domain.Text) error {
+	_, err := r.UpdateTextWithReport(text)
+	return err
+}
+
+// UpdateTextWithReport is UpdateText, reporting normalization like
+// SaveTextWithReport.
+func (r *SQLiteTextRepository) UpdateTextWithReport(text *domain.Text) (NormalizeReport, error) {
+	report, err := prepareText(r.Normalizer(), text)
+	if err != nil {
+		return NormalizeReport{}, err
+	}
+	if err := r.updateText(text); err != nil {
+		return NormalizeReport{}, err
+	}
+	return report, nil
+}
+
+// updateText stores a text prepared by UpdateTextWithReport.
+func (r *SQLiteTextRepository) updateText(text *domain.Text) error {
+	if err := r.db.storage.checkWritable(); err != nil {
+		return err
+	}
+	r.search.mu.Lock()
+	defer r.search.mu.Unlock()
+	err := r.db.inTx(func(tx *sql.Tx) error {
+		prev, err := queryTexts(tx, "id = ?", text.ID)
+		if err != nil {
+			return err
+		}
+		if len(prev) == 0 {
+			return fmt.Errorf("%w: %s", ErrTextNotFound, text.ID)
+		}
+		keepSegmentation(&prev[0], text)
+		if replaced, added := nextRevision(&prev[0], text); added != nil {
+			if err := recordRevision(tx, text.ID, replaced, added); err != nil {
+				return err
+			}
+		}
+		metrics, err := encodeMetrics(text.Metrics)
+		if err != nil {
+			return fmt.Errorf("storage: encode metrics of %q: %w", text.ID, err)
+		}
+		_, err = tx.Exec(
+			`UPDATE texts SET title = ?, content = ?, category_id = ?, language = ?, is_favorite = ?, created_at = ?, revision = ?,
+				revised_at = ?, segment_mode = ?, segment_size = ?, metrics = ? WHERE id = ?`,
+			text.Title, text.Content, text.CategoryID, text.Language, text.IsFavorite, formatTime(text.CreatedAt), text.Revision,
+			formatOptionalTime(text.RevisedAt), text.SegmentMode, text.SegmentSize, metrics, text.ID,
+		)
+		if err != nil {
+			return fmt.Errorf("storage: update text %q: %w", text.ID, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	r.search.put(text)
+	return nil
+}
+
+// Revisions returns the revision history of a text, newest first, without
+// content.
+func (r *SQLiteTextRepository) Revisions(id string) ([]TextRevision, error) {
+	history, err := r.history(id)
+	if err != nil {
+		return nil, err
+	}
+	return revisionListing(history), nil
+}
+
+// Revision returns one revision of a text with its content.
+func (r *SQLiteTextRepository) Revision(id string, revision int) (TextRevision, error) {
+	history, err := r.history(id)
+	if err != nil {
+		return TextRevision{}, err
+	}
+	return findRevision(history, id, revision)
+}
+
+// history returns the revisions of text id, oldest first, the current one
+// included.
+func (r *SQLiteTextRepository) history(id string) ([]TextRevision, error) {
+	text, err := r.Text(id)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := r.db.db.Query(
+		`SELECT revision, saved_at, content FROM text_revisions WHERE text_id = ? ORDER BY revision`, id,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("storage: query revisions of %q: %w", id, err)
+	}
+	var history []TextRevision
+	for rows.Next() {
+		var rev TextRevision
+		var savedAt string
+		if err := rows.Scan(&rev.Revision, &savedAt, &rev.Content); err != nil {
+			_ = rows.Close()
+			return nil, fmt.Errorf("storage: scan revision: %w", err)
+		}
+		if rev.SavedAt, err = parseTime(savedAt); err != nil {
+			_ = rows.Close()
+			return nil, err
+		}
+		rev.Length = utf8.RuneCountInString(rev.Content)
+		history = append(history, rev)
+	}
+	if err := closeRows(rows); err != nil {
+		return nil, err
+	}
+	return withCurrent(history, &text), nil
+}
+
+// DeleteText moves a text to the trash.
+func (r *SQLiteTextRepository) DeleteText(id string) error {
+	if err := validateTextID(id); err != nil {
+		return err
+	}
+	if err := r.db.storage.checkWritable(); err != nil {
+		return err
+	}
+	r.search.mu.Lock()
+	defer r.search.mu.Unlock()
+	var trashID string
+	err := r.db.inTx(func(tx *sql.Tx) error {
+		texts, err := queryTexts(tx, "id = ?", id)
+		if err != nil {
+			return err
+		}
+		if len(texts) == 0 {
+			return fmt.Errorf("%w: %s", ErrTextNotFound, id)
+		}
+		if trashID, err = r.db.storage.writeTrash(texts[0].Title, nil, texts); err != nil {
+			return err
+		}
+		return deleteTextRow(tx, id)
+	})
+	if err != nil {
+		if trashID != "" {
+			r.db.storage.discardTrash(trashID)
+		}
+		return err
+	}
+	r.search.remove(id)
+	return nil
+}
+
+// ApplyBulk applies change to every text in ids in one transaction. Unknown
+// IDs are reported in their result and skipped; on any error no text is
+// changed.
+func (r *SQLiteTextRepository) ApplyBulk(ids []string, change BulkChange) ([]BulkResult, error) {
+	if err := change.validate(); err != nil {
+		return nil, err
+	}
+	if err := r.db.storage.checkWritable(); err != nil {
+		return nil, err
+	}
+	r.search.mu.Lock()
+	defer r.search.mu.Unlock()
+	var results []BulkResult
+	var texts []domain.Text
+	var trashID string
+	err := r.db.inTx(func(tx *sql.Tx) error {
+		var err error
+		results, texts, err = bulkTexts(tx, ids, change.CategoryID)
+		if err != nil || len(texts) == 0 {
+			return err
+		}
+		if change.Delete {
+			trashID, err = r.bulkDelete(tx, texts)
+			return err
+		}
+		return bulkUpdate(tx, texts, &change)
+	})
+	if err != nil {
+		if trashID != "" {
+			r.db.storage.discardTrash(trashID)
+		}
+		return nil, err
+	}
+	for i := range texts {
+		if change.Delete {
+			r.search.remove(texts[i].ID)
+		} else {
+			r.search.put(&texts[i])
+		}
+	}
+	return results, nil
+}
+
+// bulkTexts checks the target category of a bulk change (nil or "" for
+// none) and returns the per-ID results and the texts that exist.
+func bulkTexts(tx *sql.Tx, ids []string, categoryID *string) ([]BulkResult, []domain.Text, error) {
+	if categoryID != nil && *categoryID != "" {
+		exists, err := rowExists(tx, `SELECT 1 FROM categories WHERE id = ?`, *categoryID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !exists {
+			return nil, nil, fmt.Errorf("%w: %s", ErrCategoryNotFound, *categoryID)
+		}
+	}
+	results, targets, err := bulkTargets(ids, func(id string) (bool, error) {
+		return rowExists(tx, `SELECT 1 FROM texts WHERE id = ?`, id)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	texts := make([]domain.Text, 0, len(targets))
+	for _, id := range targets {
+		found, err := queryTexts(tx, "id = ?", id)
+		if err != nil {
+			return nil, nil, err
+		}
+		texts = append(texts, found...)
+	}
+	return results, texts, nil
+}
+
+// bulkDelete moves texts to a new trash entry and deletes their rows. The
+// trash entry ID is returned even on error, for the caller to discard once
+// the transaction is rolled back.
+func (r *SQLiteTextRepository) bulkDelete(tx *sql.Tx, texts []domain.Text) (string, error) {
+	trashID, err := r.db.storage.writeTrash(bulkTrashName(texts), nil, texts)
+	if err != nil {
+		return "", err
+	}
+	for i := range texts {
+		if err := deleteTextRow(tx, texts[i].ID); err != nil {
+			return trashID, err
+		}
+	}
+	return trashID, nil
+}
+
+// bulkUpdate applies change to texts and writes their rows back.
+func bulkUpdate(tx *sql.Tx, texts []domain.Text, change *BulkChange) error {
+	for i := range texts {
+		if err := updateBulkRow(tx, &texts[i], change); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SaveCategory creates a new category entry.
+// Returns ErrCategoryExists if a category with the same ID or name already exists.
+func (r *SQLiteTextRepository) SaveCategory(cat *domain.Category) error {
+	if err := validateCategory(cat); err != nil {
+		return err
+	}
+	if err := r.db.storage.checkWritable(); err != nil {
+		return err
+	}
+	return r.db.inTx(func(tx *sql.Tx) error {
+		var existingID string
+		err := tx.QueryRow(`SELECT id FROM categories WHERE id = ? OR name = ? LIMIT 1`, cat.ID, cat.Name).Scan(&existingID)
+		switch {
+		case err == nil && existingID == cat.ID:
+			return fmt.Errorf("%w: %s", ErrCategoryExists, cat.ID)
+		case err == nil:
+			return fmt.Errorf("%w: %s", ErrCategoryExists, cat.Name)
+		case !errors.Is(err, sql.ErrNoRows):
+			return fmt.Errorf("storage: query category %q: %w", cat.ID, err)
+		}
+		return insertCategory(tx, cat)
+	})
+}
+
+// UpdateCategory replaces the name, icon, parent and sort order of an
+// existing category. Returns ErrCategoryExists if the name is taken by another
+// category and ErrCategoryCycle if the parent lies in its own subtree.
+func (r *SQLiteTextRepository) UpdateCategory(cat *domain.Category) error {
+	if err := validateCategory(cat); err != nil {
+		return err
+	}
+	if err := r.db.storage.checkWritable(); err != nil {
+		return err
+	}
+	return r.db.inTx(func(tx *sql.Tx) error {
+		categories, err := queryCategories(tx)
+		if err != nil {
+			return err
+		}
+		if _, err := updateCategory(categories, cat); err != nil {
+			return err
+		}
+		return updateCategoryRow(tx, cat)
+	})
+}
+
+// MoveCategory moves a category under parentID ("" for the root) at position
+// among its new siblings (negative appends) and renumbers their sort order.
+// Returns ErrCategoryCycle if parentID lies in the category's own subtree.
+func (r *SQLiteTextRepository) MoveCategory(id, parentID string, position int) error {
+	if err := validateCategoryID(id); err != nil {
+		return err
+	}
+	if err := r.db.storage.checkWritable(); err != nil {
+		return err
+	}
+	return r.db.inTx(func(tx *sql.Tx) error {
+		categories, err := queryCategories(tx)
+		if err != nil {
+			return err
+		}
+		moved, err := moveCategory(categories, id, parentID, position)
+		if err != nil {
+			return err
+		}
+		for i := range moved {
+			if moved[i] == categories[i] {
+				continue
+			}
+			if err := updateCategoryRow(tx, &moved[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// DeleteCategory moves a category, its subcategories and all their texts to
+// the trash as one entry. Returns ErrCategoryNotFound if category doesn't exist.
+func (r *SQLiteTextRepository) DeleteCategory(id string) error {
+	if err := validateCategoryID(id); err != nil {
+		return err
+	}
+	if err := r.db.storage.checkWritable(); err != nil {
+		return err
+	}
+	r.search.mu.Lock()
+	defer r.search.mu.Unlock()
+	var subtree []domain.Category
+	var trashID string
+	err := r.db.inTx(func(tx *sql.Tx) error {
+		categories, err := queryCategories(tx)
+		if err != nil {
+			return err
+		}
+		if subtree = subtreeCategories(categories, id); subtree == nil {
+			return fmt.Errorf("%w: %s", ErrCategoryNotFound, id)
+		}
+		var texts []domain.Text
+		for _, c := range subtree {
+			inCategory, err := queryTexts(tx, "category_id = ?", c.ID)
+			if err != nil {
+				return err
+			}
+			texts = append(texts, inCategory...)
+		}
+		if trashID, err = r.db.storage.writeTrash(subtree[0].Name, subtree, texts); err != nil {
+			return err
+		}
+		return deleteCategoryRows(tx, subtree)
+	})
+	if err != nil {
+		if trashID != "" {
+			r.db.storage.discardTrash(trashID)
+		}
+		return err
+	}
+	for _, c := range subtree {
+		r.search.removeCategory(c.ID)
+	}
+	return nil
+}
+
+// Search returns the texts matching q, best match first. The index is built
+// from all content on the first call.
+func (r *SQLiteTextRepository) Search(q SearchQuery) (SearchResult, error) {
+	lib, err := r.Library()
+	if err != nil {
+		return SearchResult{}, err
+	}
+	r.search.mu.Lock()
+	defer r.search.mu.Unlock()
+	if err := r.search.ensure(r.allTexts); err != nil {
+		return SearchResult{}, err
+	}
+	return r.search.search(q, lib.Categories, func(id string) (string, error) {
+		var content string
+		err := r.db.db.QueryRow(`SELECT content FROM texts WHERE id = ?`, id).Scan(&content)
+		if err != nil {
+			return "", fmt.Errorf("storage: query text %q: %w", id, err)
+		}
+		return content, nil
+	})
+}
+
+// allTexts returns every text with content, for building the search index.
+func (r *SQLiteTextRepository) allTexts() ([]domain.Text, error) {
+	return queryTexts(r.db.db, "")
+}
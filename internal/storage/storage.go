@@ -11,11 +11,21 @@
 //	│   ├── index.json           # metadata: categories, text entries
 //	│   └── content/
 //	│       └── {id}.txt         # actual text content by ID
-//	├── sessions.json            # typing session history
-//	└── settings.json            # user preferences
+//	├── sessions.jsonl           # typing session journal (one session per line)
+//	├── sessions-archive/
+//	│   └── {year}.jsonl         # archived journal segments
+//	├── settings.json            # user preferences
+//	└── fingergo.lock            # advisory lock held by the running instance
 //
-// On first run, embedded defaults are copied to {root}/.
+// On first run, the embedded welcome pack (see pack.go) seeds the library.
 // Existing files are never overwritten (idempotent).
+//
+// All writes go through writeFile (temp file + fsync + rename), and the JSON
+// documents keep their previous generation as {name}.bak for crash recovery.
+// Each JSON document is wrapped in a {"schemaVersion", "data"} envelope and
+// upgraded by the migrations registry on Init.
+// Documents that fail to parse are quarantined as {name}.{timestamp}.corrupt,
+// then restored from .bak or rebuilt; see Manager.Warnings.
 package storage
 
 import (
@@ -25,24 +35,25 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+	"sync"
 )
 
 // Relative paths within the data directory.
 const (
 	textsDir            = "texts"
 	textsContentDir     = "texts/content"
+	textsRevisionsDir   = "texts/revisions" // {id}.json revision history per text
+	textsProgressDir    = "texts/progress"  // {id}.json progress cursor per chaptered text
 	textsIndexFile      = "texts/index.json"
 	fallbackContentFile = "texts/content/dfs-file-finder.txt"
-	sessionsFile        = "sessions.json"
+	sessionsFile        = "sessions.json" // legacy, converted to sessionsJournalFile
 )
 
-// Paths inside the embedded filesystem.
-const (
-	embeddedIndexPath   = "embedded/index.json"
-	embeddedDefaultPath = "embedded/default.txt"
-)
+// embeddedDefaultPath is the welcome text inside the embedded filesystem,
+// also used as fallback content for texts whose file is missing.
+const embeddedDefaultPath = welcomePackDir + "/content/quick-sort.txt"
 
-//go:embed embedded/index.json embedded/default.txt
+//go:embed embedded/welcome
 var embeddedFiles embed.FS
 
 // Sentinel errors for the storage package.
@@ -50,7 +61,11 @@ var errEmptyRoot = errors.New("storage: root path is empty")
 
 // Manager owns the on-disk data layout for FingerGo.
 type Manager struct {
-	root string // absolute path to data directory (e.g., ~/.local/share/fingergo)
+	lock     *os.File   // held advisory lock on {root}/fingergo.lock (nil until Init)
+	root     string     // absolute path to data directory (e.g., ~/.local/share/fingergo)
+	warnings []string   // recoverable load problems reported to the GUI
+	mu       sync.Mutex // guards lock and warnings
+	readOnly bool       // opened via NewReadOnly: no lock, no writes
 }
 
 // New creates a storage manager rooted at the provided path.
@@ -69,32 +84,49 @@ func (m *Manager) Root() string {
 // Init ensures the expected directory structure exists and seeds fallback data.
 // Safe to call multiple times — existing files are not overwritten.
 //
+// Init first takes an exclusive advisory lock on the root; if another process
+// holds it, ErrLocked is returned (see WaitInit and NewReadOnly for tools).
+// The lock is held until Close.
+//
 // Creates:
 //   - {root}/texts/
 //   - {root}/texts/content/
-//   - {root}/texts/index.json       (from embedded)
-//   - {root}/texts/content/{id}.txt (from embedded)
-//   - {root}/sessions.json          (empty array)
+//   - {root}/texts/index.json       (from the welcome pack)
+//   - {root}/texts/content/{id}.txt (from the welcome pack)
+//   - {root}/sessions.jsonl         (empty journal, or converted sessions.json)
+//
+// Finally runs pending schema migrations (see migrations.go).
 func (m *Manager) Init() error {
+	if err := m.checkWritable(); err != nil {
+		return err
+	}
 	if err := m.ensureDir(m.root); err != nil {
 		return err
 	}
+	if err := m.acquireLock(); err != nil {
+		return err
+	}
 	if err := m.ensureDir(m.join(textsDir)); err != nil {
 		return err
 	}
 	if err := m.ensureDir(m.join(textsContentDir)); err != nil {
 		return err
 	}
-	if err := m.ensureFile(textsIndexFile, embeddedIndexPath); err != nil {
+	for _, dir := range []string{".", textsDir, textsContentDir} {
+		if err := m.removeStaleTemps(dir); err != nil {
+			return err
+		}
+	}
+	if err := m.seedLibrary(); err != nil {
 		return err
 	}
 	if err := m.ensureFile(fallbackContentFile, embeddedDefaultPath); err != nil {
 		return err
 	}
-	if err := m.ensureJSONFile(sessionsFile, nil); err != nil {
+	if err := m.migrate(); err != nil {
 		return err
 	}
-	return nil
+	return m.migrateSessionsJournal()
 }
 
 // join constructs an absolute path by prepending the root directory.
@@ -114,7 +146,6 @@ func (m *Manager) ensureDir(path string) error {
 
 // ensureFile copies embedded content to target path if target doesn't exist.
 // Idempotent: skips if file already exists, never overwrites.
-// Permissions: 0o600 (rw-------) — owner-only access.
 func (m *Manager) ensureFile(relPath, embeddedPath string) error {
 	target := m.join(relPath)
 	if _, err := os.Stat(target); err == nil {
@@ -126,26 +157,30 @@ func (m *Manager) ensureFile(relPath, embeddedPath string) error {
 	if err != nil {
 		return fmt.Errorf("storage: read embedded %q: %w", embeddedPath, err)
 	}
-	if err := os.WriteFile(target, data, 0o600); err != nil {
-		return fmt.Errorf("storage: write %q: %w", target, err)
-	}
-	return nil
+	return m.writeFile(relPath, data)
 }
 
-func (m *Manager) ensureJSONFile(relPath string, defaultContent []byte) error {
-	target := m.join(relPath)
+// seedLibrary installs the embedded welcome pack as the library if index.json
+// doesn't exist: content files first, then the index in the current schema.
+func (m *Manager) seedLibrary() error {
+	target := m.join(textsIndexFile)
 	if _, err := os.Stat(target); err == nil {
 		return nil
 	} else if !errors.Is(err, os.ErrNotExist) {
 		return fmt.Errorf("storage: stat %q: %w", target, err)
 	}
-
-	content := defaultContent
-	if len(content) == 0 {
-		content = []byte("[]\n")
+	pack, err := welcomePack()
+	if err != nil {
+		return err
 	}
-	if err := os.WriteFile(target, content, 0o600); err != nil {
-		return fmt.Errorf("storage: write %q: %w", target, err)
+	for _, text := range pack.manifest.Texts {
+		if err := m.writeFile(contentPath(text.ID), []byte(pack.content[text.ID])); err != nil {
+			return err
+		}
 	}
-	return nil
+	data, err := encodeDocument(textsIndexFile, pack.library())
+	if err != nil {
+		return fmt.Errorf("storage: marshal index: %w", err)
+	}
+	return m.writeFile(textsIndexFile, data)
 }
@@ -10,6 +10,10 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"sync/atomic"
 
 	domain "github.com/AshBuk/FingerGo/internal/domain"
 )
@@ -33,13 +37,25 @@ const (
 //   - Content files loaded on demand and cached in memory
 //   - Writes use ordered persistence (content first, index second) with best-effort rollback
 //   - O(1) lookups via textIndex and sliceIndex maps
+//   - Files edited outside the app are picked up by Watch; mutations reload a
+//     changed index.json first, so in-app saves never clobber external edits
+//   - Search uses an inverted index built on first use and kept current by
+//     every mutation (see search.go)
+//   - Content is normalized (see normalize.go) before validation on every save
+//   - Safe for concurrent use: reads share mu, writes hold it exclusively;
+//     contentCache is also filled by readers, so they serialize on cacheMu
 type TextRepository struct {
-	contentCache map[string]string      // id → full text content
-	textIndex    map[string]domain.Text // id → metadata (O(1) lookup)
-	sliceIndex   map[string]int         // id → position in library.Texts slice
-	storage      *Manager               // underlying file manager
-	library      domain.TextLibrary     // categories + text metadata
-	loaded       bool                   // true after first load
+	contentCache map[string]string          // id → full text content
+	textIndex    map[string]domain.Text     // id → metadata (O(1) lookup)
+	sliceIndex   map[string]int             // id → position in library.Texts slice
+	storage      *Manager                   // underlying file manager
+	stamps       map[string]fileStamp       // relPath → state after our last read/write (see watch.go)
+	library      domain.TextLibrary         // categories + text metadata
+	search       searchIndex                // full-text index; its mu is taken after r.mu
+	normalizer   atomic.Pointer[Normalizer] // applied by SaveText and UpdateText; nil for defaults
+	mu           sync.RWMutex               // guards all fields below storage
+	cacheMu      sync.Mutex                 // guards contentCache under mu.RLock
+	loaded       bool                       // true after first load
 }
 
 // NewTextRepository wires repository to the storage manager.
@@ -52,15 +68,20 @@ func NewTextRepository(mgr *Manager) (*TextRepository, error) {
 		contentCache: make(map[string]string),
 		textIndex:    make(map[string]domain.Text),
 		sliceIndex:   make(map[string]int),
+		stamps:       make(map[string]fileStamp),
 	}, nil
 }
 
 // Library returns metadata for texts and categories (content stripped).
 func (r *TextRepository) Library() (domain.TextLibrary, error) {
-	if err := r.ensureLoaded(); err != nil {
+	if err := r.load(); err != nil {
 		return domain.TextLibrary{}, err
 	}
-	return cloneLibrary(r.library), nil
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	lib := cloneLibrary(r.library)
+	sortCategories(lib.Categories)
+	return lib, nil
 }
 
 // DefaultText resolves and returns the configured default text with content.
@@ -81,48 +102,245 @@ func (r *TextRepository) Text(id string) (domain.Text, error) {
 	if err := validateTextID(id); err != nil {
 		return domain.Text{}, fmt.Errorf("%w: %s", ErrTextNotFound, id)
 	}
-	if err := r.ensureLoaded(); err != nil {
+	if err := r.load(); err != nil {
 		return domain.Text{}, err
 	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
 	text, found := r.lookupText(id)
 	if !found {
 		return domain.Text{}, fmt.Errorf("%w: %s", ErrTextNotFound, id)
 	}
-	if content, ok := r.contentCache[id]; ok {
-		text.Content = content
-		return text, nil
-	}
-	content, err := r.loadContent(id)
+	content, err := r.cachedContent(id)
 	if err != nil {
 		return domain.Text{}, err
 	}
-	if len(r.contentCache) >= maxCachedTexts {
-		clear(r.contentCache)
-	}
-	r.contentCache[id] = content
 	text.Content = content
 	return text, nil
 }
 
+// Search returns the texts matching q, best match first. The index is built
+// from all content on the first call.
+func (r *TextRepository) Search(q SearchQuery) (SearchResult, error) {
+	if err := r.load(); err != nil {
+		return SearchResult{}, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	r.search.mu.Lock()
+	defer r.search.mu.Unlock()
+	err := r.search.ensure(func() ([]domain.Text, error) {
+		texts := make([]domain.Text, len(r.library.Texts))
+		for i, text := range r.library.Texts {
+			content, err := r.loadContent(text.ID)
+			if err != nil && !errors.Is(err, ErrContentUnavailable) {
+				return nil, err
+			}
+			text.Content = content // unavailable content leaves the title searchable
+			texts[i] = text
+		}
+		return texts, nil
+	})
+	if err != nil {
+		return SearchResult{}, err
+	}
+	return r.search.search(q, r.library.Categories, func(id string) (string, error) {
+		content, err := r.cachedContent(id)
+		if errors.Is(err, ErrContentUnavailable) {
+			return "", nil
+		}
+		return content, err
+	})
+}
+
+// Revisions returns the revision history of a text, newest first, without
+// content.
+func (r *TextRepository) Revisions(id string) ([]TextRevision, error) {
+	history, err := r.history(id)
+	if err != nil {
+		return nil, err
+	}
+	return revisionListing(history), nil
+}
+
+// Revision returns one revision of a text with its content.
+func (r *TextRepository) Revision(id string, revision int) (TextRevision, error) {
+	history, err := r.history(id)
+	if err != nil {
+		return TextRevision{}, err
+	}
+	return findRevision(history, id, revision)
+}
+
+// history returns the revisions of text id, oldest first, the current one
+// included.
+func (r *TextRepository) history(id string) ([]TextRevision, error) {
+	if err := validateTextID(id); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrTextNotFound, id)
+	}
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	text, found := r.lookupText(id)
+	if !found {
+		return nil, fmt.Errorf("%w: %s", ErrTextNotFound, id)
+	}
+	content, err := r.cachedContent(id)
+	if err != nil {
+		return nil, err
+	}
+	text.Content = content
+	history, err := r.readRevisions(id)
+	if err != nil {
+		return nil, err
+	}
+	return withCurrent(history, &text), nil
+}
+
+// Progress returns the progress cursor of a text; the zero TextProgress
+// before the first recorded session.
+func (r *TextRepository) Progress(id string) (TextProgress, error) {
+	if err := validateTextID(id); err != nil {
+		return TextProgress{}, fmt.Errorf("%w: %s", ErrTextNotFound, id)
+	}
+	if err := r.load(); err != nil {
+		return TextProgress{}, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if _, found := r.lookupText(id); !found {
+		return TextProgress{}, fmt.Errorf("%w: %s", ErrTextNotFound, id)
+	}
+	data, err := os.ReadFile(r.storage.join(progressPath(id)))
+	if errors.Is(err, os.ErrNotExist) {
+		return TextProgress{}, nil
+	}
+	if err != nil {
+		return TextProgress{}, fmt.Errorf("storage: read progress of %q: %w", id, err)
+	}
+	var progress TextProgress
+	if err := json.Unmarshal(data, &progress); err != nil {
+		// Like the revision history, a damaged cursor only restarts the text
+		log.Printf("WARNING: discarding unreadable progress of %q: %v", id, err)
+		return TextProgress{}, nil
+	}
+	return progress, nil
+}
+
+// SaveProgress replaces the progress cursor of a text.
+func (r *TextRepository) SaveProgress(id string, progress TextProgress) error {
+	if err := validateTextID(id); err != nil {
+		return err
+	}
+	data, err := json.Marshal(progress)
+	if err != nil {
+		return fmt.Errorf("storage: marshal progress of %q: %w", id, err)
+	}
+	if err := r.storage.checkWritable(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.ensureLoaded(); err != nil {
+		return err
+	}
+	if _, exists := r.textIndex[id]; !exists {
+		return fmt.Errorf("%w: %s", ErrTextNotFound, id)
+	}
+	if err := r.storage.ensureDir(r.storage.join(textsProgressDir)); err != nil {
+		return err
+	}
+	return r.storage.writeFile(progressPath(id), data)
+}
+
+// Normalizer returns the content normalization applied by SaveText and
+// UpdateText.
+func (r *TextRepository) Normalizer() *Normalizer {
+	return r.normalizer.Load()
+}
+
+// SetNormalizer replaces the content normalization; nil applies the
+// per-language defaults.
+func (r *TextRepository) SetNormalizer(n *Normalizer) {
+	r.normalizer.Store(n)
+}
+
+// RefreshMetrics recomputes the difficulty metrics of texts analyzed on
+// another layout or by older formulas and returns how many it updated.
+func (r *TextRepository) RefreshMetrics() (int, error) {
+	layout := r.Normalizer().Layout()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.ensureLoaded(); err != nil {
+		return 0, err
+	}
+	stale, metrics, err := staleMetrics(r.library.Texts, layout, func(i int) (string, error) {
+		return r.cachedContent(r.library.Texts[i].ID)
+	})
+	if err != nil || len(stale) == 0 {
+		return 0, err
+	}
+	old := make([]*domain.TextMetrics, len(stale))
+	for j, i := range stale {
+		old[j] = r.library.Texts[i].Metrics
+		r.setMetrics(i, &metrics[j])
+	}
+	if err := r.persistIndex(); err != nil {
+		for j, i := range stale {
+			r.setMetrics(i, old[j])
+		}
+		return 0, err
+	}
+	return len(stale), nil
+}
+
+// setMetrics replaces the metrics of r.library.Texts[i]. Caller must hold r.mu.
+func (r *TextRepository) setMetrics(i int, m *domain.TextMetrics) {
+	entry := &r.library.Texts[i]
+	entry.Metrics = m
+	r.textIndex[entry.ID] = *entry
+}
+
 // SaveText creates a new text entry with content.
 // Returns ErrTextExists if a text with the same ID already exists.
 func (r *TextRepository) SaveText(text *domain.Text) error {
-	if text == nil || text.ID == "" {
-		return ErrEmptyTextID
+	_, err := r.SaveTextWithReport(text)
+	return err
+}
+
+// SaveTextWithReport is SaveText, also reporting what normalization changed
+// in the content and which characters the keyboard layout cannot type.
+func (r *TextRepository) SaveTextWithReport(text *domain.Text) (NormalizeReport, error) {
+	report, err := prepareText(r.Normalizer(), text)
+	if err != nil {
+		return NormalizeReport{}, err
 	}
-	if err := validateText(text); err != nil {
-		return err
+	if err := r.saveText(text); err != nil {
+		return NormalizeReport{}, err
 	}
+	return report, nil
+}
+
+// saveText stores a text prepared by SaveTextWithReport.
+func (r *TextRepository) saveText(text *domain.Text) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	if err := r.ensureLoaded(); err != nil {
 		return err
 	}
 	if _, exists := r.textIndex[text.ID]; exists {
 		return fmt.Errorf("%w: %s", ErrTextExists, text.ID)
 	}
+	if err := r.writeRevisions(text.ID, []TextRevision{firstRevision(text)}); err != nil {
+		return err
+	}
 	content := text.Content
 	entry := *text
 	entry.Content = ""
 	if err := r.persistContent(entry.ID, content); err != nil {
+		r.discardRevisions(entry.ID)
 		return err
 	}
 	idx := len(r.library.Texts)
@@ -139,19 +357,38 @@ func (r *TextRepository) SaveText(text *domain.Text) error {
 		if delErr := r.deleteContent(entry.ID); delErr != nil {
 			log.Printf("WARNING: rollback failed to delete content for %q: %v", entry.ID, delErr)
 		}
+		r.discardRevisions(entry.ID)
 		return err
 	}
+	r.search.mu.Lock()
+	r.search.put(text)
+	r.search.mu.Unlock()
 	return nil
 }
 
 // UpdateText modifies an existing text entry.
 func (r *TextRepository) UpdateText(text *domain.Text) error {
-	if text == nil || text.ID == "" {
-		return ErrEmptyTextID
+	_, err := r.UpdateTextWithReport(text)
+	return err
+}
+
+// UpdateTextWithReport is UpdateText, reporting normalization like
+// SaveTextWithReport.
+func (r *TextRepository) UpdateTextWithReport(text *domain.Text) (NormalizeReport, error) {
+	report, err := prepareText(r.Normalizer(), text)
+	if err != nil {
+		return NormalizeReport{}, err
 	}
-	if err := validateText(text); err != nil {
-		return err
+	if err := r.updateText(text); err != nil {
+		return NormalizeReport{}, err
 	}
+	return report, nil
+}
+
+// updateText stores a text prepared by UpdateTextWithReport.
+func (r *TextRepository) updateText(text *domain.Text) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	if err := r.ensureLoaded(); err != nil {
 		return err
 	}
@@ -159,13 +396,21 @@ func (r *TextRepository) UpdateText(text *domain.Text) error {
 	if !exists {
 		return fmt.Errorf("%w: %s", ErrTextNotFound, text.ID)
 	}
-	content := text.Content
-	entry := *text
-	entry.Content = ""
-	prevContent, hadFile, err := r.getPrevContent(entry.ID)
+	prevContent, hadFile, err := r.getPrevContent(text.ID)
 	if err != nil {
 		return err
 	}
+	// The history is written first: a failure after it only leaves an entry
+	// past the current revision, which the next update overwrites
+	prev := r.textIndex[text.ID]
+	prev.Content = prevContent
+	keepSegmentation(&prev, text)
+	if err := r.recordRevision(&prev, text); err != nil {
+		return err
+	}
+	content := text.Content
+	entry := *text
+	entry.Content = ""
 	if err := r.persistContent(entry.ID, content); err != nil {
 		return err
 	}
@@ -194,15 +439,34 @@ func (r *TextRepository) UpdateText(text *domain.Text) error {
 		}
 		return err
 	}
+	r.search.mu.Lock()
+	r.search.put(text)
+	r.search.mu.Unlock()
 	return nil
 }
 
+// recordRevision adds text, an update of prev, to the revision history when
+// it changes the content. Caller must hold r.mu.
+func (r *TextRepository) recordRevision(prev, text *domain.Text) error {
+	replaced, added := nextRevision(prev, text)
+	if added == nil {
+		return nil
+	}
+	history, err := r.readRevisions(text.ID)
+	if err != nil {
+		return err
+	}
+	return r.writeRevisions(text.ID, appendRevision(history, replaced, *added))
+}
+
 // SaveCategory creates a new category entry.
 // Returns ErrCategoryExists if a category with the same ID or name already exists.
 func (r *TextRepository) SaveCategory(cat *domain.Category) error {
 	if err := validateCategory(cat); err != nil {
 		return err
 	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	if err := r.ensureLoaded(); err != nil {
 		return err
 	}
@@ -222,64 +486,248 @@ func (r *TextRepository) SaveCategory(cat *domain.Category) error {
 	return nil
 }
 
-// DeleteCategory removes a category entry by ID and all texts belonging to it.
-// Returns ErrCategoryNotFound if category doesn't exist.
+// UpdateCategory replaces the name, icon, parent and sort order of an
+// existing category. Returns ErrCategoryExists if the name is taken by another
+// category and ErrCategoryCycle if the parent lies in its own subtree.
+func (r *TextRepository) UpdateCategory(cat *domain.Category) error {
+	if err := validateCategory(cat); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.ensureLoaded(); err != nil {
+		return err
+	}
+	updated, err := updateCategory(r.library.Categories, cat)
+	if err != nil {
+		return err
+	}
+	return r.replaceCategories(updated)
+}
+
+// MoveCategory moves a category under parentID ("" for the root) at position
+// among its new siblings (negative appends) and renumbers their sort order.
+// Returns ErrCategoryCycle if parentID lies in the category's own subtree.
+func (r *TextRepository) MoveCategory(id, parentID string, position int) error {
+	if err := validateCategoryID(id); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.ensureLoaded(); err != nil {
+		return err
+	}
+	moved, err := moveCategory(r.library.Categories, id, parentID, position)
+	if err != nil {
+		return err
+	}
+	return r.replaceCategories(moved)
+}
+
+// replaceCategories installs categories and persists the index, restoring
+// the previous list if the write fails. Caller must hold r.mu.
+func (r *TextRepository) replaceCategories(categories []domain.Category) error {
+	old := r.library.Categories
+	r.library.Categories = categories
+	if err := r.persistIndex(); err != nil {
+		r.library.Categories = old
+		return err
+	}
+	return nil
+}
+
+// DeleteCategory moves a category, its subcategories and all their texts to
+// the trash as one entry. Returns ErrCategoryNotFound if category doesn't exist.
 func (r *TextRepository) DeleteCategory(id string) error {
 	// Validate ID for security (prevent path traversal)
 	if err := validateCategoryID(id); err != nil {
 		return err
 	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	if err := r.ensureLoaded(); err != nil {
 		return err
 	}
-	idx := -1
-	for i, c := range r.library.Categories {
-		if c.ID == id {
-			idx = i
-			break
-		}
-	}
-	if idx == -1 {
+	subtree := subtreeCategories(r.library.Categories, id)
+	if subtree == nil {
 		return fmt.Errorf("%w: %s", ErrCategoryNotFound, id)
 	}
-	// Collect IDs first — DeleteText mutates library.Texts, unsafe to delete during range
-	var textsToDelete []string
-	for _, text := range r.library.Texts {
-		if text.CategoryID == id {
-			textsToDelete = append(textsToDelete, text.ID)
-		}
-	}
-	for _, textID := range textsToDelete {
-		if err := r.DeleteText(textID); err != nil {
-			log.Printf("WARNING: failed to delete text %q during category deletion: %v", textID, err)
-		}
+	inSubtree := make(map[string]bool, len(subtree))
+	for _, c := range subtree {
+		inSubtree[c.ID] = true
 	}
-	// Refresh idx as slice may have changed after text deletions
-	idx = -1
-	for i, c := range r.library.Categories {
-		if c.ID == id {
-			idx = i
-			break
+	old := r.library
+	next := old
+	next.Categories = slices.DeleteFunc(slices.Clone(old.Categories), func(c domain.Category) bool { return inSubtree[c.ID] })
+	next.Texts = nil
+	var removed []string
+	for _, text := range old.Texts {
+		if inSubtree[text.CategoryID] {
+			removed = append(removed, text.ID)
+		} else {
+			next.Texts = append(next.Texts, text)
 		}
 	}
-	if idx == -1 {
-		return fmt.Errorf("%w: %s", ErrCategoryNotFound, id)
+	trashID, err := r.trash(subtree[0].Name, subtree, removed)
+	if err != nil {
+		return err
 	}
-	oldCat := r.library.Categories[idx]
-	r.library.Categories = append(r.library.Categories[:idx], r.library.Categories[idx+1:]...)
+	r.setLibrary(next)
 	if err := r.persistIndex(); err != nil {
-		r.library.Categories = append(r.library.Categories[:idx], append([]domain.Category{oldCat}, r.library.Categories[idx:]...)...)
+		r.setLibrary(old)
+		r.storage.discardTrash(trashID)
 		return err
 	}
+	// The index no longer references the texts; leftover files are harmless
+	for _, textID := range removed {
+		delete(r.contentCache, textID)
+		if err := r.deleteContent(textID); err != nil {
+			log.Printf("WARNING: failed to delete content for %q during category deletion: %v", textID, err)
+		}
+		r.discardRevisions(textID)
+		r.discardProgress(textID)
+	}
 	return nil
 }
 
-// DeleteText removes a text entry by ID.
+// DeleteText moves a text to the trash.
 func (r *TextRepository) DeleteText(id string) error {
 	// Validate ID for security (prevent path traversal)
 	if err := validateTextID(id); err != nil {
 		return err
 	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.ensureLoaded(); err != nil {
+		return err
+	}
+	text, found := r.lookupText(id)
+	if !found {
+		return fmt.Errorf("%w: %s", ErrTextNotFound, id)
+	}
+	trashID, err := r.trash(text.Title, nil, []string{id})
+	if err != nil {
+		return err
+	}
+	if err := r.deleteText(id); err != nil {
+		r.storage.discardTrash(trashID)
+		return err
+	}
+	return nil
+}
+
+// ApplyBulk applies change to every text in ids and persists the index
+// once. Unknown IDs are reported in their result and skipped; if the index
+// write fails, no text is changed.
+func (r *TextRepository) ApplyBulk(ids []string, change BulkChange) ([]BulkResult, error) {
+	if err := change.validate(); err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.ensureLoaded(); err != nil {
+		return nil, err
+	}
+	if id := change.CategoryID; id != nil && *id != "" &&
+		!slices.ContainsFunc(r.library.Categories, func(c domain.Category) bool { return c.ID == *id }) {
+		return nil, fmt.Errorf("%w: %s", ErrCategoryNotFound, *id)
+	}
+	results, targets, _ := bulkTargets(ids, func(id string) (bool, error) {
+		_, ok := r.textIndex[id]
+		return ok, nil
+	})
+	if len(targets) == 0 {
+		return results, nil
+	}
+	var err error
+	if change.Delete {
+		err = r.bulkDelete(targets)
+	} else {
+		err = r.bulkUpdate(targets, &change)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// bulkUpdate applies change to the texts in ids and persists the index,
+// restoring the previous library if that fails. Caller must hold r.mu.
+func (r *TextRepository) bulkUpdate(ids []string, change *BulkChange) error {
+	selected := idSet(ids)
+	old := r.library
+	next := old
+	next.Texts = slices.Clone(old.Texts)
+	for i := range next.Texts {
+		if selected[next.Texts[i].ID] {
+			change.apply(&next.Texts[i])
+		}
+	}
+	r.setLibrary(next)
+	if err := r.persistIndex(); err != nil {
+		r.setLibrary(old)
+		return err
+	}
+	return nil
+}
+
+// bulkDelete moves the texts in ids to a new trash entry, removes them from
+// the index and then deletes their files. If the index write fails, the
+// library and the trash are left as before. Caller must hold r.mu.
+func (r *TextRepository) bulkDelete(ids []string) error {
+	selected := idSet(ids)
+	old := r.library
+	next := old
+	next.Texts = make([]domain.Text, 0, len(old.Texts))
+	var removed []domain.Text
+	for _, text := range old.Texts {
+		if selected[text.ID] {
+			removed = append(removed, text)
+		} else {
+			next.Texts = append(next.Texts, text)
+		}
+	}
+	trashID, err := r.trash(bulkTrashName(removed), nil, ids)
+	if err != nil {
+		return err
+	}
+	r.setLibrary(next)
+	if err := r.persistIndex(); err != nil {
+		r.setLibrary(old)
+		r.storage.discardTrash(trashID)
+		return err
+	}
+	for _, text := range removed {
+		delete(r.contentCache, text.ID)
+		if err := r.deleteContent(text.ID); err != nil {
+			log.Printf("WARNING: failed to delete content for %q during bulk deletion: %v", text.ID, err)
+		}
+		r.discardRevisions(text.ID)
+		r.discardProgress(text.ID)
+	}
+	return nil
+}
+
+// trash writes categories and the texts with the given IDs (with content) to
+// a new trash entry. Texts whose content cannot be read are left out.
+// Caller must hold r.mu.
+func (r *TextRepository) trash(name string, categories []domain.Category, ids []string) (string, error) {
+	texts := make([]domain.Text, 0, len(ids))
+	for _, id := range ids {
+		text := r.textIndex[id]
+		content, err := r.cachedContent(id)
+		if err != nil {
+			log.Printf("WARNING: text %q is deleted without a trash copy: %v", id, err)
+			continue
+		}
+		text.Content = content
+		texts = append(texts, text)
+	}
+	return r.storage.writeTrash(name, categories, texts)
+}
+
+// deleteText removes a text entry by ID. Caller must hold r.mu.
+func (r *TextRepository) deleteText(id string) error {
 	if err := r.ensureLoaded(); err != nil {
 		return err
 	}
@@ -319,28 +767,33 @@ func (r *TextRepository) DeleteText(id string) error {
 		}
 		return err
 	}
+	r.discardRevisions(id)
+	r.discardProgress(id)
+	r.search.mu.Lock()
+	r.search.remove(id)
+	r.search.mu.Unlock()
 	return nil
 }
 
 // persistIndex writes the current library metadata to disk.
 func (r *TextRepository) persistIndex() error {
-	indexPath := r.storage.join(textsIndexFile)
-	data, err := json.MarshalIndent(r.library, "", "  ")
+	data, err := encodeDocument(textsIndexFile, r.library)
 	if err != nil {
 		return fmt.Errorf("storage: marshal index: %w", err)
 	}
-	if err := os.WriteFile(indexPath, data, 0o600); err != nil {
-		return fmt.Errorf("storage: write index %q: %w", indexPath, err)
+	if err := r.storage.writeFileBackup(textsIndexFile, data); err != nil {
+		return err
 	}
+	r.recordStamp(textsIndexFile)
 	return nil
 }
 
 // persistContent writes text content to a separate file.
 func (r *TextRepository) persistContent(id, content string) error {
-	contentPath := r.storage.join(textsContentDir, fmt.Sprintf("%s.txt", id))
-	if err := os.WriteFile(contentPath, []byte(content), 0o600); err != nil {
-		return fmt.Errorf("storage: write content %q: %w", contentPath, err)
+	if err := r.storage.writeFile(contentPath(id), []byte(content)); err != nil {
+		return err
 	}
+	r.recordStamp(contentPath(id))
 	return nil
 }
 
@@ -362,21 +815,25 @@ func (r *TextRepository) rebuildSliceIndex() {
 
 // deleteContent removes the content file for a text.
 func (r *TextRepository) deleteContent(id string) error {
-	contentPath := r.storage.join(textsContentDir, fmt.Sprintf("%s.txt", id))
-	if err := os.Remove(contentPath); err != nil && !errors.Is(err, os.ErrNotExist) {
-		return fmt.Errorf("storage: delete content %q: %w", contentPath, err)
+	if err := r.storage.checkWritable(); err != nil {
+		return err
 	}
+	path := r.storage.join(contentPath(id))
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("storage: delete content %q: %w", path, err)
+	}
+	r.recordStamp(contentPath(id))
 	return nil
 }
 
 func (r *TextRepository) readContent(id string) (content string, exists bool, err error) {
-	contentPath := r.storage.join(textsContentDir, fmt.Sprintf("%s.txt", id))
-	data, readErr := os.ReadFile(contentPath)
+	path := r.storage.join(contentPath(id))
+	data, readErr := os.ReadFile(path)
 	if readErr != nil {
 		if errors.Is(readErr, os.ErrNotExist) {
 			return "", false, nil
 		}
-		err = fmt.Errorf("storage: read content %q: %w", contentPath, readErr)
+		err = fmt.Errorf("storage: read content %q: %w", path, readErr)
 		return "", false, err
 	}
 	content = string(data)
@@ -384,39 +841,74 @@ func (r *TextRepository) readContent(id string) (content string, exists bool, er
 	return
 }
 
+// load runs ensureLoaded under the write lock unless the index is already loaded.
+// Read paths call it before taking r.mu.RLock.
+func (r *TextRepository) load() error {
+	r.mu.RLock()
+	loaded := r.loaded
+	r.mu.RUnlock()
+	if loaded {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.ensureLoaded()
+}
+
+// ensureLoaded reads index.json on first use and re-reads it when it was
+// changed outside the app since. Caller must hold r.mu.
 func (r *TextRepository) ensureLoaded() error {
 	if r.loaded {
-		return nil
+		return r.refreshIndex()
+	}
+	var library domain.TextLibrary
+	err := r.storage.readFileFallback(textsIndexFile, func(data []byte) error {
+		library = domain.TextLibrary{}
+		return decodeDocument(textsIndexFile, data, &library)
+	})
+	rebuilt := false
+	if errors.Is(err, errCorruptFile) {
+		library, err = r.storage.rebuildLibrary()
+		if err != nil {
+			return fmt.Errorf("storage: rebuild index: %w", err)
+		}
+		rebuilt = true
 	}
-	indexPath := r.storage.join(textsIndexFile)
-	data, err := os.ReadFile(indexPath)
 	if err != nil {
-		return fmt.Errorf("storage: read index %q: %w", indexPath, err)
+		return fmt.Errorf("storage: load index: %w", err)
 	}
-	var library domain.TextLibrary
-	if err := json.Unmarshal(data, &library); err != nil {
-		return fmt.Errorf("storage: parse index %q: %w", indexPath, err)
+	r.setLibrary(library)
+	r.loaded = true
+	r.recordStamp(textsIndexFile)
+	if rebuilt {
+		if err := r.persistIndex(); err != nil {
+			return err
+		}
+		r.storage.warnf("text library index was corrupt and has been rebuilt with %d texts; categories were reset", len(library.Texts))
 	}
+	return nil
+}
+
+// setLibrary installs library (content stripped) and rebuilds the lookup maps.
+// Caller must hold r.mu.
+func (r *TextRepository) setLibrary(library domain.TextLibrary) {
 	for i := range library.Texts {
 		library.Texts[i].Content = ""
 	}
 	r.library = library
-	r.loaded = true
-	// Initialize lookup maps
 	if r.contentCache == nil {
 		r.contentCache = make(map[string]string)
 	}
-	if r.textIndex == nil {
-		r.textIndex = make(map[string]domain.Text, len(library.Texts))
-	}
-	if r.sliceIndex == nil {
-		r.sliceIndex = make(map[string]int, len(library.Texts))
+	if r.stamps == nil {
+		r.stamps = make(map[string]fileStamp)
 	}
+	r.textIndex = make(map[string]domain.Text, len(library.Texts))
+	r.sliceIndex = make(map[string]int, len(library.Texts))
 	for i, text := range library.Texts {
 		r.textIndex[text.ID] = text
 		r.sliceIndex[text.ID] = i
 	}
-	return nil
+	r.search.reset()
 }
 
 func (r *TextRepository) lookupText(id string) (domain.Text, bool) {
@@ -424,6 +916,28 @@ func (r *TextRepository) lookupText(id string) (domain.Text, bool) {
 	return text, ok
 }
 
+// cachedContent returns the content of a text through contentCache.
+// Caller must hold r.mu (a read lock suffices).
+func (r *TextRepository) cachedContent(id string) (string, error) {
+	r.cacheMu.Lock()
+	content, ok := r.contentCache[id]
+	r.cacheMu.Unlock()
+	if ok {
+		return content, nil
+	}
+	content, err := r.loadContent(id)
+	if err != nil {
+		return "", err
+	}
+	r.cacheMu.Lock()
+	if len(r.contentCache) >= maxCachedTexts {
+		clear(r.contentCache)
+	}
+	r.contentCache[id] = content
+	r.cacheMu.Unlock()
+	return content, nil
+}
+
 func (r *TextRepository) loadContent(id string) (string, error) {
 	if content, ok, err := r.readContent(id); err != nil {
 		return "", err
@@ -438,6 +952,68 @@ func (r *TextRepository) loadContent(id string) (string, error) {
 	return string(data), nil
 }
 
+// readRevisions returns the recorded history of a text, oldest first.
+func (r *TextRepository) readRevisions(id string) ([]TextRevision, error) {
+	data, err := os.ReadFile(r.storage.join(revisionsPath(id)))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("storage: read revisions of %q: %w", id, err)
+	}
+	var history []TextRevision
+	if err := json.Unmarshal(data, &history); err != nil {
+		// History is an extra: a damaged file must not block editing
+		log.Printf("WARNING: discarding unreadable revisions of %q: %v", id, err)
+		return nil, nil
+	}
+	return history, nil
+}
+
+// writeRevisions replaces the recorded history of a text.
+func (r *TextRepository) writeRevisions(id string, history []TextRevision) error {
+	data, err := json.Marshal(history)
+	if err != nil {
+		return fmt.Errorf("storage: marshal revisions of %q: %w", id, err)
+	}
+	if err := r.storage.checkWritable(); err != nil {
+		return err
+	}
+	if err := r.storage.ensureDir(r.storage.join(textsRevisionsDir)); err != nil {
+		return err
+	}
+	return r.storage.writeFile(revisionsPath(id), data)
+}
+
+// discardRevisions removes the history of a text that no longer exists.
+func (r *TextRepository) discardRevisions(id string) {
+	if err := os.Remove(r.storage.join(revisionsPath(id))); err != nil && !errors.Is(err, os.ErrNotExist) {
+		log.Printf("WARNING: failed to delete revisions of %q: %v", id, err)
+	}
+}
+
+// discardProgress removes the progress cursor of a text that no longer exists.
+func (r *TextRepository) discardProgress(id string) {
+	if err := os.Remove(r.storage.join(progressPath(id))); err != nil && !errors.Is(err, os.ErrNotExist) {
+		log.Printf("WARNING: failed to delete progress of %q: %v", id, err)
+	}
+}
+
+// contentPath returns the relative path of the content file for a text ID.
+func contentPath(id string) string {
+	return filepath.Join(textsContentDir, id+".txt")
+}
+
+// revisionsPath returns the relative path of the revision history of a text ID.
+func revisionsPath(id string) string {
+	return filepath.Join(textsRevisionsDir, id+".json")
+}
+
+// progressPath returns the relative path of the progress cursor of a text ID.
+func progressPath(id string) string {
+	return filepath.Join(textsProgressDir, id+".json")
+}
+
 func cloneLibrary(src domain.TextLibrary) domain.TextLibrary {
 	out := src
 	if len(src.Categories) > 0 {
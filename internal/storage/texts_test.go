@@ -8,6 +8,8 @@ import (
 	"errors"
 	"os"
 	"path/filepath"
+	"strconv"
+	"sync"
 	"testing"
 	"time"
 
@@ -481,3 +483,59 @@ func TestTextRepository_ContentFile(t *testing.T) {
 		}
 	})
 }
+
+func TestTextRepository_ConcurrentAccess(t *testing.T) {
+	repo := setupTextRepository(t)
+	cat := &domain.Category{ID: "busy", Name: "Busy"}
+	if err := repo.SaveCategory(cat); err != nil {
+		t.Fatalf("SaveCategory() error: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for w := range 6 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 15 {
+				id := "c-" + strconv.Itoa(w) + "-" + strconv.Itoa(i)
+				text := &domain.Text{ID: id, Title: id, Content: "x", Language: "text", CategoryID: "busy"}
+				if err := repo.SaveText(text); err != nil {
+					t.Errorf("SaveText() error: %v", err)
+					return
+				}
+				text.Content = "updated"
+				if err := repo.UpdateText(text); err != nil {
+					t.Errorf("UpdateText() error: %v", err)
+				}
+				if _, err := repo.Text(id); err != nil {
+					t.Errorf("Text() error: %v", err)
+				}
+				_, _ = repo.DefaultText()
+			}
+		}()
+	}
+	// Concurrent cascade delete must not corrupt the index for the writers above
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		_ = repo.SaveCategory(&domain.Category{ID: "doomed", Name: "Doomed"})
+		_ = repo.DeleteCategory("doomed")
+	}()
+	wg.Wait()
+
+	lib, err := repo.Library()
+	if err != nil {
+		t.Fatalf("Library() error: %v", err)
+	}
+	seen := make(map[string]bool)
+	for _, text := range lib.Texts {
+		if seen[text.ID] {
+			t.Errorf("duplicate entry %q in index", text.ID)
+		}
+		seen[text.ID] = true
+		got, err := repo.Text(text.ID)
+		if err != nil || got.ID != text.ID {
+			t.Errorf("Text(%q) = %q, %v; index out of sync", text.ID, got.ID, err)
+		}
+	}
+}
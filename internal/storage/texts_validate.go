@@ -35,6 +35,7 @@ var (
 	ErrInvalidCategoryID   = errors.New("storage: category id contains invalid characters")
 	ErrEmptyCategoryName   = errors.New("storage: category name is empty")
 	ErrCategoryNameTooLong = errors.New("storage: category name too long")
+	ErrCategoryCycle       = errors.New("storage: category cannot be nested under itself")
 )
 
 // Validation limits.
@@ -45,6 +46,12 @@ const (
 	defaultLanguage  = "text"    // Default language for plain text
 )
 
+// Limits exported for importers that check input before calling SaveText.
+const (
+	MaxTitleLength   = maxTitleLength
+	MaxContentLength = maxContentLength
+)
+
 // validIDPattern defines allowed characters in IDs: alphanumeric, hyphens, underscores.
 // This prevents path traversal attacks (../, ..\, etc.) and ensures filesystem safety.
 var validIDPattern = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
@@ -106,7 +113,7 @@ func validateText(text *domain.Text) error {
 	if !domain.IsValidLanguage(text.Language) {
 		return fmt.Errorf("%w: %s", ErrInvalidLanguage, text.Language)
 	}
-	return nil
+	return validateSegmentation(text)
 }
 
 // validateCategory checks category field constraints.
@@ -0,0 +1,27 @@
+// Copyright 2025 Asher Buk
+// SPDX-License-Identifier: Apache-2.0
+// https://github.com/AshBuk/FingerGo
+
+package storage
+
+import (
+	domain "github.com/AshBuk/FingerGo/internal/domain"
+)
+
+// Helpers shared by the TextStore backends (TextRepository and
+// SQLiteTextRepository), so both treat texts the same way.
+
+// prepareText readies a text for SaveText or UpdateText: it normalizes the
+// content with n, validates the result and caches its difficulty metrics.
+// The report tells what the normalization did.
+func prepareText(n *Normalizer, text *domain.Text) (NormalizeReport, error) {
+	if text == nil || text.ID == "" {
+		return NormalizeReport{}, ErrEmptyTextID
+	}
+	report := n.Normalize(text)
+	if err := validateText(text); err != nil {
+		return NormalizeReport{}, err
+	}
+	analyzeText(n, text)
+	return report, nil
+}
@@ -0,0 +1,26 @@
+// Copyright 2025 Asher Buk
+// SPDX-License-Identifier: Apache-2.0
+// https://github.com/AshBuk/FingerGo
+
+package storage
+
+import (
+	"testing"
+
+	domain "github.com/AshBuk/FingerGo/internal/domain"
+)
+
+// fillLibrary saves categories (parents first) and then texts to s.
+func fillLibrary(t *testing.T, s TextStore, categories []domain.Category, texts []domain.Text) {
+	t.Helper()
+	for _, c := range categories {
+		if err := s.SaveCategory(&c); err != nil {
+			t.Fatalf("SaveCategory(%s) error: %v", c.ID, err)
+		}
+	}
+	for _, text := range texts {
+		if err := s.SaveText(&text); err != nil {
+			t.Fatalf("SaveText(%s) error: %v", text.ID, err)
+		}
+	}
+}
@@ -0,0 +1,321 @@
+// Copyright 2025 Asher Buk
+// SPDX-License-Identifier: Apache-2.0
+// https://github.com/AshBuk/FingerGo
+
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+
+	domain "github.com/AshBuk/FingerGo/internal/domain"
+)
+
+// Trash layout: one directory per deletion, shared by both backends.
+//
+//	{root}/trash/{entryID}/entry.json         # TrashEntry
+//	{root}/trash/{entryID}/content/{id}.txt   # content of each deleted text
+//
+// entry.json is written last, so a directory without it is an interrupted
+// deletion: ListTrash ignores it and PurgeTrash removes it.
+const (
+	trashDir        = "trash"
+	trashEntryFile  = "entry.json"
+	trashContentDir = "content"
+)
+
+// ErrTrashEntryNotFound is returned for unknown trash entry IDs.
+var ErrTrashEntryNotFound = errors.New("storage: trash entry not found")
+
+// TrashEntry is one deletion: a single text, or a category with its
+// subcategories and all their texts.
+type TrashEntry struct {
+	DeletedAt  time.Time         `json:"deletedAt"`
+	ID         string            `json:"id"`
+	Name       string            `json:"name"`                 // title of the text or name of the category
+	Categories []domain.Category `json:"categories,omitempty"` // parents first; ParentID as before deletion
+	Texts      []domain.Text     `json:"texts,omitempty"`      // metadata; content in content/{id}.txt
+}
+
+// writeTrash stores categories and texts (with content) as a new trash entry
+// and returns its ID. Repositories call it before deleting anything, and
+// discardTrash if the deletion then fails.
+func (m *Manager) writeTrash(name string, categories []domain.Category, texts []domain.Text) (string, error) {
+	if err := m.checkWritable(); err != nil {
+		return "", err
+	}
+	entry := TrashEntry{
+		DeletedAt:  time.Now().UTC(),
+		ID:         uuid.NewString(),
+		Name:       name,
+		Categories: categories,
+		Texts:      make([]domain.Text, len(texts)),
+	}
+	dir := filepath.Join(trashDir, entry.ID)
+	if err := m.ensureDir(m.join(dir, trashContentDir)); err != nil {
+		return "", err
+	}
+	for i, text := range texts {
+		if err := m.writeFile(trashContentPath(entry.ID, text.ID), []byte(text.Content)); err != nil {
+			m.discardTrash(entry.ID)
+			return "", err
+		}
+		text.Content = ""
+		entry.Texts[i] = text
+	}
+	data, err := json.MarshalIndent(&entry, "", "  ")
+	if err == nil {
+		err = m.writeFile(filepath.Join(dir, trashEntryFile), data)
+	}
+	if err != nil {
+		m.discardTrash(entry.ID)
+		return "", fmt.Errorf("storage: write trash entry: %w", err)
+	}
+	return entry.ID, nil
+}
+
+// discardTrash removes a trash entry whose deletion did not happen.
+func (m *Manager) discardTrash(id string) {
+	if err := os.RemoveAll(m.join(trashDir, id)); err != nil {
+		log.Printf("WARNING: failed to discard trash entry %q: %v", id, err)
+	}
+}
+
+// ListTrash returns the trash entries, most recently deleted first.
+func (m *Manager) ListTrash() ([]TrashEntry, error) {
+	dirs, err := os.ReadDir(m.join(trashDir))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("storage: read trash: %w", err)
+	}
+	var entries []TrashEntry
+	for _, d := range dirs {
+		if !d.IsDir() {
+			continue
+		}
+		entry, err := m.readTrashEntry(d.Name())
+		if errors.Is(err, ErrTrashEntryNotFound) {
+			continue // interrupted deletion
+		}
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	slices.SortFunc(entries, func(a, b TrashEntry) int { return b.DeletedAt.Compare(a.DeletedAt) })
+	return entries, nil
+}
+
+// RestoreFromTrash puts the categories and texts of a trash entry back into
+// store and removes the entry. Categories return under their former parent
+// when it still exists, otherwise at the root; a single text returns to its
+// category on the same terms. IDs and category names taken in the meantime
+// are resolved like ImportPack with ConflictRename.
+//
+// When an import fails halfway, the entry is rewritten to hold only what
+// was not restored, so retrying does not duplicate anything.
+func (m *Manager) RestoreFromTrash(store TextStore, id string) (PackReport, error) {
+	report := PackReport{TextIDs: make(map[string]string)}
+	if err := m.checkWritable(); err != nil {
+		return report, err
+	}
+	entry, err := m.readTrashEntry(id)
+	if err != nil {
+		return report, err
+	}
+	pack := &textPack{
+		manifest: PackManifest{Categories: entry.Categories, Texts: entry.Texts},
+		content:  make(map[string]string, len(entry.Texts)),
+	}
+	for _, t := range entry.Texts {
+		if pack.content[t.ID], err = m.readTrashContent(id, t.ID); err != nil {
+			return report, err
+		}
+	}
+	lib, err := store.Library()
+	if err != nil {
+		return report, err
+	}
+	imp := newPackImporter(store, lib, pack, ConflictRename, &report)
+	// References outside the entry keep pointing at library categories that survived
+	for _, c := range entry.Categories {
+		if imp.libCats[c.ParentID] {
+			imp.catIDs[c.ParentID] = c.ParentID
+		}
+	}
+	for _, t := range entry.Texts {
+		if imp.libCats[t.CategoryID] {
+			imp.catIDs[t.CategoryID] = t.CategoryID
+		}
+	}
+	cats, texts, err := restoreTrashItems(imp, entry, pack.content)
+	if err != nil {
+		return report, errors.Join(err, m.keepUnrestored(entry, cats, texts, imp.catIDs))
+	}
+	m.discardTrash(id)
+	return report, nil
+}
+
+// restoreTrashItems imports the categories and then the texts of entry and
+// returns how many of each were restored, also when it fails.
+func restoreTrashItems(imp *packImporter, entry TrashEntry, content map[string]string) (cats, texts int, err error) {
+	for _, c := range entry.Categories {
+		if err := imp.importCategory(c); err != nil {
+			return cats, texts, err
+		}
+		cats++
+	}
+	for _, t := range entry.Texts {
+		if err := imp.importText(t, content[t.ID]); err != nil {
+			return cats, texts, err
+		}
+		texts++
+	}
+	return cats, texts, nil
+}
+
+// keepUnrestored rewrites entry after a failed restore to hold only what is
+// still missing: the categories after the first cats and the texts after the
+// first texts. References to restored categories are pointed at their IDs in
+// the library (catIDs), so a retry puts the rest back into them instead of
+// restoring anything twice.
+func (m *Manager) keepUnrestored(entry TrashEntry, cats, texts int, catIDs map[string]string) error {
+	if cats == 0 && texts == 0 {
+		return nil
+	}
+	for _, t := range entry.Texts[:texts] {
+		_ = os.Remove(m.join(trashContentPath(entry.ID, t.ID)))
+	}
+	entry.Categories = slices.Clone(entry.Categories[cats:])
+	for i := range entry.Categories {
+		if id, ok := catIDs[entry.Categories[i].ParentID]; ok {
+			entry.Categories[i].ParentID = id
+		}
+	}
+	entry.Texts = slices.Clone(entry.Texts[texts:])
+	for i := range entry.Texts {
+		if id, ok := catIDs[entry.Texts[i].CategoryID]; ok {
+			entry.Texts[i].CategoryID = id
+		}
+	}
+	data, err := json.MarshalIndent(&entry, "", "  ")
+	if err == nil {
+		err = m.writeFile(filepath.Join(trashDir, entry.ID, trashEntryFile), data)
+	}
+	if err != nil {
+		return fmt.Errorf("storage: record partial restore of trash entry %q: %w", entry.ID, err)
+	}
+	return nil
+}
+
+// EmptyTrash permanently deletes every trash entry.
+func (m *Manager) EmptyTrash() error {
+	if err := m.checkWritable(); err != nil {
+		return err
+	}
+	if err := os.RemoveAll(m.join(trashDir)); err != nil {
+		return fmt.Errorf("storage: empty trash: %w", err)
+	}
+	return nil
+}
+
+// PurgeTrash permanently deletes trash entries older than retention, plus
+// interrupted deletions, and returns how many entries were removed. A
+// retention of zero or less keeps everything.
+func (m *Manager) PurgeTrash(retention time.Duration) (int, error) {
+	if retention <= 0 {
+		return 0, nil
+	}
+	if err := m.checkWritable(); err != nil {
+		return 0, err
+	}
+	dirs, err := os.ReadDir(m.join(trashDir))
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("storage: read trash: %w", err)
+	}
+	cutoff := time.Now().Add(-retention)
+	purged := 0
+	for _, d := range dirs {
+		if !d.IsDir() {
+			continue
+		}
+		entry, err := m.readTrashEntry(d.Name())
+		switch {
+		case errors.Is(err, ErrTrashEntryNotFound):
+		case err != nil:
+			log.Printf("WARNING: trash entry %q is unreadable, keeping it: %v", d.Name(), err)
+			continue
+		case entry.DeletedAt.After(cutoff):
+			continue
+		}
+		if err := os.RemoveAll(m.join(trashDir, d.Name())); err != nil {
+			return purged, fmt.Errorf("storage: purge trash entry %q: %w", d.Name(), err)
+		}
+		purged++
+	}
+	return purged, nil
+}
+
+// readTrashEntry reads entry.json of a trash entry.
+func (m *Manager) readTrashEntry(id string) (TrashEntry, error) {
+	var entry TrashEntry
+	if !validIDPattern.MatchString(id) {
+		return entry, fmt.Errorf("%w: %s", ErrTrashEntryNotFound, id)
+	}
+	raw, err := os.ReadFile(m.join(trashDir, id, trashEntryFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return entry, fmt.Errorf("%w: %s", ErrTrashEntryNotFound, id)
+	}
+	if err != nil {
+		return entry, fmt.Errorf("storage: read trash entry %q: %w", id, err)
+	}
+	if len(raw) > maxManifestSize {
+		return entry, fmt.Errorf("storage: trash entry %q is too large", id)
+	}
+	if err := json.Unmarshal(raw, &entry); err != nil {
+		return entry, fmt.Errorf("storage: decode trash entry %q: %w", id, err)
+	}
+	for _, t := range entry.Texts {
+		if err := validateTextID(t.ID); err != nil {
+			return entry, fmt.Errorf("storage: trash entry %q: %w", id, err)
+		}
+	}
+	entry.ID = id
+	return entry, nil
+}
+
+// readTrashContent reads the content of text textID from trash entry id.
+func (m *Manager) readTrashContent(id, textID string) (string, error) {
+	f, err := os.Open(m.join(trashContentPath(id, textID)))
+	if err != nil {
+		return "", fmt.Errorf("storage: read trashed text %q: %w", textID, err)
+	}
+	defer f.Close()
+	data, err := io.ReadAll(io.LimitReader(f, maxContentLength+1))
+	if err != nil {
+		return "", fmt.Errorf("storage: read trashed text %q: %w", textID, err)
+	}
+	if len(data) > maxContentLength {
+		return "", fmt.Errorf("storage: trashed text %q: %w", textID, ErrTextContentTooLarge)
+	}
+	return string(data), nil
+}
+
+// trashContentPath returns the relative path of a trashed text's content.
+func trashContentPath(entryID, textID string) string {
+	return filepath.Join(trashDir, entryID, trashContentDir, textID+".txt")
+}
@@ -0,0 +1,261 @@
+// Copyright 2025 Asher Buk
+// SPDX-License-Identifier: Apache-2.0
+// https://github.com/AshBuk/FingerGo
+
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"slices"
+	"testing"
+	"time"
+
+	domain "github.com/AshBuk/FingerGo/internal/domain"
+)
+
+// Trash fixtures: the tree a > a1 > a2 plus b, with one text in a, a2 and b.
+var (
+	trashTreeCategories = []domain.Category{
+		{ID: "a", Name: "A"}, {ID: "a1", Name: "A1", ParentID: "a"},
+		{ID: "a2", Name: "A2", ParentID: "a1"}, {ID: "b", Name: "B"},
+	}
+	trashTreeTexts = []domain.Text{
+		{ID: "ta", Title: "TA", Content: "alpha", CategoryID: "a"},
+		{ID: "ta2", Title: "TA2", Content: "deep", CategoryID: "a2"},
+		{ID: "tb", Title: "TB", Content: "beta", CategoryID: "b"},
+	}
+)
+
+// libraryIDs returns the category and text IDs of the library, sorted.
+func libraryIDs(t *testing.T, s TextStore) (categories, texts []string) {
+	t.Helper()
+	lib, err := s.Library()
+	if err != nil {
+		t.Fatalf("Library() error: %v", err)
+	}
+	for _, c := range lib.Categories {
+		categories = append(categories, c.ID)
+	}
+	for _, text := range lib.Texts {
+		texts = append(texts, text.ID)
+	}
+	slices.Sort(categories)
+	slices.Sort(texts)
+	return categories, texts
+}
+
+func TestStores_DeleteCategorySubtree(t *testing.T) {
+	for name, open := range backends(t) {
+		t.Run(string(name), func(t *testing.T) {
+			s := open(t)
+			fillLibrary(t, s.texts, trashTreeCategories, trashTreeTexts)
+
+			if err := s.texts.DeleteCategory("a"); err != nil {
+				t.Fatalf("DeleteCategory() error: %v", err)
+			}
+			cats, texts := libraryIDs(t, s.texts)
+			if want := []string{"b", "welcome"}; !slices.Equal(cats, want) {
+				t.Errorf("categories after delete = %v, want %v", cats, want)
+			}
+			if want := []string{"quick-sort", "tb"}; !slices.Equal(texts, want) {
+				t.Errorf("texts after delete = %v, want %v", texts, want)
+			}
+			if _, err := s.texts.Text("ta2"); !errors.Is(err, ErrTextNotFound) {
+				t.Errorf("Text(ta2): expected ErrTextNotFound, got %v", err)
+			}
+
+			entries, err := s.mgr.ListTrash()
+			if err != nil {
+				t.Fatalf("ListTrash() error: %v", err)
+			}
+			if len(entries) != 1 {
+				t.Fatalf("ListTrash() = %d entries, want 1", len(entries))
+			}
+			entry := entries[0]
+			if entry.Name != "A" || len(entry.Categories) != 3 || len(entry.Texts) != 2 {
+				t.Errorf("entry = %+v, want category A with 3 categories and 2 texts", entry)
+			}
+			if entry.Categories[0].ID != "a" {
+				t.Errorf("first trashed category = %s, want the deleted root a", entry.Categories[0].ID)
+			}
+
+			report, err := s.mgr.RestoreFromTrash(s.texts, entry.ID)
+			if err != nil {
+				t.Fatalf("RestoreFromTrash() error: %v", err)
+			}
+			if report.Categories != 3 || report.Imported != 2 {
+				t.Errorf("report = %+v, want 3 categories and 2 texts", report)
+			}
+			if got := childIDs(t, s.texts, "a1"); !slices.Equal(got, []string{"a2"}) {
+				t.Errorf("children of a1 after restore = %v, want [a2]", got)
+			}
+			if text, err := s.texts.Text("ta2"); err != nil || text.Content != "deep" || text.CategoryID != "a2" {
+				t.Errorf("restored Text(ta2) = %+v, %v", text, err)
+			}
+			if entries, _ := s.mgr.ListTrash(); len(entries) != 0 {
+				t.Errorf("trash after restore = %+v, want empty", entries)
+			}
+		})
+	}
+}
+
+func TestStores_RestoreTextFromTrash(t *testing.T) {
+	for name, open := range backends(t) {
+		t.Run(string(name), func(t *testing.T) {
+			s := open(t)
+			fillLibrary(t, s.texts, trashTreeCategories, trashTreeTexts)
+			if err := s.texts.DeleteText("tb"); err != nil {
+				t.Fatalf("DeleteText() error: %v", err)
+			}
+			// The ID is reused before the restore
+			if err := s.texts.SaveText(&domain.Text{ID: "tb", Title: "New", Content: "new"}); err != nil {
+				t.Fatalf("SaveText() error: %v", err)
+			}
+			entries, _ := s.mgr.ListTrash()
+			if len(entries) != 1 || entries[0].Name != "TB" {
+				t.Fatalf("ListTrash() = %+v, want the text TB", entries)
+			}
+			report, err := s.mgr.RestoreFromTrash(s.texts, entries[0].ID)
+			if err != nil {
+				t.Fatalf("RestoreFromTrash() error: %v", err)
+			}
+			restoredID := report.TextIDs["tb"]
+			if report.Renamed != 1 || restoredID == "tb" {
+				t.Fatalf("report = %+v, want tb renamed", report)
+			}
+			text, err := s.texts.Text(restoredID)
+			if err != nil || text.Content != "beta" || text.CategoryID != "b" {
+				t.Errorf("restored Text(%s) = %+v, %v; want content beta in b", restoredID, text, err)
+			}
+			if _, err := s.mgr.RestoreFromTrash(s.texts, entries[0].ID); !errors.Is(err, ErrTrashEntryNotFound) {
+				t.Errorf("second restore: expected ErrTrashEntryNotFound, got %v", err)
+			}
+		})
+	}
+}
+
+func TestStores_RestoreIntoDeletedParent(t *testing.T) {
+	for name, open := range backends(t) {
+		t.Run(string(name), func(t *testing.T) {
+			s := open(t)
+			fillLibrary(t, s.texts, trashTreeCategories, trashTreeTexts)
+			if err := s.texts.DeleteCategory("a1"); err != nil {
+				t.Fatalf("DeleteCategory(a1) error: %v", err)
+			}
+			if err := s.texts.DeleteCategory("a"); err != nil {
+				t.Fatalf("DeleteCategory(a) error: %v", err)
+			}
+			entries, _ := s.mgr.ListTrash()
+			idx := slices.IndexFunc(entries, func(e TrashEntry) bool { return e.Name == "A1" })
+			if idx < 0 {
+				t.Fatalf("ListTrash() = %+v, want an entry for A1", entries)
+			}
+			if _, err := s.mgr.RestoreFromTrash(s.texts, entries[idx].ID); err != nil {
+				t.Fatalf("RestoreFromTrash() error: %v", err)
+			}
+			// a is still in the trash, so a1 comes back at the root
+			if got := childIDs(t, s.texts, ""); !slices.Contains(got, "a1") {
+				t.Errorf("roots = %v, want a1 among them", got)
+			}
+		})
+	}
+}
+
+// failingTextStore fails SaveText for one text ID.
+type failingTextStore struct {
+	TextStore
+	failID string
+}
+
+func (s failingTextStore) SaveText(text *domain.Text) error {
+	if text.ID == s.failID {
+		return errors.New("disk full")
+	}
+	return s.TextStore.SaveText(text)
+}
+
+func TestStores_RestoreFromTrashResumes(t *testing.T) {
+	for name, open := range backends(t) {
+		t.Run(string(name), func(t *testing.T) {
+			s := open(t)
+			fillLibrary(t, s.texts, trashTreeCategories, trashTreeTexts)
+			if err := s.texts.DeleteCategory("a"); err != nil {
+				t.Fatalf("DeleteCategory() error: %v", err)
+			}
+			entries, _ := s.mgr.ListTrash()
+			if len(entries) != 1 {
+				t.Fatalf("ListTrash() = %+v, want 1 entry", entries)
+			}
+			id := entries[0].ID
+			if _, err := s.mgr.RestoreFromTrash(failingTextStore{s.texts, "ta2"}, id); err == nil {
+				t.Fatal("RestoreFromTrash() with a failing store: expected error")
+			}
+			entry, err := s.mgr.readTrashEntry(id)
+			if err != nil {
+				t.Fatalf("readTrashEntry() error: %v", err)
+			}
+			if len(entry.Categories) != 0 || len(entry.Texts) != 1 || entry.Texts[0].ID != "ta2" {
+				t.Errorf("entry after partial restore = %+v, want only ta2 left", entry)
+			}
+
+			if _, err := s.mgr.RestoreFromTrash(s.texts, id); err != nil {
+				t.Fatalf("retried RestoreFromTrash() error: %v", err)
+			}
+			cats, texts := libraryIDs(t, s.texts)
+			if want := []string{"a", "a1", "a2", "b", "welcome"}; !slices.Equal(cats, want) {
+				t.Errorf("categories after retry = %v, want %v", cats, want)
+			}
+			if want := []string{"quick-sort", "ta", "ta2", "tb"}; !slices.Equal(texts, want) {
+				t.Errorf("texts after retry = %v, want %v", texts, want)
+			}
+			if text, err := s.texts.Text("ta2"); err != nil || text.Content != "deep" || text.CategoryID != "a2" {
+				t.Errorf("restored Text(ta2) = %+v, %v", text, err)
+			}
+		})
+	}
+}
+
+func TestManager_EmptyAndPurgeTrash(t *testing.T) {
+	s := backends(t)[BackendJSON](t)
+	fillLibrary(t, s.texts, trashTreeCategories, trashTreeTexts)
+	for _, id := range []string{"ta", "tb"} {
+		if err := s.texts.DeleteText(id); err != nil {
+			t.Fatalf("DeleteText(%s) error: %v", id, err)
+		}
+	}
+	entries, _ := s.mgr.ListTrash()
+	if len(entries) != 2 {
+		t.Fatalf("ListTrash() = %d entries, want 2", len(entries))
+	}
+	// Age one entry past the retention period
+	old := entries[1]
+	old.DeletedAt = time.Now().Add(-48 * time.Hour)
+	data, _ := json.Marshal(old)
+	if err := os.WriteFile(s.mgr.join(trashDir, old.ID, trashEntryFile), data, 0o600); err != nil {
+		t.Fatalf("WriteFile() error: %v", err)
+	}
+	// An interrupted deletion has no entry.json
+	if err := os.MkdirAll(s.mgr.join(trashDir, "partial"), 0o755); err != nil {
+		t.Fatalf("MkdirAll() error: %v", err)
+	}
+
+	if n, err := s.mgr.PurgeTrash(0); err != nil || n != 0 {
+		t.Errorf("PurgeTrash(0) = %d, %v; want nothing purged", n, err)
+	}
+	if n, err := s.mgr.PurgeTrash(24 * time.Hour); err != nil || n != 2 {
+		t.Errorf("PurgeTrash(24h) = %d, %v; want the old and the partial entry", n, err)
+	}
+	entries, _ = s.mgr.ListTrash()
+	if len(entries) != 1 || entries[0].ID == old.ID {
+		t.Errorf("ListTrash() after purge = %+v, want only the recent entry", entries)
+	}
+
+	if err := s.mgr.EmptyTrash(); err != nil {
+		t.Fatalf("EmptyTrash() error: %v", err)
+	}
+	if entries, err := s.mgr.ListTrash(); err != nil || len(entries) != 0 {
+		t.Errorf("ListTrash() after EmptyTrash = %+v, %v", entries, err)
+	}
+}
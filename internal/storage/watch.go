@@ -0,0 +1,256 @@
+// Copyright 2025 Asher Buk
+// SPDX-License-Identifier: Apache-2.0
+// https://github.com/AshBuk/FingerGo
+
+package storage
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	domain "github.com/AshBuk/FingerGo/internal/domain"
+)
+
+// DefaultWatchInterval is how often Watch polls the library files.
+// Polling (not inotify & co.) keeps working on network mounts and with sync
+// tools that replace files via rename.
+const DefaultWatchInterval = 2 * time.Second
+
+// LibraryChange describes library files modified outside the app.
+type LibraryChange struct {
+	TextIDs      []string `json:"textIds"`      // texts whose content file changed or disappeared
+	IndexChanged bool     `json:"indexChanged"` // index.json was edited and reloaded
+}
+
+// fileStamp is the on-disk state of a file as seen by a stat call.
+type fileStamp struct {
+	modTime int64 // UnixNano
+	size    int64
+	exists  bool
+}
+
+// statStamp returns the current stamp of an absolute path.
+func statStamp(path string) fileStamp {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileStamp{}
+	}
+	return fileStamp{modTime: info.ModTime().UnixNano(), size: info.Size(), exists: true}
+}
+
+// recordStamp remembers the state of relPath after a read or write by the
+// repository, so Watch does not mistake our own writes for external edits.
+// Caller must hold r.mu.
+func (r *TextRepository) recordStamp(relPath string) {
+	if r.stamps == nil {
+		r.stamps = make(map[string]fileStamp)
+	}
+	r.stamps[relPath] = statStamp(r.storage.join(relPath))
+}
+
+// Watch records the current state of index.json and the content files, then
+// polls them every interval in a background goroutine until ctx is done.
+// External modifications are applied to the repository (see reloadExternal)
+// and reported through onChange; writes made by this repository are ignored.
+// An index.json that does not parse (e.g. halfway through a sync) keeps the
+// in-memory library and is retried on the next poll.
+func (r *TextRepository) Watch(ctx context.Context, interval time.Duration, onChange func(LibraryChange)) {
+	if interval <= 0 {
+		interval = DefaultWatchInterval
+	}
+	prev := r.scanFiles()
+	go r.poll(ctx, interval, prev, onChange)
+}
+
+// poll is the loop behind Watch.
+func (r *TextRepository) poll(ctx context.Context, interval time.Duration, prev map[string]fileStamp, onChange func(LibraryChange)) {
+	var failed fileStamp // index.json state that last failed to reload, logged once
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		cur := r.scanFiles()
+		change, err := r.reloadExternal(prev, cur)
+		if err != nil {
+			if failed != cur[textsIndexFile] {
+				log.Printf("WARNING: %v", err)
+				failed = cur[textsIndexFile]
+			}
+			cur[textsIndexFile] = prev[textsIndexFile] // retry on the next poll
+		}
+		prev = cur
+		if change.IndexChanged || len(change.TextIDs) > 0 {
+			onChange(change)
+		}
+	}
+}
+
+// reloadExternal reconciles the repository with files that changed between
+// two scans:
+//   - index.json changed: the disk version wins and replaces the in-memory
+//     library (entries with unsafe IDs are dropped)
+//   - a content file changed or was removed: its cached content and the
+//     search index are dropped, the next Text or Search call reads the files
+//     again, and the text's metrics are recomputed (see reanalyze)
+//
+// Files whose current state matches our own last write are skipped, and a
+// removed index.json is ignored: the in-memory library is written back on the
+// next save.
+func (r *TextRepository) reloadExternal(prev, cur map[string]fileStamp) (LibraryChange, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var change LibraryChange
+	for _, relPath := range changedPaths(prev, cur) {
+		if own, ok := r.stamps[relPath]; ok && own == cur[relPath] {
+			continue
+		}
+		if relPath == textsIndexFile {
+			change.IndexChanged = cur[relPath].exists
+			continue
+		}
+		id := strings.TrimSuffix(filepath.Base(relPath), ".txt")
+		change.TextIDs = append(change.TextIDs, id)
+		r.cacheMu.Lock()
+		delete(r.contentCache, id)
+		r.cacheMu.Unlock()
+		r.stamps[relPath] = cur[relPath]
+	}
+	slices.Sort(change.TextIDs)
+	if len(change.TextIDs) > 0 {
+		r.search.reset()
+	}
+	if change.IndexChanged && r.loaded {
+		if err := r.reloadIndex(); err != nil {
+			change.IndexChanged = false
+			return change, err
+		}
+	}
+	r.reanalyze(change.TextIDs)
+	return change, nil
+}
+
+// reanalyze recomputes the metrics of the texts in ids (sorted) from their
+// content on disk and saves them to index.json. Failures are logged as
+// warnings: the edit itself has been picked up. Caller must hold r.mu.
+func (r *TextRepository) reanalyze(ids []string) {
+	if len(ids) == 0 || !r.loaded {
+		return
+	}
+	n := r.Normalizer()
+	updated := false
+	for i := range r.library.Texts {
+		text := r.library.Texts[i]
+		if _, found := slices.BinarySearch(ids, text.ID); !found {
+			continue
+		}
+		content, err := r.cachedContent(text.ID)
+		if err != nil {
+			r.storage.warnf("externally edited text %q: metrics not updated: %v", text.ID, err)
+			continue
+		}
+		text.Content = content
+		analyzeText(n, &text)
+		r.setMetrics(i, text.Metrics)
+		updated = true
+	}
+	if !updated {
+		return
+	}
+	if err := r.persistIndex(); err != nil {
+		r.storage.warnf("externally edited texts: save metrics: %v", err)
+	}
+}
+
+// refreshIndex reloads index.json if it changed on disk since our last
+// read or write. Caller must hold r.mu.
+func (r *TextRepository) refreshIndex() error {
+	stamp := statStamp(r.storage.join(textsIndexFile))
+	if !stamp.exists || stamp == r.stamps[textsIndexFile] {
+		return nil
+	}
+	return r.reloadIndex()
+}
+
+// reloadIndex replaces the in-memory library with index.json as found on disk.
+// Unlike the first load, a file that does not parse is left in place: it is
+// most likely being written by another program. Caller must hold r.mu.
+func (r *TextRepository) reloadIndex() error {
+	raw, err := os.ReadFile(r.storage.join(textsIndexFile))
+	if err != nil {
+		return fmt.Errorf("storage: reload index: %w", err)
+	}
+	var library domain.TextLibrary
+	if err := decodeDocument(textsIndexFile, raw, &library); err != nil {
+		return fmt.Errorf("storage: reload index: %s was changed outside the app and cannot be read: %w", textsIndexFile, err)
+	}
+	library.Texts = slices.DeleteFunc(library.Texts, func(t domain.Text) bool {
+		if err := validateTextID(t.ID); err != nil {
+			r.storage.warnf("externally edited %s: skipped text %q: %v", textsIndexFile, t.ID, err)
+			return true
+		}
+		return false
+	})
+	library.Categories = slices.DeleteFunc(library.Categories, func(c domain.Category) bool {
+		if err := validateCategoryID(c.ID); err != nil {
+			r.storage.warnf("externally edited %s: skipped category %q: %v", textsIndexFile, c.ID, err)
+			return true
+		}
+		return false
+	})
+	r.setLibrary(library)
+	r.cacheMu.Lock()
+	for id := range r.contentCache {
+		if _, ok := r.textIndex[id]; !ok {
+			delete(r.contentCache, id)
+		}
+	}
+	r.cacheMu.Unlock()
+	r.recordStamp(textsIndexFile)
+	return nil
+}
+
+// scanFiles stats index.json and every content file.
+func (r *TextRepository) scanFiles() map[string]fileStamp {
+	stamps := map[string]fileStamp{
+		textsIndexFile: statStamp(r.storage.join(textsIndexFile)),
+	}
+	entries, err := os.ReadDir(r.storage.join(textsContentDir))
+	if err != nil {
+		return stamps
+	}
+	for _, entry := range entries {
+		id, ok := strings.CutSuffix(entry.Name(), ".txt")
+		if !ok || entry.IsDir() || validateTextID(id) != nil {
+			continue
+		}
+		stamps[contentPath(id)] = statStamp(r.storage.join(contentPath(id)))
+	}
+	return stamps
+}
+
+// changedPaths returns the sorted paths whose stamps differ between two scans.
+func changedPaths(prev, cur map[string]fileStamp) []string {
+	var paths []string
+	for relPath, stamp := range cur {
+		if prev[relPath] != stamp {
+			paths = append(paths, relPath)
+		}
+	}
+	for relPath := range prev {
+		if _, ok := cur[relPath]; !ok {
+			paths = append(paths, relPath)
+		}
+	}
+	slices.Sort(paths)
+	return paths
+}
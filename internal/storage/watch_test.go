@@ -0,0 +1,162 @@
+// Copyright 2025 Asher Buk
+// SPDX-License-Identifier: Apache-2.0
+// https://github.com/AshBuk/FingerGo
+
+package storage
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	domain "github.com/AshBuk/FingerGo/internal/domain"
+)
+
+// writeIndex replaces index.json the way an external editor would.
+func writeIndex(t *testing.T, repo *TextRepository, lib domain.TextLibrary) {
+	t.Helper()
+	data, err := encodeDocument(textsIndexFile, lib)
+	if err != nil {
+		t.Fatalf("encodeDocument: %v", err)
+	}
+	if err := os.WriteFile(repo.storage.join(textsIndexFile), data, 0o600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+}
+
+func TestTextRepository_ReloadExternal(t *testing.T) {
+	t.Run("ignores own writes", func(t *testing.T) {
+		repo := setupTextRepository(t)
+		prev := repo.scanFiles()
+		if err := repo.SaveText(&domain.Text{ID: "own", Title: "Own", Content: "mine"}); err != nil {
+			t.Fatalf("SaveText: %v", err)
+		}
+		change, err := repo.reloadExternal(prev, repo.scanFiles())
+		if err != nil {
+			t.Fatalf("reloadExternal: %v", err)
+		}
+		if change.IndexChanged || len(change.TextIDs) > 0 {
+			t.Errorf("own write reported as external change: %+v", change)
+		}
+	})
+
+	t.Run("drops cached content edited on disk", func(t *testing.T) {
+		repo := setupTextRepository(t)
+		if err := repo.SaveText(&domain.Text{ID: "t1", Title: "T1", Content: "before"}); err != nil {
+			t.Fatalf("SaveText: %v", err)
+		}
+		prev := repo.scanFiles()
+		if err := os.WriteFile(repo.storage.join(contentPath("t1")), []byte("after edit"), 0o600); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+		change, err := repo.reloadExternal(prev, repo.scanFiles())
+		if err != nil {
+			t.Fatalf("reloadExternal: %v", err)
+		}
+		if len(change.TextIDs) != 1 || change.TextIDs[0] != "t1" {
+			t.Errorf("TextIDs = %v, want [t1]", change.TextIDs)
+		}
+		text, _ := repo.Text("t1")
+		if text.Content != "after edit" {
+			t.Errorf("content = %q, want %q", text.Content, "after edit")
+		}
+		if text.Metrics == nil || text.Metrics.Characters != len("after edit") {
+			t.Errorf("metrics = %+v, want them recomputed for the edited content", text.Metrics)
+		}
+	})
+
+	t.Run("reloads edited index and skips unsafe ids", func(t *testing.T) {
+		repo := setupTextRepository(t)
+		lib, _ := repo.Library()
+		prev := repo.scanFiles()
+		lib.Categories = append(lib.Categories,
+			domain.Category{ID: "synced", Name: "Synced"},
+			domain.Category{ID: "../evil", Name: "Evil"})
+		writeIndex(t, repo, lib)
+		change, err := repo.reloadExternal(prev, repo.scanFiles())
+		if err != nil {
+			t.Fatalf("reloadExternal: %v", err)
+		}
+		if !change.IndexChanged {
+			t.Error("IndexChanged = false")
+		}
+		got, _ := repo.Library()
+		if n := len(got.Categories); n != len(lib.Categories)-1 {
+			t.Errorf("categories = %d, want %d", n, len(lib.Categories)-1)
+		}
+		if len(repo.storage.Warnings()) == 0 {
+			t.Error("expected a warning for the unsafe category id")
+		}
+	})
+
+	t.Run("keeps library while index is unreadable", func(t *testing.T) {
+		repo := setupTextRepository(t)
+		before, _ := repo.Library()
+		prev := repo.scanFiles()
+		if err := os.WriteFile(repo.storage.join(textsIndexFile), []byte(`{"schemaVersion":`), 0o600); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+		if _, err := repo.reloadExternal(prev, repo.scanFiles()); err == nil {
+			t.Error("expected error for a half-written index")
+		}
+		after, _ := repo.Library()
+		if len(after.Texts) != len(before.Texts) {
+			t.Errorf("texts = %d, want %d", len(after.Texts), len(before.Texts))
+		}
+	})
+}
+
+func TestTextRepository_SaveDoesNotClobberExternalEdit(t *testing.T) {
+	repo := setupTextRepository(t)
+	lib, _ := repo.Library()
+	lib.Categories = append(lib.Categories, domain.Category{ID: "by-hand", Name: "By Hand"})
+	writeIndex(t, repo, lib)
+
+	if err := repo.SaveText(&domain.Text{ID: "in-app", Title: "In App", Content: "x"}); err != nil {
+		t.Fatalf("SaveText: %v", err)
+	}
+
+	reopened, _ := NewTextRepository(repo.storage)
+	got, err := reopened.Library()
+	if err != nil {
+		t.Fatalf("Library: %v", err)
+	}
+	foundCat, foundText := false, false
+	for _, c := range got.Categories {
+		foundCat = foundCat || c.ID == "by-hand"
+	}
+	for _, tx := range got.Texts {
+		foundText = foundText || tx.ID == "in-app"
+	}
+	if !foundCat || !foundText {
+		t.Errorf("external category kept = %v, in-app text saved = %v", foundCat, foundText)
+	}
+}
+
+func TestTextRepository_Watch(t *testing.T) {
+	repo := setupTextRepository(t)
+	if _, err := repo.Library(); err != nil {
+		t.Fatalf("Library: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changes := make(chan LibraryChange, 1)
+	repo.Watch(ctx, 10*time.Millisecond, func(c LibraryChange) { changes <- c })
+
+	lib, _ := repo.Library()
+	lib.DefaultTextID = ""
+	writeIndex(t, repo, lib)
+	select {
+	case c := <-changes:
+		if !c.IndexChanged {
+			t.Errorf("change = %+v, want IndexChanged", c)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Watch did not report the edit")
+	}
+	got, _ := repo.Library()
+	if got.DefaultTextID != "" {
+		t.Errorf("DefaultTextID = %q, want reloaded empty value", got.DefaultTextID)
+	}
+}
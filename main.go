@@ -7,7 +7,10 @@ package main
 import (
 	"context"
 	"embed"
+	"flag"
+	"io"
 	"log"
+	"os"
 
 	"github.com/wailsapp/wails/v2"
 	"github.com/wailsapp/wails/v2/pkg/options"
@@ -16,13 +19,18 @@ import (
 	"github.com/wailsapp/wails/v2/pkg/options/windows"
 
 	"github.com/AshBuk/FingerGo/app"
+	"github.com/AshBuk/FingerGo/internal/storage"
 )
 
 //go:embed gui/src
 var assets embed.FS
 
 func main() {
-	appInstance := app.New()
+	root, err := storage.ResolveRoot(parseDataDir(os.Args[1:]))
+	if err != nil {
+		log.Fatalf("failed to resolve data directory: %v", err)
+	}
+	appInstance := app.NewWithRoot(root)
 
 	if err := wails.Run(&options.App{
 		Title:                    "FingerGo",
@@ -36,6 +44,10 @@ func main() {
 		Bind:                     []interface{}{appInstance},
 		Frameless:                false,
 		EnableDefaultContextMenu: true,
+		SingleInstanceLock: &options.SingleInstanceLock{
+			UniqueId:               root.InstanceID(),
+			OnSecondInstanceLaunch: appInstance.OnSecondInstanceLaunch,
+		},
 		Windows: &windows.Options{
 			Theme: windows.Dark,
 		},
@@ -56,3 +68,16 @@ func wrapStartup(appInstance *app.App) func(ctx context.Context) {
 		}
 	}
 }
+
+// parseDataDir returns the --data-dir flag value. Unknown arguments are
+// ignored, since platforms may pass their own (e.g. -psn_* on macOS).
+func parseDataDir(args []string) string {
+	fs := flag.NewFlagSet("fingergo", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	dataDir := fs.String("data-dir", "", "data directory (overrides FINGERGO_DATA_DIR and portable mode)")
+	// A failed Parse has already consumed the offending argument; resume after it
+	for fs.Parse(args) != nil {
+		args = fs.Args()
+	}
+	return *dataDir
+}